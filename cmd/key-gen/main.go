@@ -6,6 +6,8 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log"
+
+	"github.com/rrb115/vdcs/internal/crypto"
 )
 
 func main() {
@@ -16,6 +18,8 @@ func main() {
 
 	fmt.Printf("Private Key (Hex): %s\n", hex.EncodeToString(priv))
 	fmt.Printf("Public Key (Hex):  %s\n", hex.EncodeToString(pub))
+	fmt.Printf("Author ID:         %s\n", crypto.Fingerprint(pub))
 	fmt.Println("\nUse the Public Key to start the node (--trusted-keys).")
 	fmt.Println("Use the Private Key to sign entries with the CLI (--priv-key).")
+	fmt.Println("The CLI signs as the Author ID unless given another one (--author).")
 }
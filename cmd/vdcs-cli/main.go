@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"flag"
@@ -11,20 +12,26 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/rrb115/vdcs/internal/audit"
+	"github.com/rrb115/vdcs/internal/authors"
+	"github.com/rrb115/vdcs/internal/checkpoint"
 	"github.com/rrb115/vdcs/internal/crypto"
 	verlog "github.com/rrb115/vdcs/internal/log"
 	"github.com/rrb115/vdcs/internal/merkle"
+	"github.com/rrb115/vdcs/internal/policy"
 	vdcspb "github.com/rrb115/vdcs/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 func main() {
 	if len(os.Args) < 2 {
 		fmt.Println("Usage: vdcs-cli <command> [args]")
-		fmt.Println("Commands: set, get, audit")
+		fmt.Println("Commands: set, txn, govern, proposal, get, watch, verify-receipts, audit, monitor")
 		os.Exit(1)
 	}
 
@@ -34,8 +41,18 @@ func main() {
 	switch cmd {
 	case "set":
 		runSet(args)
+	case "txn":
+		runTxn(args)
+	case "govern":
+		runGovern(args)
+	case "proposal":
+		runProposal(args)
 	case "get":
 		runGet(args)
+	case "watch":
+		runWatch(args)
+	case "verify-receipts":
+		runVerifyReceipts(args)
 	case "audit":
 		runAudit(args)
 	case "monitor":
@@ -49,6 +66,7 @@ func runMonitor(args []string) {
 	monCmd := flag.NewFlagSet("monitor", flag.ExitOnError)
 	target := monCmd.String("target", "", "Target URL to report state")
 	interval := monCmd.Duration("interval", 0, "Polling interval (0 for one-shot)")
+	nodeKeyHex := monCmd.String("node-key", "", "Node public key (hex) used to verify signed checkpoints")
 
 	if err := monCmd.Parse(args); err != nil {
 		log.Fatal(err)
@@ -60,24 +78,47 @@ func runMonitor(args []string) {
 
 	client := connect()
 
+	// Last log tree head we verified. Every new head must be an append-only
+	// extension of it.
+	var lastSize uint64
+	var lastRoot []byte
+
 	for {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		state, err := client.GetLatestRoot(ctx, &vdcspb.Empty{})
+		if err != nil {
+			err = fmt.Errorf("ERROR: Failed to fetch state: %w", err)
+		} else if *nodeKeyHex != "" {
+			if _, cpErr := verifyCheckpoint(state, *nodeKeyHex); cpErr != nil {
+				err = fmt.Errorf("ALERT: invalid checkpoint: %w", cpErr)
+			}
+		}
+		if err == nil && lastSize > 0 {
+			err = verifyConsistency(ctx, client, lastSize, lastRoot, state)
+		}
 		cancel()
 		if err != nil {
-			log.Printf("ERROR: Failed to fetch state: %v", err)
+			log.Print(err)
 			if *interval == 0 {
 				os.Exit(1)
 			}
 			time.Sleep(*interval)
 			continue
 		}
+		lastSize, lastRoot = state.LogSize, state.LogRoot
 
 		payload := map[string]interface{}{
 			"index":     state.Version,
 			"root_hash": hex.EncodeToString(state.StateRoot),
+			"log_size":  state.LogSize,
+			"log_root":  hex.EncodeToString(state.LogRoot),
 			"timestamp": time.Now().Unix(),
 		}
+		if cp := state.Checkpoint; cp != nil {
+			// Stored form of the signed checkpoint, see internal/checkpoint.
+			signed := &checkpoint.Signed{Body: cp.Body, Signature: cp.Signature}
+			payload["checkpoint"] = string(signed.Marshal())
+		}
 
 		jsonBody, _ := json.Marshal(payload)
 		resp, err := http.Post(*target, "application/json", bytes.NewBuffer(jsonBody))
@@ -96,6 +137,199 @@ func runMonitor(args []string) {
 	}
 }
 
+func runWatch(args []string) {
+	watchCmd := flag.NewFlagSet("watch", flag.ExitOnError)
+	prefix := watchCmd.String("prefix", "", "Only show entries changing keys with this prefix")
+	from := watchCmd.Uint64("from", 0, "First index to show")
+	nodeKeyHex := watchCmd.String("node-key", "", "Node public key (hex) used to verify signed checkpoints and sequenced entries")
+	trustedKeys := watchCmd.String("trusted-keys", "", "Comma-separated list of trusted public keys (hex), as given to vdcs-node")
+	authorsPath := watchCmd.String("authors", "", "Path to the node's JSON authors file, if it has one")
+
+	if err := watchCmd.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	keys := loadAuthors(*authorsPath, *trustedKeys)
+	if len(keys) == 0 {
+		log.Fatal("missing required flag: -trusted-keys or -authors")
+	}
+
+	client := connect()
+
+	// Position and last verified log head. Reconnects resume from here.
+	next := *from
+	var lastSize uint64
+	var lastRoot []byte
+
+	for {
+		stream, err := client.Watch(context.Background(), &vdcspb.WatchRequest{
+			KeyPrefix:    *prefix,
+			StartIndex:   next,
+			KnownLogSize: lastSize,
+		})
+		for err == nil {
+			var event *vdcspb.WatchEvent
+			if event, err = stream.Recv(); err != nil {
+				break
+			}
+			if err := verifyWatchEvent(event, *nodeKeyHex, keys, lastSize, lastRoot); err != nil {
+				log.Fatalf("WATCH VERIFICATION FAILED at index %d! %v", event.GetEntry().GetIndex(), err)
+			}
+			next = event.Entry.Index + 1
+			lastSize, lastRoot = event.State.LogSize, event.State.LogRoot
+			trackAuthors(keys, event.Entry)
+			printEntry(event.Entry)
+		}
+		log.Printf("watch interrupted (%v), resuming from index %d", err, next)
+		time.Sleep(time.Second)
+	}
+}
+
+// verifyWatchEvent checks that the event's entry is authentic and included
+// in the event's head, and that the head extends the last verified one.
+func verifyWatchEvent(event *vdcspb.WatchEvent, nodeKeyHex string, keys authors.Set, lastSize uint64, lastRoot []byte) error {
+	entry, state := event.Entry, event.State
+	if entry == nil || state == nil {
+		return fmt.Errorf("incomplete event")
+	}
+	hash, err := verlog.ComputeEntryHash(entry)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hash, entry.EntryHash) {
+		return fmt.Errorf("entry hash does not match entry")
+	}
+	var cp *checkpoint.Checkpoint
+	if nodeKeyHex != "" {
+		if cp, err = verifyCheckpoint(state, nodeKeyHex); err != nil {
+			return err
+		}
+	}
+	if err := verifyEntrySignature(entry, keys, cp, nodeKeyHex); err != nil {
+		return err
+	}
+	if !merkle.VerifyLogInclusion(entry.EntryHash, entry.Index, state.LogSize, event.InclusionProof, state.LogRoot) {
+		return fmt.Errorf("entry is not included in the log of size %d", state.LogSize)
+	}
+	if event.PreviousLogSize != lastSize {
+		return fmt.Errorf("consistency proof is from size %d, last verified size is %d", event.PreviousLogSize, lastSize)
+	}
+	if lastSize > 0 && !merkle.VerifyLogConsistency(lastSize, state.LogSize, lastRoot, state.LogRoot, event.ConsistencyProof) {
+		return fmt.Errorf("log of size %d is not consistent with size %d", state.LogSize, lastSize)
+	}
+	return nil
+}
+
+// verifyEntrySignature checks the signature over the entry's hash: by a
+// trusted author, or by the node that signed cp for a sequenced entry, whose
+// intent must then be signed by a trusted author.
+func verifyEntrySignature(entry *vdcspb.ConfigEntry, keys authors.Set, cp *checkpoint.Checkpoint, nodeKeyHex string) error {
+	if entry.Intent != nil {
+		if cp == nil {
+			return fmt.Errorf("entry sequenced by %s, give -node-key to check it", entry.AuthorId)
+		}
+		if entry.AuthorId != cp.Origin {
+			return fmt.Errorf("entry sequenced by %s, not by the node of log %s", entry.AuthorId, cp.Origin)
+		}
+		pub, _ := parseNodeKey(nodeKeyHex)
+		if !crypto.Verify(pub, entry.EntryHash, entry.Signature) {
+			return fmt.Errorf("invalid signature by sequencer %s", entry.AuthorId)
+		}
+		pub, ok := keys[entry.Intent.AuthorId]
+		if !ok {
+			return fmt.Errorf("intent by unknown author %s", entry.Intent.AuthorId)
+		}
+		return verlog.VerifyIntent(entry.Intent, pub)
+	}
+	pub, ok := keys[entry.AuthorId]
+	if !ok {
+		return fmt.Errorf("entry by unknown author %s", entry.AuthorId)
+	}
+	if !crypto.Verify(pub, entry.EntryHash, entry.Signature) {
+		return fmt.Errorf("invalid signature by author %s", entry.AuthorId)
+	}
+	return nil
+}
+
+// trackAuthors applies a verified governance entry to keys, so that later
+// entries by added or rotated authors can be checked. Only the governance
+// entries under the watched prefix are seen; give authors added outside it
+// with -authors.
+func trackAuthors(keys authors.Set, entry *vdcspb.ConfigEntry) {
+	for _, m := range verlog.Mutations(entry) {
+		id, ok := strings.CutPrefix(m.Key, verlog.AuthorKeyPrefix)
+		if !ok {
+			continue
+		}
+		switch m.Operation {
+		case vdcspb.Operation_OPERATION_ADD_AUTHOR, vdcspb.Operation_OPERATION_ROTATE_KEY:
+			keys[id] = m.Value
+		case vdcspb.Operation_OPERATION_REVOKE_AUTHOR:
+			delete(keys, id)
+		}
+	}
+}
+
+func printEntry(entry *vdcspb.ConfigEntry) {
+	author := entry.AuthorId
+	if entry.Intent != nil {
+		author = fmt.Sprintf("%s via %s", entry.Intent.AuthorId, entry.AuthorId)
+	}
+	for _, m := range verlog.Mutations(entry) {
+		switch m.Operation {
+		case vdcspb.Operation_OPERATION_SET:
+			fmt.Printf("[%d] %s SET %s = %s (Value Hash: %x)\n", entry.Index, author, m.Key, m.Value, m.ValueHash)
+		default:
+			fmt.Printf("[%d] %s %s %s\n", entry.Index, author, m.Operation, m.Key)
+		}
+	}
+}
+
+// verifyCheckpoint checks the node's signature on the checkpoint in state
+// and that the checkpoint describes the same head as the rest of state.
+func verifyCheckpoint(state *vdcspb.ConfigState, nodeKeyHex string) (*checkpoint.Checkpoint, error) {
+	pub, err := parseNodeKey(nodeKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	if state.Checkpoint == nil {
+		return nil, fmt.Errorf("node did not return a checkpoint")
+	}
+	signed := &checkpoint.Signed{Body: state.Checkpoint.Body, Signature: state.Checkpoint.Signature}
+	cp, err := signed.Verify(pub)
+	if err != nil {
+		return nil, err
+	}
+	if cp.LogSize != state.LogSize || !bytes.Equal(cp.LogRoot, state.LogRoot) || !bytes.Equal(cp.StateRoot, state.StateRoot) {
+		return nil, fmt.Errorf("checkpoint does not match reported state")
+	}
+	return cp, nil
+}
+
+func parseNodeKey(nodeKeyHex string) (ed25519.PublicKey, error) {
+	pub, err := hex.DecodeString(nodeKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node key: %w", err)
+	}
+	return ed25519.PublicKey(pub), nil
+}
+
+// verifyConsistency checks that state's log tree extends the previously seen
+// log tree of size lastSize with root lastRoot.
+func verifyConsistency(ctx context.Context, client vdcspb.VDCSClient, lastSize uint64, lastRoot []byte, state *vdcspb.ConfigState) error {
+	if state.LogSize < lastSize {
+		return fmt.Errorf("ALERT: log shrank from %d to %d entries", lastSize, state.LogSize)
+	}
+	resp, err := client.GetConsistencyProof(ctx, &vdcspb.GetConsistencyProofRequest{First: lastSize, Second: state.LogSize})
+	if err != nil {
+		return err
+	}
+	if !merkle.VerifyLogConsistency(lastSize, state.LogSize, lastRoot, state.LogRoot, resp.Hashes) {
+		return fmt.Errorf("ALERT: log at size %d (root %x) is not consistent with size %d (root %x)",
+			state.LogSize, state.LogRoot, lastSize, lastRoot)
+	}
+	return nil
+}
+
 func connect() vdcspb.VDCSClient {
 	conn, err := grpc.NewClient("localhost:9090", grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
@@ -108,25 +342,42 @@ func runSet(args []string) {
 	setCmd := flag.NewFlagSet("set", flag.ExitOnError)
 	key := setCmd.String("key", "", "Key to set")
 	value := setCmd.String("value", "", "Value string")
-	authorID := setCmd.String("author", "admin", "Author ID")
+	authorID := setCmd.String("author", "", "Author ID (default: fingerprint of the private key)")
 	privKeyHex := setCmd.String("priv-key", "", "Private key (hex)")
+	ifHash := setCmd.String("if-hash", "", "Only set if the current value hash (hex) matches")
+	ifAbsent := setCmd.Bool("if-absent", false, "Only set if the key does not exist")
+	ifIndex := setCmd.Int64("if-index", -1, "Only set if the key was last modified at this index")
+	sequenced := setCmd.Bool("sequenced", false, "Sign only the intent and let the node assign the index")
+	ttl := setCmd.Duration("ttl", time.Minute, "How long a sequenced intent stays valid")
+	receiptsDir := setCmd.String("receipts", defaultReceiptsDir, "Directory to store the node's receipt in (empty to discard)")
+	approvers := approveFlag(setCmd)
 
 	if err := setCmd.Parse(args); err != nil {
 		log.Fatal(err)
 	}
+	precondition := parsePrecondition(*ifHash, *ifAbsent, *ifIndex)
 
 	if *key == "" || *privKeyHex == "" {
 		log.Fatal("missing required flags: -key, -priv-key")
 	}
 
-	pkBytes, err := hex.DecodeString(*privKeyHex)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if len(pkBytes) != ed25519.PrivateKeySize {
-		log.Fatalf("invalid private key size: %d", len(pkBytes))
+	privKey := parsePrivKey(*privKeyHex)
+	valHash := crypto.Hash([]byte(*value))
+
+	if *sequenced {
+		intent := &vdcspb.Intent{
+			AuthorId:     authorOf(*authorID, privKey),
+			Key:          *key,
+			Operation:    vdcspb.Operation_OPERATION_SET,
+			ValueHash:    valHash[:],
+			Nonce:        newNonce(),
+			ExpiresAt:    time.Now().Add(*ttl).UnixNano(),
+			Precondition: precondition,
+			Value:        []byte(*value),
+		}
+		signAndProposeIntent(intent, privKey, *approvers, *receiptsDir)
+		return
 	}
-	privKey := ed25519.PrivateKey(pkBytes)
 
 	// 1. Prepare Entry
 	// Wait, we need the next index?
@@ -142,45 +393,335 @@ func runSet(args []string) {
 		log.Fatalf("failed to get state: %v", err)
 	}
 
-	// Index = Version?
-	// Version is "Last Applied Index". So if version=0 (genesis), next is 1?
-	// Or logic depending on 0-based.
-	// If Log size is N, next index is N.
-	// Version is usually N.
-	index := state.Version
+	// Version is the last applied index; the next index is the log size.
+	index := state.LogSize
 
 	fmt.Printf("Proposing at Index %d...\n", index)
 
-	valHash := crypto.Hash([]byte(*value))
+	entry := &vdcspb.ConfigEntry{
+		Index:        index,
+		Timestamp:    time.Now().UnixNano(),
+		AuthorId:     authorOf(*authorID, privKey),
+		Key:          *key,
+		ValueHash:    valHash[:],
+		Operation:    vdcspb.Operation_OPERATION_SET,
+		PrevHash:     state.LastEntryHash,
+		Value:        []byte(*value),
+		Precondition: precondition,
+	}
+
+	signAndPropose(ctx, client, entry, privKey, *approvers, *receiptsDir)
+}
+
+func runTxn(args []string) {
+	txnCmd := flag.NewFlagSet("txn", flag.ExitOnError)
+	authorID := txnCmd.String("author", "", "Author ID (default: fingerprint of the private key)")
+	privKeyHex := txnCmd.String("priv-key", "", "Private key (hex)")
+	receiptsDir := txnCmd.String("receipts", defaultReceiptsDir, "Directory to store the node's receipt in (empty to discard)")
+	approvers := approveFlag(txnCmd)
+
+	// Mutations are applied together, in the order given.
+	var mutations []*vdcspb.Mutation
+	txnCmd.Func("set", "key=value to set (repeatable)", func(s string) error {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("expected key=value, got %q", s)
+		}
+		valHash := crypto.Hash([]byte(value))
+		mutations = append(mutations, &vdcspb.Mutation{
+			Key:       key,
+			ValueHash: valHash[:],
+			Operation: vdcspb.Operation_OPERATION_SET,
+			Value:     []byte(value),
+		})
+		return nil
+	})
+	txnCmd.Func("delete", "key to delete (repeatable)", func(key string) error {
+		if key == "" {
+			return fmt.Errorf("empty key")
+		}
+		mutations = append(mutations, &vdcspb.Mutation{
+			Key:       key,
+			Operation: vdcspb.Operation_OPERATION_DELETE,
+		})
+		return nil
+	})
+
+	if err := txnCmd.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+
+	if len(mutations) == 0 || *privKeyHex == "" {
+		log.Fatal("missing required flags: at least one -set or -delete, -priv-key")
+	}
+	privKey := parsePrivKey(*privKeyHex)
+
+	client := connect()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	state, err := client.GetLatestRoot(ctx, &vdcspb.Empty{})
+	if err != nil {
+		log.Fatalf("failed to get state: %v", err)
+	}
+	index := state.LogSize
+
+	fmt.Printf("Proposing transaction of %d mutations at Index %d...\n", len(mutations), index)
 
 	entry := &vdcspb.ConfigEntry{
 		Index:     index,
 		Timestamp: time.Now().UnixNano(),
-		AuthorId:  *authorID,
-		Key:       *key,
-		ValueHash: valHash[:],
-		Operation: vdcspb.Operation_OPERATION_SET,
+		AuthorId:  authorOf(*authorID, privKey),
+		Operation: vdcspb.Operation_OPERATION_TRANSACTION,
+		PrevHash:  state.LastEntryHash,
+		Mutations: mutations,
+	}
+	signAndPropose(ctx, client, entry, privKey, *approvers, *receiptsDir)
+}
+
+// governOps maps the -op values of govern to governance operations.
+var governOps = map[string]vdcspb.Operation{
+	"add-author":    vdcspb.Operation_OPERATION_ADD_AUTHOR,
+	"revoke-author": vdcspb.Operation_OPERATION_REVOKE_AUTHOR,
+	"rotate-key":    vdcspb.Operation_OPERATION_ROTATE_KEY,
+	"update-policy": vdcspb.Operation_OPERATION_UPDATE_POLICY,
+}
+
+func runGovern(args []string) {
+	governCmd := flag.NewFlagSet("govern", flag.ExitOnError)
+	opName := governCmd.String("op", "", "Operation: add-author, revoke-author, rotate-key, update-policy")
+	id := governCmd.String("id", "", "Author ID to add, revoke or rotate")
+	pubKeyHex := governCmd.String("pub-key", "", "Public key (hex) of the added author, or the new key when rotating")
+	policyPath := governCmd.String("policy", "", "Path to the new JSON write policy")
+	authorID := governCmd.String("author", "", "Author ID (default: fingerprint of the private key)")
+	privKeyHex := governCmd.String("priv-key", "", "Private key (hex)")
+	receiptsDir := governCmd.String("receipts", defaultReceiptsDir, "Directory to store the node's receipt in (empty to discard)")
+	approvers := approveFlag(governCmd)
+
+	if err := governCmd.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	op, ok := governOps[*opName]
+	if !ok || *privKeyHex == "" {
+		log.Fatal("missing required flags: -op (add-author, revoke-author, rotate-key, update-policy), -priv-key")
+	}
+	privKey := parsePrivKey(*privKeyHex)
+
+	// 1. Build the governance key and value
+	var key string
+	var value []byte
+	switch op {
+	case vdcspb.Operation_OPERATION_UPDATE_POLICY:
+		if *policyPath == "" {
+			log.Fatal("missing required flag: -policy")
+		}
+		p, err := policy.Load(*policyPath)
+		if err != nil {
+			log.Fatalf("failed to load policy: %v", err)
+		}
+		// Record the policy in a stable form, independent of the file's layout.
+		value, err = json.Marshal(p)
+		if err != nil {
+			log.Fatal(err)
+		}
+		key = verlog.PolicyKey
+	default:
+		if *id == "" {
+			log.Fatal("missing required flag: -id")
+		}
+		key = verlog.AuthorKey(*id)
+		if op != vdcspb.Operation_OPERATION_REVOKE_AUTHOR {
+			pub, err := hex.DecodeString(*pubKeyHex)
+			if err != nil || len(pub) != ed25519.PublicKeySize {
+				log.Fatal("invalid -pub-key: expected a hex Ed25519 public key")
+			}
+			value = pub
+		}
+	}
+	var valueHash []byte
+	if len(value) > 0 {
+		h := crypto.Hash(value)
+		valueHash = h[:]
+	}
+
+	// 2. Propose it like any other entry
+	client := connect()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	state, err := client.GetLatestRoot(ctx, &vdcspb.Empty{})
+	if err != nil {
+		log.Fatalf("failed to get state: %v", err)
+	}
+	fmt.Printf("Proposing %s of %s at Index %d...\n", *opName, key, state.LogSize)
+
+	entry := &vdcspb.ConfigEntry{
+		Index:     state.LogSize,
+		Timestamp: time.Now().UnixNano(),
+		AuthorId:  authorOf(*authorID, privKey),
+		Key:       key,
+		ValueHash: valueHash,
+		Operation: op,
 		PrevHash:  state.LastEntryHash,
-		Value:     []byte(*value),
+		Value:     value,
+	}
+	signAndPropose(ctx, client, entry, privKey, *approvers, *receiptsDir)
+}
+
+// parsePrecondition builds the precondition selected by the -if-* flags of
+// set. At most one may be given.
+func parsePrecondition(ifHash string, ifAbsent bool, ifIndex int64) *vdcspb.Precondition {
+	var conditions []*vdcspb.Precondition
+	if ifHash != "" {
+		h, err := hex.DecodeString(ifHash)
+		if err != nil {
+			log.Fatalf("invalid -if-hash: %v", err)
+		}
+		conditions = append(conditions, &vdcspb.Precondition{
+			Condition: &vdcspb.Precondition_ExpectedValueHash{ExpectedValueHash: h},
+		})
+	}
+	if ifAbsent {
+		conditions = append(conditions, &vdcspb.Precondition{
+			Condition: &vdcspb.Precondition_MustNotExist{MustNotExist: true},
+		})
+	}
+	if ifIndex >= 0 {
+		conditions = append(conditions, &vdcspb.Precondition{
+			Condition: &vdcspb.Precondition_ExpectedModifiedIndex{ExpectedModifiedIndex: uint64(ifIndex)},
+		})
+	}
+	if len(conditions) > 1 {
+		log.Fatal("only one of -if-hash, -if-absent, -if-index may be given")
+	}
+	if len(conditions) == 0 {
+		return nil
+	}
+	return conditions[0]
+}
+
+func parsePrivKey(privKeyHex string) ed25519.PrivateKey {
+	pkBytes, err := hex.DecodeString(privKeyHex)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(pkBytes) != ed25519.PrivateKeySize {
+		log.Fatalf("invalid private key size: %d", len(pkBytes))
+	}
+	return ed25519.PrivateKey(pkBytes)
+}
+
+// authorOf returns id, or the fingerprint of key's public key if id is
+// empty.
+func authorOf(id string, key ed25519.PrivateKey) string {
+	if id != "" {
+		return id
 	}
+	return crypto.Fingerprint(key.Public().(ed25519.PublicKey))
+}
+
+// approver is a co-signer given with -approve.
+type approver struct {
+	id  string
+	key ed25519.PrivateKey
+}
 
+// approveFlag adds the repeatable -approve flag to fs.
+func approveFlag(fs *flag.FlagSet) *[]approver {
+	var approvers []approver
+	fs.Func("approve", "[author=]private-key (hex) of a co-signer (repeatable; author defaults to the key's fingerprint)", func(s string) error {
+		id, keyHex, ok := strings.Cut(s, "=")
+		if !ok {
+			id, keyHex = "", s
+		} else if id == "" {
+			return fmt.Errorf("expected [author=]private-key, got %q", s)
+		}
+		key, err := hex.DecodeString(keyHex)
+		if err != nil || len(key) != ed25519.PrivateKeySize {
+			return fmt.Errorf("invalid private key for co-signer %q", id)
+		}
+		approvers = append(approvers, approver{id: authorOf(id, key), key: key})
+		return nil
+	})
+	return &approvers
+}
+
+// approve co-signs hash with every approver.
+func approve(hash []byte, approvers []approver) []*vdcspb.Approval {
+	var approvals []*vdcspb.Approval
+	for _, a := range approvers {
+		approvals = append(approvals, &vdcspb.Approval{AuthorId: a.id, Signature: crypto.Sign(a.key, hash)})
+	}
+	return approvals
+}
+
+// signAndPropose fills in EntryHash, Signature and Approvals and submits the
+// entry.
+func signAndPropose(ctx context.Context, client vdcspb.VDCSClient, entry *vdcspb.ConfigEntry, privKey ed25519.PrivateKey, approvers []approver, receiptsDir string) {
 	entryHash, err := verlog.ComputeEntryHash(entry)
 	if err != nil {
 		log.Fatal(err)
 	}
 	entry.EntryHash = entryHash
 	entry.Signature = crypto.Sign(privKey, entryHash)
+	entry.Approvals = approve(entryHash, approvers)
+
+	resp, err := client.ProposeEntry(ctx, entry)
+	if err != nil {
+		proposeFailed(err)
+	}
+	fmt.Printf("Successfully proposed entry %d\n", entry.Index)
+	saveReceipt(receiptsDir, resp)
+}
 
-	_, err = client.ProposeEntry(ctx, entry)
+// signAndProposeIntent signs the intent and submits it for sequencing.
+func signAndProposeIntent(intent *vdcspb.Intent, privKey ed25519.PrivateKey, approvers []approver, receiptsDir string) {
+	intentHash, err := verlog.ComputeIntentHash(intent)
 	if err != nil {
-		log.Fatalf("Propose failed: %v", err)
+		log.Fatal(err)
+	}
+	intent.Signature = crypto.Sign(privKey, intentHash)
+	intent.Approvals = approve(intentHash, approvers)
+
+	client := connect()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	fmt.Printf("Proposing intent %x...\n", intentHash)
+	resp, err := client.ProposeIntent(ctx, intent)
+	if err != nil {
+		proposeFailed(err)
+	}
+	fmt.Printf("Successfully sequenced intent at Index %d (Entry Hash: %x)\n", resp.Index, resp.EntryHash)
+	saveReceipt(receiptsDir, resp)
+}
+
+func newNonce() []byte {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		log.Fatal(err)
 	}
-	fmt.Printf("Successfully proposed entry %d\n", index)
+	return nonce
+}
+
+// proposeFailed reports a rejected proposal and exits.
+func proposeFailed(err error) {
+	for _, d := range status.Convert(err).Details() {
+		if pf, ok := d.(*vdcspb.PreconditionFailure); ok {
+			if pf.Exists {
+				log.Fatalf("Precondition failed: %s has value hash %x (last modified at index %d)",
+					pf.Key, pf.CurrentValueHash, pf.ModifiedIndex)
+			}
+			log.Fatalf("Precondition failed: %s does not exist", pf.Key)
+		}
+	}
+	log.Fatalf("Propose failed: %v", err)
 }
 
 func runGet(args []string) {
 	getCmd := flag.NewFlagSet("get", flag.ExitOnError)
 	key := getCmd.String("key", "", "Key to get")
+	nodeKeyHex := getCmd.String("node-key", "", "Node public key (hex) used to verify the signed checkpoint")
 
 	if err := getCmd.Parse(args); err != nil {
 		log.Fatal(err)
@@ -190,36 +731,165 @@ func runGet(args []string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// 1. Get Trusted Root
-	// In a real system, we get this from a trusted source or via gossip.
-	// Here we query the node (trust-on-first-use or assume node is honest about root for current view)
-	// But effectively verification checks consistency between Root and Proof.
-	state, err := client.GetLatestRoot(ctx, &vdcspb.Empty{})
+	// 1. Get Value, Proof and the Root it was proven against
+	// In a real system, we get the root from a trusted source or via gossip.
+	// Here we trust the node's signed checkpoint (if -node-key is given) and
+	// verify consistency between Root, Proof and Value.
+	resp, err := client.GetValue(ctx, &vdcspb.GetValueRequest{Key: *key})
 	if err != nil {
 		log.Fatal(err)
 	}
+	state := resp.State
+	if state == nil || resp.Proof == nil {
+		log.Fatal("PROOF VERIFICATION FAILED! incomplete response")
+	}
+	if state.TreeFormatVersion != merkle.TreeFormatVersion {
+		log.Fatalf("node uses tree format v%d, this client supports v%d", state.TreeFormatVersion, merkle.TreeFormatVersion)
+	}
+	if *nodeKeyHex != "" {
+		if _, err := verifyCheckpoint(state, *nodeKeyHex); err != nil {
+			log.Fatalf("CHECKPOINT VERIFICATION FAILED! %v", err)
+		}
+		fmt.Println("Verified Node Checkpoint")
+	}
 	fmt.Printf("Trusted Root (Version %d): %x\n", state.Version, state.StateRoot)
 
-	// 2. Get Proof
-	resp, err := client.GetProof(ctx, &vdcspb.GetProofRequest{Key: *key})
-	if err != nil {
-		log.Fatal(err)
+	// 2. Verify Proof
+	if resp.Proof.Key != *key {
+		log.Fatalf("PROOF VERIFICATION FAILED! proof is for key %q", resp.Proof.Key)
 	}
-
-	proof := &merkle.Proof{
-		Key:       resp.Key,
-		ValueHash: resp.ValueHash,
-		Siblings:  resp.Siblings,
-		IsLeft:    resp.IsLeft,
+	proof := &merkle.SparseProof{
+		Key:           resp.Proof.Key,
+		Exists:        resp.Proof.Exists,
+		ValueHash:     resp.Proof.ValueHash,
+		Siblings:      resp.Proof.Siblings,
+		LeafKeyHash:   resp.Proof.LeafKeyHash,
+		LeafValueHash: resp.Proof.LeafValueHash,
 	}
 
-	if proof.Verify(state.StateRoot) {
-		fmt.Printf("Verified Value Hash: %x\n", proof.ValueHash)
-	} else {
+	if !proof.Verify(state.StateRoot) {
 		log.Fatal("PROOF VERIFICATION FAILED!")
 	}
+	if !proof.Exists {
+		fmt.Printf("Verified Absent: %s\n", proof.Key)
+		return
+	}
+	fmt.Printf("Verified Value Hash: %x\n", proof.ValueHash)
+
+	// 3. Verify Value against the proven hash
+	if !resp.HasValue {
+		fmt.Println("Value not available (entry carried only the hash)")
+		return
+	}
+	if valHash := crypto.Hash(resp.Value); !bytes.Equal(valHash[:], proof.ValueHash) {
+		log.Fatal("VALUE VERIFICATION FAILED! value does not match proven hash")
+	}
+	fmt.Printf("Verified Value: %s\n", resp.Value)
+}
+
+// loadAuthors returns the trusted authors from an authors file, if path is
+// not empty, and comma-separated hex keys, named the way vdcs-node names them.
+func loadAuthors(path, hexKeys string) authors.Set {
+	keys := make(authors.Set)
+	if path != "" {
+		var err error
+		if keys, err = authors.Load(path); err != nil {
+			log.Fatalf("failed to load authors: %v", err)
+		}
+	}
+	if err := keys.AddKeys(hexKeys); err != nil {
+		log.Fatalf("failed to parse trusted keys: %v", err)
+	}
+	return keys
 }
 
 func runAudit(args []string) {
-	fmt.Println("Audit not implemented yet")
+	auditCmd := flag.NewFlagSet("audit", flag.ExitOnError)
+	trustedKeys := auditCmd.String("trusted-keys", "", "Comma-separated list of trusted public keys (hex), as given to vdcs-node")
+	authorsPath := auditCmd.String("authors", "", "Path to the node's JSON authors file, if it has one")
+	nodeKeyHex := auditCmd.String("node-key", "", "Node public key (hex), to verify the checkpoint and sequenced entries")
+	pageSize := auditCmd.Uint("page-size", 1000, "Entries to fetch per request")
+	policyPath := auditCmd.String("policy", "", "Path to the node's JSON write policy, if it has one")
+	out := auditCmd.String("out", "", "Write the JSON report to this file instead of stdout")
+
+	if err := auditCmd.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+
+	// 1. Trusted authors, named the way vdcs-node names them
+	keys := loadAuthors(*authorsPath, *trustedKeys)
+
+	client := connect()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	// 2. Head to audit against
+	state, err := client.GetLatestRoot(ctx, &vdcspb.Empty{})
+	if err != nil {
+		log.Fatalf("failed to get state: %v", err)
+	}
+	sequencers := make(map[string][]byte)
+	if *nodeKeyHex != "" {
+		cp, err := verifyCheckpoint(state, *nodeKeyHex)
+		if err != nil {
+			log.Fatalf("CHECKPOINT VERIFICATION FAILED! %v", err)
+		}
+		pub, _ := parseNodeKey(*nodeKeyHex)
+		sequencers[cp.Origin] = pub
+		log.Printf("Verified Node Checkpoint (Log Size %d)", cp.LogSize)
+	}
+
+	// 3. Download and check the log page by page
+	auditor := audit.NewAuditor(keys, sequencers)
+	if *policyPath != "" {
+		p, err := policy.Load(*policyPath)
+		if err != nil {
+			log.Fatalf("failed to load policy: %v", err)
+		}
+		auditor.SetPolicy(p)
+	}
+	// The first failure ends the audit, so the rest of the log is not
+	// downloaded for nothing.
+fetch:
+	for next := uint64(0); next < state.LogSize; {
+		resp, err := client.GetEntries(ctx, &vdcspb.GetEntriesRequest{
+			Start:    next,
+			End:      state.LogSize,
+			PageSize: uint32(*pageSize),
+		})
+		if err != nil {
+			log.Fatalf("failed to fetch entries from %d: %v", next, err)
+		}
+		if len(resp.Entries) == 0 {
+			log.Fatalf("node returned no entries at %d", next)
+		}
+		if want := next + uint64(len(resp.Entries)); resp.NextIndex != want {
+			log.Fatalf("node returned %d entries from %d with next index %d, expected %d", len(resp.Entries), next, resp.NextIndex, want)
+		}
+		for _, entry := range resp.Entries {
+			if err := auditor.Add(entry); err != nil {
+				break fetch
+			}
+		}
+		next = resp.NextIndex
+	}
+
+	// 4. Report
+	report := auditor.Finish(state.LogSize, state.LogRoot, state.StateRoot)
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	data = append(data, '\n')
+	if *out != "" {
+		err = os.WriteFile(*out, data, 0644)
+	} else {
+		_, err = os.Stdout.Write(data)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !report.OK {
+		os.Exit(1)
+	}
 }
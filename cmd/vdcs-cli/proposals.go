@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	verlog "github.com/rrb115/vdcs/internal/log"
+	"github.com/rrb115/vdcs/internal/node"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+// runProposal dispatches the proposal subcommands: submit a change for
+// review, list and show pending proposals, and approve, reject or withdraw
+// them.
+func runProposal(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: vdcs-cli proposal <submit|list|show|approve|reject|withdraw> [args]")
+		os.Exit(1)
+	}
+	switch args[0] {
+	case "submit":
+		runProposalSubmit(args[1:])
+	case "list":
+		runProposalList(args[1:])
+	case "show":
+		runProposalShow(args[1:])
+	case "approve":
+		runProposalApprove(args[1:])
+	case "reject":
+		runProposalReject(args[1:])
+	case "withdraw":
+		runProposalWithdraw(args[1:])
+	default:
+		log.Fatalf("unknown proposal command: %s", args[0])
+	}
+}
+
+func runProposalSubmit(args []string) {
+	submitCmd := flag.NewFlagSet("proposal submit", flag.ExitOnError)
+	key := submitCmd.String("key", "", "Key to change")
+	value := submitCmd.String("value", "", "Value string")
+	del := submitCmd.Bool("delete", false, "Delete the key instead of setting it")
+	authorID := submitCmd.String("author", "", "Author ID (default: fingerprint of the private key)")
+	privKeyHex := submitCmd.String("priv-key", "", "Private key (hex)")
+	ifHash := submitCmd.String("if-hash", "", "Only apply if the current value hash (hex) matches")
+	ifAbsent := submitCmd.Bool("if-absent", false, "Only apply if the key does not exist")
+	ifIndex := submitCmd.Int64("if-index", -1, "Only apply if the key was last modified at this index")
+	ttl := submitCmd.Duration("ttl", 24*time.Hour, "How long the proposal waits for approvals")
+	receiptsDir := submitCmd.String("receipts", defaultReceiptsDir, "Directory to store the node's receipt in (empty to discard)")
+
+	if err := submitCmd.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	if *key == "" || *privKeyHex == "" {
+		log.Fatal("missing required flags: -key, -priv-key")
+	}
+	privKey := parsePrivKey(*privKeyHex)
+
+	intent := &vdcspb.Intent{
+		AuthorId:     authorOf(*authorID, privKey),
+		Key:          *key,
+		Operation:    vdcspb.Operation_OPERATION_SET,
+		Nonce:        newNonce(),
+		ExpiresAt:    time.Now().Add(*ttl).UnixNano(),
+		Precondition: parsePrecondition(*ifHash, *ifAbsent, *ifIndex),
+	}
+	if *del {
+		intent.Operation = vdcspb.Operation_OPERATION_DELETE
+	} else {
+		valHash := crypto.Hash([]byte(*value))
+		intent.ValueHash = valHash[:]
+		intent.Value = []byte(*value)
+	}
+	intentHash, err := verlog.ComputeIntentHash(intent)
+	if err != nil {
+		log.Fatal(err)
+	}
+	intent.Signature = crypto.Sign(privKey, intentHash)
+
+	client := connect()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.SubmitProposal(ctx, intent)
+	if err != nil {
+		proposeFailed(err)
+	}
+	reportProposal(resp, *receiptsDir)
+}
+
+func runProposalList(args []string) {
+	listCmd := flag.NewFlagSet("proposal list", flag.ExitOnError)
+	prefix := listCmd.String("prefix", "", "Only list proposals for keys with this prefix")
+	all := listCmd.Bool("all", false, "Include recently closed proposals")
+
+	if err := listCmd.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+
+	client := connect()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.ListProposals(ctx, &vdcspb.ListProposalsRequest{KeyPrefix: *prefix, IncludeClosed: *all})
+	if err != nil {
+		log.Fatalf("failed to list proposals: %v", err)
+	}
+	if len(resp.Proposals) == 0 {
+		fmt.Println("No proposals")
+	}
+	for _, p := range resp.Proposals {
+		printProposal(p)
+		fmt.Println()
+	}
+}
+
+func runProposalShow(args []string) {
+	showCmd := flag.NewFlagSet("proposal show", flag.ExitOnError)
+	id := showCmd.String("id", "", "Proposal ID")
+
+	if err := showCmd.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	if *id == "" {
+		log.Fatal("missing required flag: -id")
+	}
+
+	client := connect()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	p, err := client.GetProposal(ctx, &vdcspb.GetProposalRequest{Id: *id})
+	if err != nil {
+		log.Fatalf("failed to get proposal: %v", err)
+	}
+	printProposal(p)
+}
+
+func runProposalApprove(args []string) {
+	approveCmd := flag.NewFlagSet("proposal approve", flag.ExitOnError)
+	id := approveCmd.String("id", "", "Proposal ID")
+	authorID := approveCmd.String("author", "", "Author ID (default: fingerprint of the private key)")
+	privKeyHex := approveCmd.String("priv-key", "", "Private key (hex)")
+	receiptsDir := approveCmd.String("receipts", defaultReceiptsDir, "Directory to store the node's receipt in (empty to discard)")
+
+	if err := approveCmd.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	if *id == "" || *privKeyHex == "" {
+		log.Fatal("missing required flags: -id, -priv-key")
+	}
+	privKey := parsePrivKey(*privKeyHex)
+
+	client := connect()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Sign the intent hash we computed ourselves, not the ID the node
+	// reports, so the approval covers the change we reviewed.
+	p, err := client.GetProposal(ctx, &vdcspb.GetProposalRequest{Id: *id})
+	if err != nil {
+		log.Fatalf("failed to get proposal: %v", err)
+	}
+	intentHash, err := verlog.ComputeIntentHash(p.Intent)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if hex.EncodeToString(intentHash) != *id {
+		log.Fatalf("proposal %s does not match its intent", *id)
+	}
+	printProposal(p)
+
+	resp, err := client.ApproveProposal(ctx, &vdcspb.ApproveProposalRequest{
+		Id:       *id,
+		Approval: &vdcspb.Approval{AuthorId: authorOf(*authorID, privKey), Signature: crypto.Sign(privKey, intentHash)},
+	})
+	if err != nil {
+		proposeFailed(err)
+	}
+	reportProposal(resp, *receiptsDir)
+}
+
+func runProposalReject(args []string) {
+	rejectCmd := flag.NewFlagSet("proposal reject", flag.ExitOnError)
+	id := rejectCmd.String("id", "", "Proposal ID")
+	reason := rejectCmd.String("reason", "", "Reason for the rejection")
+	authorID := rejectCmd.String("author", "", "Author ID (default: fingerprint of the private key)")
+	privKeyHex := rejectCmd.String("priv-key", "", "Private key (hex)")
+
+	if err := rejectCmd.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	if *id == "" || *privKeyHex == "" {
+		log.Fatal("missing required flags: -id, -priv-key")
+	}
+	privKey := parsePrivKey(*privKeyHex)
+
+	client := connect()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.RejectProposal(ctx, &vdcspb.RejectProposalRequest{
+		Id:        *id,
+		AuthorId:  authorOf(*authorID, privKey),
+		Reason:    *reason,
+		Signature: crypto.Sign(privKey, node.RejectMessage(*id, *reason)),
+	})
+	if err != nil {
+		proposeFailed(err)
+	}
+	reportProposal(resp, "")
+}
+
+func runProposalWithdraw(args []string) {
+	withdrawCmd := flag.NewFlagSet("proposal withdraw", flag.ExitOnError)
+	id := withdrawCmd.String("id", "", "Proposal ID")
+	privKeyHex := withdrawCmd.String("priv-key", "", "Private key (hex) of the proposal's author")
+
+	if err := withdrawCmd.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	if *id == "" || *privKeyHex == "" {
+		log.Fatal("missing required flags: -id, -priv-key")
+	}
+	privKey := parsePrivKey(*privKeyHex)
+
+	client := connect()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.WithdrawProposal(ctx, &vdcspb.WithdrawProposalRequest{
+		Id:        *id,
+		Signature: crypto.Sign(privKey, node.WithdrawMessage(*id)),
+	})
+	if err != nil {
+		proposeFailed(err)
+	}
+	reportProposal(resp, "")
+}
+
+// reportProposal prints the proposal's status, and stores the receipt if the
+// call committed it.
+func reportProposal(resp *vdcspb.ProposalResponse, receiptsDir string) {
+	p := resp.Proposal
+	fmt.Printf("Proposal %s is %s\n", p.Id, proposalStatus(p.Status))
+	if p.Reason != "" {
+		fmt.Printf("  Reason: %s\n", p.Reason)
+	}
+	if c := resp.Committed; c != nil {
+		fmt.Printf("Committed at Index %d (Entry Hash: %x)\n", c.Index, c.EntryHash)
+		saveReceipt(receiptsDir, c)
+	}
+}
+
+// printProposal prints a proposal with a diff against the current state.
+func printProposal(p *vdcspb.Proposal) {
+	intent := p.Intent
+	fmt.Printf("Proposal %s (%s)\n", p.Id, proposalStatus(p.Status))
+	fmt.Printf("  Author:   %s\n", intent.AuthorId)
+	fmt.Printf("  Expires:  %s\n", time.Unix(0, intent.ExpiresAt).Format(time.RFC3339))
+	var approvers []string
+	for _, a := range intent.Approvals {
+		approvers = append(approvers, a.AuthorId)
+	}
+	fmt.Printf("  Approved: %v\n", approvers)
+	if p.Reason != "" {
+		fmt.Printf("  Reason:   %s\n", p.Reason)
+	}
+	if p.Status == vdcspb.ProposalStatus_PROPOSAL_STATUS_COMMITTED {
+		fmt.Printf("  Index:    %d\n", p.Index)
+	}
+
+	fmt.Printf("  --- %s\n", intent.Key)
+	if cur := p.Current; cur != nil && cur.Exists {
+		fmt.Printf("  - %s (Value Hash: %x, Index %d)\n", cur.Value, cur.ValueHash, cur.ModifiedIndex)
+	} else {
+		fmt.Printf("  - (absent)\n")
+	}
+	if intent.Operation == vdcspb.Operation_OPERATION_DELETE {
+		fmt.Printf("  + (deleted)\n")
+	} else {
+		fmt.Printf("  + %s (Value Hash: %x)\n", intent.Value, intent.ValueHash)
+	}
+}
+
+func proposalStatus(status vdcspb.ProposalStatus) string {
+	return strings.TrimPrefix(status.String(), "PROPOSAL_STATUS_")
+}
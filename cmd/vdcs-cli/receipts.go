@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rrb115/vdcs/internal/checkpoint"
+	verlog "github.com/rrb115/vdcs/internal/log"
+	"github.com/rrb115/vdcs/internal/merkle"
+	"github.com/rrb115/vdcs/internal/state"
+	vdcspb "github.com/rrb115/vdcs/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultReceiptsDir is where set and txn store receipts for verify-receipts.
+const defaultReceiptsDir = "receipts"
+
+// saveReceipt stores the receipt from a ProposeResponse in dir, named by
+// index and entry hash.
+func saveReceipt(dir string, resp *vdcspb.ProposeResponse) {
+	if dir == "" {
+		return
+	}
+	if resp.Receipt == nil {
+		log.Print("WARNING: node did not return a receipt")
+		return
+	}
+	signed := &checkpoint.SignedReceipt{Body: resp.Receipt.Body, Signature: resp.Receipt.Signature}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Fatalf("failed to store receipt: %v", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%020d-%x.receipt", resp.Index, resp.EntryHash[:min(8, len(resp.EntryHash))]))
+	if err := os.WriteFile(path, signed.Marshal(), 0644); err != nil {
+		log.Fatalf("failed to store receipt: %v", err)
+	}
+	fmt.Printf("Stored receipt in %s\n", path)
+}
+
+// runVerifyReceipts checks every stored receipt against the node's latest
+// signed checkpoint. A receipt is satisfied once its entry is included in the
+// checkpoint's log tree, and replaying the checkpointed log up to the entry
+// gives the receipt's state root. A receipt whose entry is missing although
+// the log has grown past its index, or whose merge deadline has passed, is
+// evidence that the node broke its promise.
+func runVerifyReceipts(args []string) {
+	verifyCmd := flag.NewFlagSet("verify-receipts", flag.ExitOnError)
+	dir := verifyCmd.String("dir", defaultReceiptsDir, "Directory of stored receipts")
+	nodeKeyHex := verifyCmd.String("node-key", "", "Node public key (hex)")
+	prune := verifyCmd.Bool("prune", false, "Delete receipts whose entries are included")
+	target := verifyCmd.String("target", "", "URL to report broken receipts to")
+
+	if err := verifyCmd.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	if *nodeKeyHex == "" {
+		log.Fatal("missing required flag: -node-key")
+	}
+	pub, err := parseNodeKey(*nodeKeyHex)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(*dir, "*.receipt"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := connect()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// 1. Get the checkpoint to check receipts against
+	state, err := client.GetLatestRoot(ctx, &vdcspb.Empty{})
+	if err != nil {
+		log.Fatalf("failed to get state: %v", err)
+	}
+	cp, err := verifyCheckpoint(state, *nodeKeyHex)
+	if err != nil {
+		log.Fatalf("CHECKPOINT VERIFICATION FAILED! %v", err)
+	}
+	fmt.Printf("Verified Checkpoint (Log Size %d): %x\n", cp.LogSize, cp.LogRoot)
+
+	// 2. Read the receipts signed by this node
+	type stored struct {
+		path   string
+		signed *checkpoint.SignedReceipt
+		r      *checkpoint.Receipt
+	}
+	var receipts []stored
+	indexes := make(map[uint64]bool)
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		signed, err := checkpoint.ParseSignedReceipt(data)
+		if err != nil {
+			log.Printf("SKIPPED %s: %v", path, err)
+			continue
+		}
+		r, err := signed.Verify(pub)
+		if err != nil {
+			log.Printf("SKIPPED %s: not signed by this node: %v", path, err)
+			continue
+		}
+		receipts = append(receipts, stored{path, signed, r})
+		if r.Index < cp.LogSize {
+			indexes[r.Index] = true
+		}
+	}
+
+	// 3. Replay the checkpointed log for the state roots after the entries
+	roots, err := stateRoots(ctx, client, cp, indexes)
+	if err != nil {
+		log.Fatalf("failed to replay the log: %v", err)
+	}
+
+	// 4. Check each receipt
+	broken := 0
+	for _, rc := range receipts {
+		path, r := rc.path, rc.r
+		result, reason, err := checkReceipt(ctx, client, cp, r, roots)
+		if err != nil {
+			log.Fatalf("failed to check %s: %v", path, err)
+		}
+		switch result {
+		case receiptIncluded:
+			fmt.Printf("OK      %s: entry %d is in the log\n", path, r.Index)
+			if *prune {
+				if err := os.Remove(path); err != nil {
+					log.Fatal(err)
+				}
+			}
+		case receiptPending:
+			fmt.Printf("PENDING %s: entry %d not yet in a checkpoint, deadline %s\n",
+				path, r.Index, r.Deadline().Format(time.RFC3339))
+		case receiptBroken:
+			broken++
+			fmt.Printf("ALERT   %s: node %s broke its receipt for entry %d (%x): %s\n",
+				path, r.Origin, r.Index, r.EntryHash, reason)
+			if *target != "" {
+				reportReceipt(*target, rc.signed, state.Checkpoint, reason)
+			}
+		}
+	}
+
+	if broken > 0 {
+		os.Exit(1)
+	}
+}
+
+type receiptResult int
+
+const (
+	receiptIncluded receiptResult = iota // Entry is in the checkpoint
+	receiptPending                       // Entry is not in the checkpoint, but the deadline has not passed
+	receiptBroken                        // The checkpoint contradicts the receipt
+)
+
+// checkReceipt checks a receipt against a checkpoint, and the state roots
+// after the checkpointed entries from stateRoots. For broken receipts it also
+// returns the reason.
+func checkReceipt(ctx context.Context, client vdcspb.VDCSClient, cp *checkpoint.Checkpoint, r *checkpoint.Receipt, roots map[uint64][]byte) (receiptResult, string, error) {
+	if r.Origin != cp.Origin {
+		return receiptBroken, fmt.Sprintf("receipt is for log %q, checkpoint is for log %q", r.Origin, cp.Origin), nil
+	}
+	if r.Index >= cp.LogSize {
+		// Judge by the checkpoint's own timestamp, so the pair of signed
+		// statements is evidence without trusting our clock.
+		if time.Unix(0, cp.Timestamp).After(r.Deadline()) {
+			return receiptBroken, fmt.Sprintf("checkpoint of size %d signed after the merge deadline %s does not include it",
+				cp.LogSize, r.Deadline().Format(time.RFC3339)), nil
+		}
+		return receiptPending, "", nil
+	}
+
+	resp, err := client.GetInclusionProof(ctx, &vdcspb.GetInclusionProofRequest{Index: r.Index, TreeSize: cp.LogSize})
+	if err != nil {
+		return 0, "", err
+	}
+	if !merkle.VerifyLogInclusion(r.EntryHash, r.Index, cp.LogSize, resp.Hashes, cp.LogRoot) {
+		return receiptBroken, fmt.Sprintf("checkpoint of size %d has a different entry at index %d", cp.LogSize, r.Index), nil
+	}
+	if root := roots[r.Index]; roots != nil && !bytes.Equal(root, r.StateRoot) {
+		return receiptBroken, fmt.Sprintf("the state root after entry %d is %x, not %x", r.Index, root, r.StateRoot), nil
+	}
+	return receiptIncluded, "", nil
+}
+
+// stateRoots replays the log of cp from its first entry up to the highest of
+// indexes, and returns the state root after each of indexes. It checks that
+// every entry hashes correctly and chains to the one before, and that the
+// last one is in cp's log tree, so the chain ties all of them to cp. It
+// returns nil if the node compacted the start of its log.
+func stateRoots(ctx context.Context, client vdcspb.VDCSClient, cp *checkpoint.Checkpoint, indexes map[uint64]bool) (map[uint64][]byte, error) {
+	var end uint64
+	for index := range indexes {
+		end = max(end, index+1)
+	}
+	roots := make(map[uint64][]byte, len(indexes))
+	if end == 0 {
+		return roots, nil
+	}
+
+	sm := state.NewStateMachine()
+	var last *vdcspb.ConfigEntry
+	for next := uint64(0); next < end; {
+		resp, err := client.GetEntries(ctx, &vdcspb.GetEntriesRequest{Start: next, End: end})
+		if status.Code(err) == codes.NotFound {
+			log.Printf("WARNING: state roots not checked, the node compacted its log: %v", err)
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch entries from %d: %w", next, err)
+		}
+		if len(resp.Entries) == 0 {
+			return nil, fmt.Errorf("node returned no entries at %d", next)
+		}
+		for _, entry := range resp.Entries {
+			hash, err := verlog.ComputeEntryHash(entry)
+			if err != nil {
+				return nil, err
+			}
+			if entry.Index != next || !bytes.Equal(hash, entry.EntryHash) {
+				return nil, fmt.Errorf("node returned an invalid entry at %d", next)
+			}
+			if prev := last.GetEntryHash(); !bytes.Equal(entry.PrevHash, prev) {
+				return nil, fmt.Errorf("entry %d does not chain to the entry before it", next)
+			}
+			sm.Apply(entry)
+			if indexes[next] {
+				roots[next] = sm.Root()
+			}
+			last = entry
+			next++
+		}
+	}
+
+	resp, err := client.GetInclusionProof(ctx, &vdcspb.GetInclusionProofRequest{Index: last.Index, TreeSize: cp.LogSize})
+	if err != nil {
+		return nil, err
+	}
+	if !merkle.VerifyLogInclusion(last.EntryHash, last.Index, cp.LogSize, resp.Hashes, cp.LogRoot) {
+		return nil, fmt.Errorf("entry %d is not in the checkpoint", last.Index)
+	}
+	return roots, nil
+}
+
+// reportReceipt posts a broken receipt and the checkpoint contradicting it.
+// Both are signed by the node, so together they are evidence of misbehavior.
+func reportReceipt(target string, receipt *checkpoint.SignedReceipt, cp *vdcspb.SignedCheckpoint, reason string) {
+	payload := map[string]interface{}{
+		"receipt":    string(receipt.Marshal()),
+		"checkpoint": string((&checkpoint.Signed{Body: cp.Body, Signature: cp.Signature}).Marshal()),
+		"reason":     reason,
+		"timestamp":  time.Now().Unix(),
+	}
+	jsonBody, _ := json.Marshal(payload)
+	resp, err := http.Post(target, "application/json", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		log.Printf("ERROR: Failed to report to %s: %v", target, err)
+		return
+	}
+	resp.Body.Close()
+	fmt.Printf("Reported receipt to %s. Status: %s\n", target, resp.Status)
+}
@@ -1,15 +1,21 @@
 package main
 
 import (
+	"crypto/ed25519"
 	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/rrb115/vdcs/internal/authors"
+	"github.com/rrb115/vdcs/internal/crypto"
 	"github.com/rrb115/vdcs/internal/node"
+	"github.com/rrb115/vdcs/internal/policy"
 	"github.com/rrb115/vdcs/internal/server"
 	"github.com/rrb115/vdcs/internal/storage"
 )
@@ -18,27 +24,43 @@ func main() {
 	var (
 		port        = flag.Int("port", 9090, "gRPC server port")
 		dataDir     = flag.String("data", "./data", "Data directory (for log.bin or vdcs.db)")
-		trustedKeys = flag.String("trusted-keys", "", "Comma-separated list of trusted public keys (hex)")
+		trustedKeys = flag.String("trusted-keys", "", "Comma-separated list of trusted public keys (hex), named by fingerprint")
+		authorsPath = flag.String("authors", "", "Path to a JSON file mapping author IDs to public keys (hex)")
 		storageType = flag.String("storage", "sqlite", "Storage type: sqlite, file")
+		nodeKeyPath = flag.String("node-key", "", "Path to the node's private signing key (default <data>/node.key, created if missing)")
+		origin      = flag.String("origin", node.DefaultOrigin, "Log name included in signed checkpoints")
+		mmd         = flag.Duration("mmd", node.DefaultMaxMergeDelay, "Maximum merge delay promised in receipts")
+		maxSkew     = flag.Duration("max-clock-skew", node.DefaultMaxClockSkew, "Maximum distance of a proposed entry's timestamp from the node's clock")
+		recoverLog  = flag.Bool("recover", false, "With -storage file, truncate an incomplete final record left by a crash")
+		segmentSize = flag.Int64("segment-size", storage.DefaultSegmentSize, "With -storage file, the size in bytes at which a log segment is sealed")
+		maxRecord   = flag.Int("max-record-size", storage.DefaultMaxRecordSize, "With -storage file, the largest entry in bytes the log file may hold")
+		policyPath  = flag.String("policy", "", "Path to a JSON write policy (default: trusted authors may write any key)")
+		snapEvery   = flag.Duration("snapshot-interval", 10*time.Minute, "How often to save a signed snapshot of the state to <data>/snapshots (0 disables)")
+		verifySnaps = flag.Bool("verify-snapshots", false, "Replay the whole log at startup and check every snapshot against it")
+		retain      = flag.Uint64("retain-entries", 0, "After each snapshot, compact all but the last N entries (0 keeps every entry)")
+		archiveDir  = flag.String("archive-dir", "", "Move compacted entries to this directory instead of deleting them")
 	)
 	flag.Parse()
 
-	// 1. Parse Trusted Keys
-	keys := make(map[string][]byte)
-	if *trustedKeys != "" {
-		parts := strings.Split(*trustedKeys, ",")
-		for i, part := range parts {
-			part = strings.TrimSpace(part)
-			keyBytes, err := hex.DecodeString(part)
-			if err != nil {
-				log.Fatalf("invalid key format for key %d: %v", i, err)
-			}
-			id := fmt.Sprintf("admin-%d", i)
-			if i == 0 {
-				id = "admin"
-			}
-			keys[id] = keyBytes
+	// 1. Parse Trusted Authors and Policy
+	keys := make(authors.Set)
+	if *authorsPath != "" {
+		var err error
+		if keys, err = authors.Load(*authorsPath); err != nil {
+			log.Fatalf("failed to load authors: %v", err)
+		}
+	}
+	if err := keys.AddKeys(*trustedKeys); err != nil {
+		log.Fatalf("failed to parse trusted keys: %v", err)
+	}
+
+	var writePolicy *policy.Policy
+	if *policyPath != "" {
+		p, err := policy.Load(*policyPath)
+		if err != nil {
+			log.Fatalf("failed to load policy: %v", err)
 		}
+		writePolicy = p
 	}
 
 	// 2. Init Storage
@@ -55,29 +77,98 @@ func main() {
 		store, err = storage.NewSQLiteStore(dbPath)
 	case "file":
 		logPath := filepath.Join(*dataDir, "log.bin")
-		store, err = storage.NewFileStore(logPath)
+		var fileStore *storage.FileStore
+		fileStore, err = storage.OpenFileStore(logPath, storage.FileStoreOptions{
+			MaxRecordSize: *maxRecord,
+			SegmentSize:   *segmentSize,
+			Recover:       *recoverLog,
+		})
+		if err == nil {
+			if n := fileStore.Recovered(); n > 0 {
+				log.Printf("Truncated an incomplete final record (%d bytes) from %s", n, logPath)
+			}
+			store = fileStore
+		}
 	default:
 		log.Fatalf("unknown storage type: %s", *storageType)
 	}
 
+	if errors.Is(err, storage.ErrTornRecord) {
+		log.Fatalf("failed to init storage: %v (restart with -recover to truncate it)", err)
+	}
 	if err != nil {
 		log.Fatalf("failed to init storage: %v", err)
 	}
 
-	// 3. Init Node
+	// 3. Load Node Identity
+	if *nodeKeyPath == "" {
+		*nodeKeyPath = filepath.Join(*dataDir, "node.key")
+	}
+	nodeKey, err := loadOrCreateNodeKey(*nodeKeyPath)
+	if err != nil {
+		store.Close()
+		log.Fatalf("failed to load node key: %v", err)
+	}
+	log.Printf("Node public key: %x", nodeKey.Public())
+
+	// 4. Init Node
 	cfg := node.Config{
-		Store:       store,
-		TrustedKeys: keys,
+		Store:           store,
+		TrustedKeys:     keys,
+		SigningKey:      nodeKey,
+		Origin:          *origin,
+		MaxMergeDelay:   *mmd,
+		MaxClockSkew:    *maxSkew,
+		Policy:          writePolicy,
+		SnapshotDir:     filepath.Join(*dataDir, "snapshots"),
+		VerifySnapshots: *verifySnaps,
 	}
 	n, err := node.NewNode(cfg)
+	if errors.Is(err, node.ErrSnapshotMismatch) {
+		store.Close()
+		log.Fatalf("failed to init node: %v (remove the snapshot to restart from the log)", err)
+	}
 	if err != nil {
 		store.Close() // Clean up if node init fails
 		log.Fatalf("failed to init node: %v", err)
 	}
 	defer n.Close()
 	// Note: n.Close() will close the store.
+	if version, ok := n.RestoredSnapshot(); ok {
+		log.Printf("Restored snapshot at index %d", version)
+	}
+	if *verifySnaps {
+		log.Printf("Snapshots match the log")
+	}
+
+	// 5. Take Snapshots and compact
+	if *snapEvery > 0 {
+		go func() {
+			last, saved := n.RestoredSnapshot()
+			for range time.Tick(*snapEvery) {
+				version, ok, err := n.Snapshot()
+				if err != nil {
+					log.Printf("failed to snapshot: %v", err)
+					continue
+				}
+				if ok && (!saved || version != last) {
+					log.Printf("Saved snapshot at index %d", version)
+					last, saved = version, true
+				}
+				if size := n.GetLatestRoot().LogSize; *retain > 0 && size > *retain {
+					before := n.FirstIndex()
+					first, err := n.Compact(size-*retain, *archiveDir)
+					if err != nil {
+						log.Printf("failed to compact: %v", err)
+					} else if first > before {
+						log.Printf("Compacted entries before index %d", first)
+					}
+				}
+			}
+		}()
+	}
 
-	// 4. Start Server
+	// 6. Start Server
 
 	srv := server.NewServer(n)
 	log.Printf("Starting VDCS node on port %d...", *port)
@@ -86,3 +177,32 @@ func main() {
 		log.Fatalf("server failed: %v", err)
 	}
 }
+
+// loadOrCreateNodeKey reads the node's hex-encoded Ed25519 private key from
+// path, generating and saving a new one if the file does not exist.
+func loadOrCreateNodeKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		_, priv, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(hex.EncodeToString(priv)+"\n"), 0600); err != nil {
+			return nil, err
+		}
+		log.Printf("Generated new node key at %s", path)
+		return priv, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key format in %s: %w", path, err)
+	}
+	if len(keyBytes) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid private key size in %s: %d", path, len(keyBytes))
+	}
+	return ed25519.PrivateKey(keyBytes), nil
+}
@@ -104,9 +104,10 @@ func main() {
 
 	fmt.Println("Starting VDCS Node...")
 	fmt.Println("\n--- Quick Start Commands (Run in new terminal) ---")
-	fmt.Printf("./bin/vdcs-cli set -key \"demo/hello\" -value \"world\" -author \"admin\" -priv-key %s\n", privKey)
+	fmt.Printf("./bin/vdcs-cli set -key \"demo/hello\" -value \"world\" -priv-key %s\n", privKey)
 	fmt.Printf("./bin/vdcs-cli get -key \"demo/hello\"\n")
-	fmt.Println("--------------------------------------------------\n")
+	fmt.Println("--------------------------------------------------")
+	fmt.Println()
 	// Pass stdout/stderr to see logs
 	nodeCmd := exec.Command(absNodePath, "-trusted-keys", pubKey)
 	nodeCmd.Stdout = os.Stdout
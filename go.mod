@@ -3,12 +3,12 @@ module github.com/rrb115/vdcs
 go 1.24.5
 
 require (
+	github.com/mattn/go-sqlite3 v1.14.33
 	google.golang.org/grpc v1.78.0
 	google.golang.org/protobuf v1.36.11
 )
 
 require (
-	github.com/mattn/go-sqlite3 v1.14.33 // indirect
 	golang.org/x/net v0.47.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
@@ -0,0 +1,119 @@
+// Package audit re-verifies a VDCS log from its entries alone.
+//
+// An Auditor replays entries through a fresh ConfigLog and StateMachine, so it
+// applies exactly the checks a node applies when it accepts or replays an
+// entry: index and hash links, timestamp order, the recomputed EntryHash, signatures against
+// the trusted authors, preconditions, and intents. At the end it compares the
+// recomputed log and state roots with the ones the node reported.
+package audit
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/rrb115/vdcs/internal/log"
+	"github.com/rrb115/vdcs/internal/policy"
+	"github.com/rrb115/vdcs/internal/state"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+// Report is the machine-readable result of an audit.
+type Report struct {
+	OK             bool   `json:"ok"`
+	EntriesChecked uint64 `json:"entries_checked"`
+	LogSize        uint64 `json:"log_size"`
+
+	// FailedIndex and Reason describe the first failure. FailedIndex is
+	// unset if the failure is not tied to an entry, e.g. a root mismatch.
+	FailedIndex *uint64 `json:"failed_index,omitempty"`
+	Reason      string  `json:"reason,omitempty"`
+
+	ExpectedLogRoot   string `json:"expected_log_root"`
+	ComputedLogRoot   string `json:"computed_log_root,omitempty"`
+	ExpectedStateRoot string `json:"expected_state_root"`
+	ComputedStateRoot string `json:"computed_state_root,omitempty"`
+}
+
+// Auditor checks a log entry by entry.
+type Auditor struct {
+	log    *log.ConfigLog
+	state  *state.StateMachine
+	failed *Report
+}
+
+// NewAuditor creates an Auditor that trusts the given authors, and the given
+// sequencers for entries wrapping intents.
+func NewAuditor(trustedKeys, sequencers map[string][]byte) *Auditor {
+	l := log.NewConfigLog()
+	for id, key := range trustedKeys {
+		l.AddTrustedAuthor(id, key)
+	}
+	for id, key := range sequencers {
+		l.AddSequencer(id, key)
+	}
+	return &Auditor{
+		log:   l,
+		state: state.NewStateMachine(),
+	}
+}
+
+// SetPolicy checks entries against the node's write policy. Call it before
+// the first Add.
+func (a *Auditor) SetPolicy(p *policy.Policy) {
+	a.log.SetPolicy(p)
+}
+
+// Add checks the next entry and applies it. After the first failure, Add
+// returns that failure again and ignores further entries.
+func (a *Auditor) Add(entry *vdcspb.ConfigEntry) error {
+	if a.failed != nil {
+		return fmt.Errorf("audit failed at index %d: %s", *a.failed.FailedIndex, a.failed.Reason)
+	}
+
+	// Same order as Node.commit.
+	err := a.state.CheckPreconditions(entry)
+	if err == nil {
+		err = a.log.Replay(entry)
+	}
+	if err != nil {
+		index := a.log.Size()
+		a.failed = &Report{FailedIndex: &index, Reason: err.Error()}
+		return err
+	}
+	a.state.Apply(entry)
+	return nil
+}
+
+// Finish compares the audited log with the root the node reported for a log
+// of logSize entries, and returns the report.
+func (a *Auditor) Finish(logSize uint64, logRoot, stateRoot []byte) *Report {
+	report := &Report{}
+	if a.failed != nil {
+		report = a.failed
+	}
+	report.EntriesChecked = a.log.Size()
+	report.LogSize = logSize
+	report.ExpectedLogRoot = hex.EncodeToString(logRoot)
+	report.ExpectedStateRoot = hex.EncodeToString(stateRoot)
+	if a.failed != nil {
+		return report
+	}
+
+	size, computedLogRoot := a.log.Root()
+	computedStateRoot := a.state.Root()
+	report.ComputedLogRoot = hex.EncodeToString(computedLogRoot)
+	report.ComputedStateRoot = hex.EncodeToString(computedStateRoot)
+
+	switch {
+	case size != logSize:
+		report.Reason = fmt.Sprintf("checked %d entries, node reported %d", size, logSize)
+	case !bytes.Equal(computedLogRoot, logRoot):
+		report.Reason = "log root mismatch"
+	case !bytes.Equal(computedStateRoot, stateRoot):
+		report.Reason = "state root mismatch"
+	default:
+		report.OK = true
+	}
+	return report
+}
@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	"github.com/rrb115/vdcs/internal/log"
+	"github.com/rrb115/vdcs/internal/state"
+	vdcspb "github.com/rrb115/vdcs/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// buildLog returns n signed, chained entries and the roots after them.
+func buildLog(t *testing.T, priv ed25519.PrivateKey, n int) ([]*vdcspb.ConfigEntry, []byte, []byte) {
+	t.Helper()
+	l := log.NewConfigLog()
+	l.AddTrustedAuthor("admin", priv.Public().(ed25519.PublicKey))
+	sm := state.NewStateMachine()
+
+	var entries []*vdcspb.ConfigEntry
+	var prevHash []byte
+	for i := 0; i < n; i++ {
+		value := []byte{byte(i)}
+		h := crypto.Hash(value)
+		entry := &vdcspb.ConfigEntry{
+			Index:     uint64(i),
+			Timestamp: int64(100 + i),
+			AuthorId:  "admin",
+			Key:       "key",
+			ValueHash: h[:],
+			Operation: vdcspb.Operation_OPERATION_SET,
+			PrevHash:  prevHash,
+			Value:     value,
+		}
+		entry.EntryHash, _ = log.ComputeEntryHash(entry)
+		entry.Signature = crypto.Sign(priv, entry.EntryHash)
+		if err := l.Append(entry); err != nil {
+			t.Fatal(err)
+		}
+		sm.Apply(entry)
+		entries = append(entries, entry)
+		prevHash = entry.EntryHash
+	}
+	_, logRoot := l.Root()
+	return entries, logRoot, sm.Root()
+}
+
+func TestAudit(t *testing.T) {
+	pub, priv, _ := crypto.GenerateKey()
+	trusted := map[string][]byte{"admin": pub}
+	entries, logRoot, stateRoot := buildLog(t, priv, 5)
+
+	audit := func(entries []*vdcspb.ConfigEntry, size uint64, logRoot, stateRoot []byte) *Report {
+		a := NewAuditor(trusted, nil)
+		for _, e := range entries {
+			if a.Add(e) != nil {
+				break
+			}
+		}
+		return a.Finish(size, logRoot, stateRoot)
+	}
+
+	// 1. Intact log
+	if r := audit(entries, 5, logRoot, stateRoot); !r.OK || r.EntriesChecked != 5 {
+		t.Fatalf("expected intact log to pass: %+v", r)
+	}
+
+	// 2. Bad signature is reported at its index
+	forged := make([]*vdcspb.ConfigEntry, len(entries))
+	copy(forged, entries)
+	_, otherPriv, _ := crypto.GenerateKey()
+	bad := proto.Clone(entries[3]).(*vdcspb.ConfigEntry)
+	bad.Signature = crypto.Sign(otherPriv, bad.EntryHash)
+	forged[3] = bad
+	r := audit(forged, 5, logRoot, stateRoot)
+	if r.OK || r.FailedIndex == nil || *r.FailedIndex != 3 || r.EntriesChecked != 3 {
+		t.Fatalf("expected failure at index 3: %+v", r)
+	}
+
+	// 3. Root mismatches
+	if r := audit(entries, 5, logRoot, logRoot); r.OK || r.FailedIndex != nil || r.Reason != "state root mismatch" {
+		t.Errorf("expected state root mismatch: %+v", r)
+	}
+	if r := audit(entries, 5, stateRoot, stateRoot); r.OK || r.Reason != "log root mismatch" {
+		t.Errorf("expected log root mismatch: %+v", r)
+	}
+	if r := audit(entries[:4], 5, logRoot, stateRoot); r.OK {
+		t.Errorf("expected truncated log to fail: %+v", r)
+	}
+}
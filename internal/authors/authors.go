@@ -0,0 +1,98 @@
+// Package authors binds author IDs to public keys.
+//
+// An author ID is either the fingerprint of its key (see crypto.Fingerprint),
+// which any verifier can check against the key itself, or a name given in an
+// authors file. Either way every key has exactly one ID and every ID one key,
+// so a ConfigEntry's AuthorId resolves to the same key no matter how the
+// node was started.
+package authors
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+)
+
+// Set maps author IDs to public keys.
+type Set map[string][]byte
+
+// Add binds id to pub. Adding the same binding twice is a no-op; binding an
+// ID or a key a second time to something else is an error, as is an ID of
+// the fingerprint form that is not the fingerprint of pub.
+func (s Set) Add(id string, pub []byte) error {
+	if id == "" {
+		return fmt.Errorf("invalid authors: empty author ID")
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid authors: public key of %s is %d bytes", id, len(pub))
+	}
+	if strings.HasPrefix(id, crypto.FingerprintPrefix) && id != crypto.Fingerprint(pub) {
+		return fmt.Errorf("invalid authors: %s is not the fingerprint of its key", id)
+	}
+	if existing, ok := s[id]; ok {
+		if !bytes.Equal(existing, pub) {
+			return fmt.Errorf("invalid authors: %s is bound to two keys", id)
+		}
+		return nil
+	}
+	for other, existing := range s {
+		if bytes.Equal(existing, pub) {
+			return fmt.Errorf("invalid authors: key of %s is also bound to %s", id, other)
+		}
+	}
+	s[id] = append([]byte(nil), pub...)
+	return nil
+}
+
+// AddKeys adds a comma-separated list of hex public keys, each under its
+// fingerprint.
+func (s Set) AddKeys(hexKeys string) error {
+	if hexKeys == "" {
+		return nil
+	}
+	for i, part := range strings.Split(hexKeys, ",") {
+		pub, err := hex.DecodeString(strings.TrimSpace(part))
+		if err != nil {
+			return fmt.Errorf("invalid key format for key %d: %w", i, err)
+		}
+		if err := s.Add(crypto.Fingerprint(pub), pub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Parse parses an authors file: a JSON object mapping author IDs to hex
+// public keys.
+func Parse(data []byte) (Set, error) {
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid authors: %w", err)
+	}
+	s := make(Set, len(raw))
+	for id, keyHex := range raw {
+		pub, err := hex.DecodeString(keyHex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid authors: key of %s: %w", id, err)
+		}
+		if err := s.Add(id, pub); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Load reads an authors file.
+func Load(path string) (Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
@@ -0,0 +1,68 @@
+package authors
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+)
+
+func TestParse(t *testing.T) {
+	pub1, _, _ := crypto.GenerateKey()
+	pub2, _, _ := crypto.GenerateKey()
+	hex1, hex2 := hex.EncodeToString(pub1), hex.EncodeToString(pub2)
+
+	s, err := Parse([]byte(fmt.Sprintf(`{"alice": %q, %q: %q}`, hex1, crypto.Fingerprint(pub2), hex2)))
+	if err != nil {
+		t.Fatalf("failed to parse authors: %v", err)
+	}
+	if !bytes.Equal(s["alice"], pub1) || !bytes.Equal(s[crypto.Fingerprint(pub2)], pub2) {
+		t.Errorf("unexpected authors: %v", s)
+	}
+
+	invalid := map[string]string{
+		"not json":          `["alice"]`,
+		"bad hex":           `{"alice": "zz"}`,
+		"short key":         `{"alice": "abcd"}`,
+		"empty id":          fmt.Sprintf(`{"": %q}`, hex1),
+		"key bound twice":   fmt.Sprintf(`{"alice": %q, "bob": %q}`, hex1, hex1),
+		"wrong fingerprint": fmt.Sprintf(`{%q: %q}`, crypto.Fingerprint(pub1), hex2),
+	}
+	for name, data := range invalid {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestAddKeys(t *testing.T) {
+	pub1, _, _ := crypto.GenerateKey()
+	pub2, _, _ := crypto.GenerateKey()
+	hex1, hex2 := hex.EncodeToString(pub1), hex.EncodeToString(pub2)
+
+	// Keys are named by fingerprint, so their order does not matter.
+	a, b := make(Set), make(Set)
+	if err := a.AddKeys(hex1 + "," + hex2); err != nil {
+		t.Fatalf("failed to add keys: %v", err)
+	}
+	if err := b.AddKeys(hex2 + ", " + hex1); err != nil {
+		t.Fatalf("failed to add keys: %v", err)
+	}
+	for id, pub := range a {
+		if !bytes.Equal(b[id], pub) {
+			t.Errorf("%s is bound to different keys", id)
+		}
+	}
+
+	// A key already named in an authors file cannot get a second ID.
+	s := Set{"alice": pub1}
+	if err := s.AddKeys(hex1); err == nil {
+		t.Error("expected an error for a key bound to two IDs")
+	}
+	// Listing a fingerprint-named key again is harmless.
+	if err := a.AddKeys(hex1); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
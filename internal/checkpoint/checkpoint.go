@@ -0,0 +1,162 @@
+// Package checkpoint defines the signed tree heads published by a VDCS node.
+//
+// A checkpoint body is UTF-8 text made of exactly six lines, each terminated
+// by a single '\n':
+//
+//	vdcs-checkpoint/v1
+//	<origin>
+//	<log size, decimal>
+//	<log root, standard base64>
+//	<state root, standard base64>
+//	<timestamp, decimal Unix nanoseconds>
+//
+// The node signs the body bytes with Ed25519. Because the body is canonical
+// (Parse rejects any other spelling of the same values), two checkpoints
+// describe the same head exactly when their bodies are byte-for-byte equal,
+// and a pair of validly signed bodies with the same origin and log size but
+// different roots is proof of equivocation.
+//
+// For storage, a signed checkpoint is serialized as the body followed by a
+// blank line and the standard base64 signature on its own line.
+//
+// The package also defines receipts: the node's signed promise that an entry
+// will appear in its checkpoints (see Receipt).
+package checkpoint
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+)
+
+// Header is the first line of every checkpoint body. It versions the encoding.
+const Header = "vdcs-checkpoint/v1"
+
+var (
+	ErrMalformed        = errors.New("malformed checkpoint")
+	ErrInvalidSignature = errors.New("invalid checkpoint signature")
+)
+
+// Checkpoint is a statement by a node about the head of its log.
+type Checkpoint struct {
+	Origin    string // Identifies the log, e.g. the node's name
+	LogSize   uint64 // Number of entries in the log
+	LogRoot   []byte // RFC 6962 root of the log tree
+	StateRoot []byte // Root of the state sparse Merkle tree
+	Timestamp int64  // Unix nanos when the checkpoint was signed
+}
+
+// Marshal returns the canonical text body of the checkpoint.
+func (c *Checkpoint) Marshal() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s\n", Header)
+	fmt.Fprintf(&b, "%s\n", c.Origin)
+	fmt.Fprintf(&b, "%d\n", c.LogSize)
+	fmt.Fprintf(&b, "%s\n", base64.StdEncoding.EncodeToString(c.LogRoot))
+	fmt.Fprintf(&b, "%s\n", base64.StdEncoding.EncodeToString(c.StateRoot))
+	fmt.Fprintf(&b, "%d\n", c.Timestamp)
+	return b.Bytes()
+}
+
+// Parse decodes a checkpoint body. It only accepts the canonical encoding.
+func Parse(body []byte) (*Checkpoint, error) {
+	lines := strings.Split(string(body), "\n")
+	if len(lines) != 7 || lines[6] != "" {
+		return nil, fmt.Errorf("%w: expected 6 lines", ErrMalformed)
+	}
+	if lines[0] != Header {
+		return nil, fmt.Errorf("%w: unknown header %q", ErrMalformed, lines[0])
+	}
+
+	c := &Checkpoint{Origin: lines[1]}
+	var err error
+	if c.LogSize, err = strconv.ParseUint(lines[2], 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: log size: %v", ErrMalformed, err)
+	}
+	if c.LogRoot, err = base64.StdEncoding.DecodeString(lines[3]); err != nil {
+		return nil, fmt.Errorf("%w: log root: %v", ErrMalformed, err)
+	}
+	if c.StateRoot, err = base64.StdEncoding.DecodeString(lines[4]); err != nil {
+		return nil, fmt.Errorf("%w: state root: %v", ErrMalformed, err)
+	}
+	if c.Timestamp, err = strconv.ParseInt(lines[5], 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: timestamp: %v", ErrMalformed, err)
+	}
+
+	// Reject alternative spellings (leading zeros, "+1", ...).
+	if !bytes.Equal(c.Marshal(), body) {
+		return nil, fmt.Errorf("%w: non-canonical encoding", ErrMalformed)
+	}
+	return c, nil
+}
+
+// Signed is a checkpoint body together with the node's signature over it.
+type Signed struct {
+	Body      []byte
+	Signature []byte
+}
+
+// Sign signs the checkpoint with the node's private key.
+func Sign(c *Checkpoint, privKey ed25519.PrivateKey) *Signed {
+	body := c.Marshal()
+	return &Signed{
+		Body:      body,
+		Signature: crypto.Sign(privKey, body),
+	}
+}
+
+// Verify checks the signature against the node's public key and returns the
+// parsed checkpoint.
+func (s *Signed) Verify(pubKey ed25519.PublicKey) (*Checkpoint, error) {
+	if len(pubKey) != ed25519.PublicKeySize || !crypto.Verify(pubKey, s.Body, s.Signature) {
+		return nil, ErrInvalidSignature
+	}
+	return Parse(s.Body)
+}
+
+// Marshal serializes the signed checkpoint for storage:
+// the body, a blank line, then the base64 signature and a newline.
+func (s *Signed) Marshal() []byte {
+	return marshalSigned(s.Body, s.Signature)
+}
+
+// ParseSigned decodes a signed checkpoint produced by Signed.Marshal.
+// It does not verify the signature.
+func ParseSigned(data []byte) (*Signed, error) {
+	body, sig, err := splitSigned(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := Parse(body); err != nil {
+		return nil, err
+	}
+	return &Signed{Body: body, Signature: sig}, nil
+}
+
+func marshalSigned(body, sig []byte) []byte {
+	var b bytes.Buffer
+	b.Write(body)
+	b.WriteString("\n")
+	b.WriteString(base64.StdEncoding.EncodeToString(sig))
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+// splitSigned splits the storage form of a signed body into body and signature.
+func splitSigned(data []byte) (body, sig []byte, err error) {
+	i := bytes.LastIndex(data, []byte("\n\n"))
+	if i < 0 || !bytes.HasSuffix(data, []byte("\n")) {
+		return nil, nil, fmt.Errorf("%w: missing signature", ErrMalformed)
+	}
+	sig, err = base64.StdEncoding.DecodeString(string(data[i+2 : len(data)-1]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: signature: %v", ErrMalformed, err)
+	}
+	return data[:i+1], sig, nil
+}
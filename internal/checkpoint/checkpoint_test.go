@@ -0,0 +1,93 @@
+package checkpoint
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+)
+
+func testCheckpoint() *Checkpoint {
+	logRoot := crypto.Hash([]byte("log"))
+	stateRoot := crypto.Hash([]byte("state"))
+	return &Checkpoint{
+		Origin:    "vdcs.example.com",
+		LogSize:   42,
+		LogRoot:   logRoot[:],
+		StateRoot: stateRoot[:],
+		Timestamp: 1700000000000000000,
+	}
+}
+
+func TestCheckpointEncoding(t *testing.T) {
+	c := testCheckpoint()
+	body := c.Marshal()
+
+	expected := "vdcs-checkpoint/v1\n" +
+		"vdcs.example.com\n" +
+		"42\n" +
+		"g2/xhOe0Gx4Ty1/Yn6HemNu6uZ6dKRiRP/Q7hqXHwhM=\n" +
+		"S6aXNcpTdl7WpwnttWxuoja3GTo7KaazkMNG8PQ0Dk4=\n" +
+		"1700000000000000000\n"
+	if string(body) != expected {
+		t.Fatalf("unexpected encoding:\n%s", body)
+	}
+
+	parsed, err := Parse(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(parsed.Marshal(), body) {
+		t.Error("round trip changed the checkpoint")
+	}
+
+	// Non-canonical spellings of the same values must be rejected.
+	for _, bad := range []string{
+		string(bytes.Replace(body, []byte("\n42\n"), []byte("\n042\n"), 1)),
+		string(bytes.Replace(body, []byte("vdcs-checkpoint/v1"), []byte("vdcs-checkpoint/v2"), 1)),
+		string(body) + "extra\n",
+		string(body[:len(body)-1]),
+	} {
+		if _, err := Parse([]byte(bad)); !errors.Is(err, ErrMalformed) {
+			t.Errorf("expected ErrMalformed for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	pub, priv, _ := crypto.GenerateKey()
+	signed := Sign(testCheckpoint(), priv)
+
+	c, err := signed.Verify(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.LogSize != 42 {
+		t.Errorf("expected log size 42, got %d", c.LogSize)
+	}
+
+	// 1. Wrong key
+	otherPub, _, _ := crypto.GenerateKey()
+	if _, err := signed.Verify(otherPub); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for wrong key, got %v", err)
+	}
+
+	// 2. Tampered body
+	tampered := &Signed{
+		Body:      bytes.Replace(signed.Body, []byte("\n42\n"), []byte("\n43\n"), 1),
+		Signature: signed.Signature,
+	}
+	if _, err := tampered.Verify(pub); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for tampered body, got %v", err)
+	}
+
+	// 3. Storage round trip
+	stored, err := ParseSigned(signed.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stored.Verify(pub); err != nil {
+		t.Errorf("stored checkpoint failed to verify: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package checkpoint
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+)
+
+// ReceiptHeader is the first line of every receipt body. It versions the
+// encoding and keeps receipt and checkpoint signatures apart.
+const ReceiptHeader = "vdcs-receipt/v1"
+
+// Receipt is a node's promise that it accepted an entry at an index. Like a
+// Certificate Transparency SCT, it lets the writer hold the node to account:
+// every checkpoint the node signs at or after Timestamp + MaxMergeDelay must
+// include the entry.
+//
+// A receipt body is seven '\n'-terminated lines:
+//
+//	vdcs-receipt/v1
+//	<origin>
+//	<index, decimal>
+//	<entry hash, standard base64>
+//	<state root after the entry, standard base64>
+//	<timestamp, decimal Unix nanoseconds>
+//	<maximum merge delay, decimal seconds>
+type Receipt struct {
+	Origin        string        // Identifies the log, as in Checkpoint
+	Index         uint64        // Index assigned to the entry
+	EntryHash     []byte        // EntryHash of the accepted entry
+	StateRoot     []byte        // State root after applying the entry
+	Timestamp     int64         // Unix nanos when the receipt was signed
+	MaxMergeDelay time.Duration // Whole seconds
+}
+
+// Deadline returns the time by which the entry must appear in a checkpoint.
+func (r *Receipt) Deadline() time.Time {
+	return time.Unix(0, r.Timestamp).Add(r.MaxMergeDelay)
+}
+
+// Marshal returns the canonical text body of the receipt.
+func (r *Receipt) Marshal() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s\n", ReceiptHeader)
+	fmt.Fprintf(&b, "%s\n", r.Origin)
+	fmt.Fprintf(&b, "%d\n", r.Index)
+	fmt.Fprintf(&b, "%s\n", base64.StdEncoding.EncodeToString(r.EntryHash))
+	fmt.Fprintf(&b, "%s\n", base64.StdEncoding.EncodeToString(r.StateRoot))
+	fmt.Fprintf(&b, "%d\n", r.Timestamp)
+	fmt.Fprintf(&b, "%d\n", int64(r.MaxMergeDelay/time.Second))
+	return b.Bytes()
+}
+
+// ParseReceipt decodes a receipt body. It only accepts the canonical encoding.
+func ParseReceipt(body []byte) (*Receipt, error) {
+	lines := strings.Split(string(body), "\n")
+	if len(lines) != 8 || lines[7] != "" {
+		return nil, fmt.Errorf("%w: expected 7 lines", ErrMalformed)
+	}
+	if lines[0] != ReceiptHeader {
+		return nil, fmt.Errorf("%w: unknown header %q", ErrMalformed, lines[0])
+	}
+
+	r := &Receipt{Origin: lines[1]}
+	var err error
+	if r.Index, err = strconv.ParseUint(lines[2], 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: index: %v", ErrMalformed, err)
+	}
+	if r.EntryHash, err = base64.StdEncoding.DecodeString(lines[3]); err != nil {
+		return nil, fmt.Errorf("%w: entry hash: %v", ErrMalformed, err)
+	}
+	if r.StateRoot, err = base64.StdEncoding.DecodeString(lines[4]); err != nil {
+		return nil, fmt.Errorf("%w: state root: %v", ErrMalformed, err)
+	}
+	if r.Timestamp, err = strconv.ParseInt(lines[5], 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: timestamp: %v", ErrMalformed, err)
+	}
+	mmd, err := strconv.ParseInt(lines[6], 10, 64)
+	if err != nil || mmd < 0 {
+		return nil, fmt.Errorf("%w: max merge delay: %q", ErrMalformed, lines[6])
+	}
+	r.MaxMergeDelay = time.Duration(mmd) * time.Second
+
+	if !bytes.Equal(r.Marshal(), body) {
+		return nil, fmt.Errorf("%w: non-canonical encoding", ErrMalformed)
+	}
+	return r, nil
+}
+
+// SignedReceipt is a receipt body together with the node's signature over it.
+type SignedReceipt struct {
+	Body      []byte
+	Signature []byte
+}
+
+// SignReceipt signs the receipt with the node's private key.
+func SignReceipt(r *Receipt, privKey ed25519.PrivateKey) *SignedReceipt {
+	body := r.Marshal()
+	return &SignedReceipt{
+		Body:      body,
+		Signature: crypto.Sign(privKey, body),
+	}
+}
+
+// Verify checks the signature against the node's public key and returns the
+// parsed receipt.
+func (s *SignedReceipt) Verify(pubKey ed25519.PublicKey) (*Receipt, error) {
+	if len(pubKey) != ed25519.PublicKeySize || !crypto.Verify(pubKey, s.Body, s.Signature) {
+		return nil, ErrInvalidSignature
+	}
+	return ParseReceipt(s.Body)
+}
+
+// Marshal serializes the signed receipt for storage, in the same form as
+// Signed.Marshal.
+func (s *SignedReceipt) Marshal() []byte {
+	return marshalSigned(s.Body, s.Signature)
+}
+
+// ParseSignedReceipt decodes a signed receipt produced by
+// SignedReceipt.Marshal. It does not verify the signature.
+func ParseSignedReceipt(data []byte) (*SignedReceipt, error) {
+	body, sig, err := splitSigned(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ParseReceipt(body); err != nil {
+		return nil, err
+	}
+	return &SignedReceipt{Body: body, Signature: sig}, nil
+}
@@ -0,0 +1,84 @@
+package checkpoint
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+)
+
+func testReceipt() *Receipt {
+	entryHash := crypto.Hash([]byte("entry"))
+	stateRoot := crypto.Hash([]byte("state"))
+	return &Receipt{
+		Origin:        "vdcs.example.com",
+		Index:         7,
+		EntryHash:     entryHash[:],
+		StateRoot:     stateRoot[:],
+		Timestamp:     1700000000000000000,
+		MaxMergeDelay: 5 * time.Minute,
+	}
+}
+
+func TestReceiptEncoding(t *testing.T) {
+	r := testReceipt()
+	body := r.Marshal()
+
+	parsed, err := ParseReceipt(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(parsed.Marshal(), body) {
+		t.Error("round trip changed the receipt")
+	}
+	if !parsed.Deadline().Equal(time.Unix(0, r.Timestamp).Add(5 * time.Minute)) {
+		t.Errorf("unexpected deadline %v", parsed.Deadline())
+	}
+
+	// A checkpoint body is not a receipt and vice versa.
+	if _, err := ParseReceipt(testCheckpoint().Marshal()); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expected ErrMalformed for checkpoint body, got %v", err)
+	}
+	if _, err := Parse(body); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expected ErrMalformed for receipt body, got %v", err)
+	}
+	if _, err := ParseReceipt(bytes.Replace(body, []byte("\n300\n"), []byte("\n-1\n"), 1)); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expected ErrMalformed for negative delay, got %v", err)
+	}
+}
+
+func TestSignAndVerifyReceipt(t *testing.T) {
+	pub, priv, _ := crypto.GenerateKey()
+	signed := SignReceipt(testReceipt(), priv)
+
+	r, err := signed.Verify(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Index != 7 {
+		t.Errorf("expected index 7, got %d", r.Index)
+	}
+
+	// 1. Tampered body
+	tampered := &SignedReceipt{
+		Body:      bytes.Replace(signed.Body, []byte("\n7\n"), []byte("\n8\n"), 1),
+		Signature: signed.Signature,
+	}
+	if _, err := tampered.Verify(pub); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for tampered body, got %v", err)
+	}
+
+	// 2. Storage round trip
+	stored, err := ParseSignedReceipt(signed.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stored.Verify(pub); err != nil {
+		t.Errorf("stored receipt failed to verify: %v", err)
+	}
+	if _, err := ParseSigned(signed.Marshal()); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expected ErrMalformed parsing a receipt as a checkpoint, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package crypto
 import (
 	"crypto/ed25519"
 	"crypto/sha256"
+	"encoding/hex"
 )
 
 // Hash computes the SHA-256 hash of the input data.
@@ -24,3 +25,13 @@ func Sign(privateKey ed25519.PrivateKey, message []byte) []byte {
 func Verify(publicKey ed25519.PublicKey, message, signature []byte) bool {
 	return ed25519.Verify(publicKey, message, signature)
 }
+
+// FingerprintPrefix marks author IDs derived from a public key.
+const FingerprintPrefix = "ed25519:"
+
+// Fingerprint returns the author ID bound to a public key: FingerprintPrefix
+// followed by the hex of the first 16 bytes of its SHA-256 hash.
+func Fingerprint(publicKey ed25519.PublicKey) string {
+	h := Hash(publicKey)
+	return FingerprintPrefix + hex.EncodeToString(h[:16])
+}
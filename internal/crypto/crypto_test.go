@@ -3,6 +3,7 @@ package crypto
 import (
 	"crypto/ed25519"
 	"encoding/hex"
+	"strings"
 	"testing"
 )
 
@@ -40,3 +41,19 @@ func TestSignAndVerify(t *testing.T) {
 		t.Error("verification succeeded for tampered data")
 	}
 }
+
+func TestFingerprint(t *testing.T) {
+	pub1, _, _ := GenerateKey()
+	pub2, _, _ := GenerateKey()
+
+	fp := Fingerprint(pub1)
+	if !strings.HasPrefix(fp, FingerprintPrefix) || len(fp) != len(FingerprintPrefix)+32 {
+		t.Errorf("unexpected fingerprint format: %s", fp)
+	}
+	if Fingerprint(pub1) != fp {
+		t.Error("fingerprint is not deterministic")
+	}
+	if Fingerprint(pub2) == fp {
+		t.Error("different keys have the same fingerprint")
+	}
+}
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"fmt"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+// Approvals returns the co-signatures of an entry: those of its intent for
+// sequenced entries, and its own otherwise.
+func Approvals(entry *vdcspb.ConfigEntry) []*vdcspb.Approval {
+	if entry.Intent != nil {
+		return entry.Intent.Approvals
+	}
+	return entry.Approvals
+}
+
+// ApprovalHash returns the hash approvals of entry sign: the intent hash of a
+// sequenced entry, and EntryHash otherwise.
+func ApprovalHash(entry *vdcspb.ConfigEntry) ([]byte, error) {
+	if entry.Intent != nil {
+		return ComputeIntentHash(entry.Intent)
+	}
+	return entry.EntryHash, nil
+}
+
+// signers verifies the approvals of entry and returns the distinct authors
+// that signed it, starting with its author. Callers must hold l.mu and have
+// verified the author's signature.
+func (l *ConfigLog) signers(entry *vdcspb.ConfigEntry) ([]string, error) {
+	author := entry.AuthorId
+	if entry.Intent != nil {
+		if len(entry.Approvals) > 0 {
+			return nil, fmt.Errorf("%w: approvals of a sequenced entry belong in its intent", ErrInvalidApproval)
+		}
+		author = entry.Intent.AuthorId
+	}
+	approvals := Approvals(entry)
+	if len(approvals) == 0 {
+		return []string{author}, nil
+	}
+
+	hash, err := ApprovalHash(entry)
+	if err != nil {
+		return nil, err
+	}
+	signers := []string{author}
+	seen := map[string]struct{}{author: {}}
+	for _, a := range approvals {
+		if _, dup := seen[a.AuthorId]; dup {
+			return nil, fmt.Errorf("%w: %s signed twice", ErrInvalidApproval, a.AuthorId)
+		}
+		seen[a.AuthorId] = struct{}{}
+		if _, ok := l.trustedKeys[a.AuthorId]; !ok {
+			return nil, fmt.Errorf("%w: author %s not trusted", ErrInvalidApproval, a.AuthorId)
+		}
+		if !crypto.Verify(l.authorConfig[a.AuthorId].PublicKey, hash, a.Signature) {
+			return nil, fmt.Errorf("%w: bad signature by %s", ErrInvalidApproval, a.AuthorId)
+		}
+		signers = append(signers, a.AuthorId)
+	}
+	return signers, nil
+}
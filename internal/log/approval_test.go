@@ -0,0 +1,131 @@
+package log
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	"github.com/rrb115/vdcs/internal/policy"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+func TestLogApprovals(t *testing.T) {
+	keys := make(map[string][]byte)
+	privs := make(map[string][]byte)
+	for _, id := range []string{"alice", "bob", "carol", "mallory"} {
+		pub, priv, _ := crypto.GenerateKey()
+		keys[id], privs[id] = pub, priv
+	}
+	seqPub, seqPriv, _ := crypto.GenerateKey()
+	newPolicyLog := func() *ConfigLog {
+		l := newLog(map[string][]byte{"alice": keys["alice"], "bob": keys["bob"], "carol": keys["carol"]})
+		l.AddSequencer("node", seqPub)
+		l.SetPolicy(&policy.Policy{
+			Groups: map[string][]string{"dba": {"alice", "bob"}},
+			Rules: []policy.Rule{
+				{Name: "all", Effect: policy.Allow, Authors: []string{policy.AnyAuthor}, Prefixes: []string{""}},
+			},
+			Thresholds: []policy.Threshold{
+				{Name: "prod-db", Signers: 2, Groups: []string{"dba"}, Prefixes: []string{"prod/db/"}},
+			},
+		})
+		return l
+	}
+	l := newPolicyLog()
+
+	var appended []*vdcspb.ConfigEntry
+	approval := func(id string, hash []byte) *vdcspb.Approval {
+		return &vdcspb.Approval{AuthorId: id, Signature: crypto.Sign(privs[id], hash)}
+	}
+	// propose signs a delete of key by alice, co-signed by approvers.
+	propose := func(key string, approvers ...string) error {
+		entry := sign(t, next(l, &vdcspb.ConfigEntry{
+			Timestamp: 100,
+			AuthorId:  "alice",
+			Key:       key,
+			Operation: vdcspb.Operation_OPERATION_DELETE,
+		}), privs["alice"])
+		for _, id := range approvers {
+			entry.Approvals = append(entry.Approvals, approval(id, entry.EntryHash))
+		}
+		if err := l.Append(entry); err != nil {
+			return err
+		}
+		appended = append(appended, entry)
+		return nil
+	}
+
+	// 1. Thresholds only apply to their prefixes
+	if err := propose("dev/db/password"); err != nil {
+		t.Fatalf("unprotected key needed approvals: %v", err)
+	}
+
+	// 2. Not enough signers that count
+	var ae *policy.ApprovalError
+	if err := propose("prod/db/password"); !errors.As(err, &ae) || ae.Threshold != "prod-db" || ae.Signers != 1 {
+		t.Fatalf("expected approval error for a single signer, got %v", err)
+	}
+	if err := propose("prod/db/password", "carol"); !errors.Is(err, policy.ErrNeedsApprovals) {
+		t.Fatalf("expected ErrNeedsApprovals for a signer outside the group, got %v", err)
+	}
+
+	// 3. Invalid approvals
+	for _, approvers := range [][]string{{"alice"}, {"bob", "bob"}, {"mallory"}} {
+		if err := propose("prod/db/password", approvers...); !errors.Is(err, ErrInvalidApproval) {
+			t.Errorf("approvals %v: expected ErrInvalidApproval, got %v", approvers, err)
+		}
+	}
+
+	// 4. Two distinct signers from the group
+	if err := propose("prod/db/password", "bob"); err != nil {
+		t.Fatalf("approved entry rejected: %v", err)
+	}
+
+	// 5. Sequenced entries carry approvals in the intent
+	intent := &vdcspb.Intent{
+		AuthorId:  "bob",
+		Key:       "prod/db/password",
+		Operation: vdcspb.Operation_OPERATION_DELETE,
+		Nonce:     []byte("n1"),
+		ExpiresAt: 1000,
+	}
+	intentHash := signIntent(t, intent, privs["bob"])
+	intent.Approvals = []*vdcspb.Approval{approval("alice", intentHash)}
+	entry := sign(t, next(l, &vdcspb.ConfigEntry{
+		Timestamp: 100,
+		AuthorId:  "node",
+		Key:       intent.Key,
+		Operation: intent.Operation,
+		Intent:    intent,
+	}), seqPriv)
+	if err := l.Append(entry); err != nil {
+		t.Fatalf("approved sequenced entry rejected: %v", err)
+	}
+	appended = append(appended, entry)
+
+	// 6. Replay reaches the same decisions
+	replayed := newPolicyLog()
+	for i, e := range appended {
+		if err := replayed.Append(e); err != nil {
+			t.Fatalf("replay failed at index %d: %v", i, err)
+		}
+	}
+	// Approvals are not part of EntryHash, so stripping them is only caught
+	// by the threshold.
+	stripped := newPolicyLog()
+	for i, e := range appended {
+		err := stripped.Append(&vdcspb.ConfigEntry{
+			Index: e.Index, Timestamp: e.Timestamp, AuthorId: e.AuthorId, Key: e.Key,
+			Operation: e.Operation, PrevHash: e.PrevHash, EntryHash: e.EntryHash, Signature: e.Signature,
+		})
+		if i == 1 {
+			if !errors.Is(err, policy.ErrNeedsApprovals) {
+				t.Fatalf("expected stripped approvals to be rejected, got %v", err)
+			}
+			break
+		}
+		if err != nil {
+			t.Fatalf("replay failed at index %d: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rrb115/vdcs/internal/merkle"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+// Compaction is all the log keeps of compacted entries: how many there are,
+// the EntryHash of the last one, which the next entry chains to, and the
+// frontier of the log tree over them. Its size is O(log n).
+type Compaction struct {
+	Size     uint64   `json:"size"`      // Entries [0, Size) are compacted
+	HeadHash []byte   `json:"head_hash"` // EntryHash of entry Size-1
+	Frontier [][]byte `json:"frontier"`  // See merkle.LogTree.Frontier
+}
+
+// FirstIndex returns the index of the first entry that was not compacted.
+func (l *ConfigLog) FirstIndex() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.first()
+}
+
+// Compaction returns what Compact(before) would keep of the entries before
+// index before, or nil if before is 0. before must not be below FirstIndex.
+func (l *ConfigLog) Compaction(before uint64) (*Compaction, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.compaction(before)
+}
+
+// Compacted returns what the log keeps of the compacted entries, or nil if
+// none were compacted.
+func (l *ConfigLog) Compacted() *Compaction {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	c, _ := l.compaction(l.first())
+	return c
+}
+
+// compaction implements Compaction. Callers must hold l.mu.
+func (l *ConfigLog) compaction(before uint64) (*Compaction, error) {
+	if before > l.size() {
+		return nil, fmt.Errorf("%w: compact before %d of log of size %d", ErrInvalidIndex, before, l.size())
+	}
+	first := l.first()
+	if before < first {
+		return nil, fmt.Errorf("%w: entries before %d, requested before %d", ErrCompacted, first, before)
+	}
+	if before == 0 {
+		return nil, nil
+	}
+	frontier, err := l.tree.Frontier(before)
+	if err != nil {
+		return nil, err
+	}
+	head := l.compactedHead
+	if before > first {
+		if head, err = l.entryHash(before - 1); err != nil {
+			return nil, err
+		}
+	}
+	return &Compaction{Size: before, HeadHash: head, Frontier: frontier}, nil
+}
+
+// Compact drops the entries before index before from memory, and prunes the
+// log tree to their frontier. Roots, inclusion proofs for later entries and
+// consistency proofs from later sizes still work, while Get, Range and
+// proofs about the dropped entries return ErrCompacted. Entries that were
+// compacted already are skipped. A log with a store may read the last
+// dropped entry through it, so compact the log before the store.
+func (l *ConfigLog) Compact(before uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if before > l.size() {
+		return fmt.Errorf("%w: compact before %d of log of size %d", ErrInvalidIndex, before, l.size())
+	}
+	first := l.first()
+	if before <= first {
+		return nil
+	}
+	head, err := l.entryHash(before - 1)
+	if err != nil {
+		return err
+	}
+	if err := l.tree.Prune(before); err != nil {
+		return err
+	}
+	l.compactedHead = head
+	l.compacted = before
+	if before > l.cached {
+		// Copy the rest, so the dropped entries can be freed.
+		l.entries = append([]*vdcspb.ConfigEntry(nil), l.entries[before-l.cached:]...)
+		l.cached = before
+	}
+	return nil
+}
+
+// RestoreCompacted starts an empty log with compacted entries, known only by
+// what Compaction returned. The next entry restored or appended must follow
+// the last of them. Callers check the resulting root against one they trust.
+func (l *ConfigLog) RestoreCompacted(c *Compaction) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.size() != 0 {
+		return fmt.Errorf("%w: log of size %d is not empty", ErrInvalidIndex, l.size())
+	}
+	tree, err := merkle.RestoreLogTree(c.Size, c.Frontier)
+	if err != nil {
+		return err
+	}
+	l.tree = tree
+	l.compacted = c.Size
+	l.cached = c.Size
+	l.compactedHead = c.HeadHash
+	return nil
+}
+
+// proofError reports proofs about compacted entries as ErrCompacted.
+func proofError(err error) error {
+	if errors.Is(err, merkle.ErrPruned) {
+		return fmt.Errorf("%w: %v", ErrCompacted, err)
+	}
+	return err
+}
@@ -0,0 +1,206 @@
+package log
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+// EntryDomain is the domain tag at the start of the canonical entry encoding.
+// It separates entry hashes from every other hash in the system and versions
+// the encoding.
+const EntryDomain = "vdcs/entry/v1"
+
+// EncodeEntry returns the canonical encoding of the signed fields of an entry.
+// EntryHash is SHA-256 of this encoding, and the author signs EntryHash.
+//
+// The encoding is the concatenation of, in this order:
+//
+//	domain     bytes   EntryDomain
+//	index      uint64
+//	timestamp  int64   (two's complement)
+//	author_id  bytes   (UTF-8)
+//	key        bytes   (UTF-8)
+//	value_hash bytes
+//	operation  uint32  (enum number)
+//	prev_hash  bytes
+//
+// Integers are big-endian and fixed width. Every "bytes" field is prefixed
+// with its length as a big-endian uint32, so an empty field is encoded as four
+// zero bytes. EntryHash, Signature and Approvals are not encoded. Value is not encoded
+// either: it is bound to the entry through value_hash, and ConfigLog.Append
+// rejects entries whose Value does not hash to ValueHash.
+//
+// The fixed fields are followed by zero or more extensions in increasing tag
+// order, each encoded as a uint32 tag and a length-prefixed body. An
+// extension is only present when its field is set, so entries that do not use
+// it hash exactly as before the extension existed.
+//
+//	tag 1  mutations     uint32 count, then each mutation as a length-prefixed
+//	                     body: key bytes, value_hash bytes, operation uint32,
+//	                     followed by its precondition if it has one
+//	tag 2  precondition  the entry's precondition
+//	tag 3  intent        bytes: the intent hash (see EncodeIntent)
+//
+// A precondition is encoded as a uint32 kind followed by its operand:
+//
+//	kind 1  expected_value_hash      bytes
+//	kind 2  must_not_exist           (no operand)
+//	kind 3  expected_modified_index  uint64
+//
+// Test vectors for other implementations are in testdata/entry_vectors.json.
+func EncodeEntry(entry *vdcspb.ConfigEntry) ([]byte, error) {
+	e := &encoder{}
+	e.bytes([]byte(EntryDomain))
+	e.uint64(entry.Index)
+	e.uint64(uint64(entry.Timestamp))
+	e.bytes([]byte(entry.AuthorId))
+	e.bytes([]byte(entry.Key))
+	e.bytes(entry.ValueHash)
+	e.uint32(uint32(entry.Operation))
+	e.bytes(entry.PrevHash)
+
+	if len(entry.Mutations) > 0 {
+		e.extension(extMutations, func(b *encoder) {
+			b.uint32(uint32(len(entry.Mutations)))
+			for _, m := range entry.Mutations {
+				b.nested(func(mb *encoder) { encodeMutation(mb, m) })
+			}
+		})
+	}
+	if entry.Precondition != nil {
+		e.extension(extPrecondition, func(b *encoder) { encodePrecondition(b, entry.Precondition) })
+	}
+	if entry.Intent != nil {
+		intentHash, err := ComputeIntentHash(entry.Intent)
+		if err != nil {
+			return nil, err
+		}
+		e.extension(extIntent, func(b *encoder) { b.bytes(intentHash) })
+	}
+
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf, nil
+}
+
+// IntentDomain is the domain tag at the start of the canonical intent encoding.
+const IntentDomain = "vdcs/intent/v1"
+
+// EncodeIntent returns the canonical encoding of the signed fields of an
+// intent. The intent hash is SHA-256 of this encoding, and the author signs
+// the intent hash.
+//
+// The encoding uses the same primitives as EncodeEntry:
+//
+//	domain     bytes   IntentDomain
+//	author_id  bytes
+//	key        bytes
+//	operation  uint32
+//	value_hash bytes
+//	nonce      bytes
+//	expires_at int64
+//
+// followed by extension tag 2 (precondition) if the intent has one.
+// Signature, Value and Approvals are not encoded.
+func EncodeIntent(intent *vdcspb.Intent) ([]byte, error) {
+	e := &encoder{}
+	e.bytes([]byte(IntentDomain))
+	e.bytes([]byte(intent.AuthorId))
+	e.bytes([]byte(intent.Key))
+	e.uint32(uint32(intent.Operation))
+	e.bytes(intent.ValueHash)
+	e.bytes(intent.Nonce)
+	e.uint64(uint64(intent.ExpiresAt))
+
+	if intent.Precondition != nil {
+		e.extension(extPrecondition, func(b *encoder) { encodePrecondition(b, intent.Precondition) })
+	}
+
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf, nil
+}
+
+// Extension tags of the canonical entry encoding.
+const (
+	extMutations    uint32 = 1
+	extPrecondition uint32 = 2
+	extIntent       uint32 = 3
+)
+
+// Precondition kinds of the canonical entry encoding.
+const (
+	preconditionValueHash     uint32 = 1
+	preconditionNotExist      uint32 = 2
+	preconditionModifiedIndex uint32 = 3
+)
+
+func encodeMutation(e *encoder, m *vdcspb.Mutation) {
+	e.bytes([]byte(m.Key))
+	e.bytes(m.ValueHash)
+	e.uint32(uint32(m.Operation))
+	if m.Precondition != nil {
+		encodePrecondition(e, m.Precondition)
+	}
+}
+
+// encodePrecondition encodes p. A precondition without a condition is
+// encoded as kind 0; validateMutations rejects it.
+func encodePrecondition(e *encoder, p *vdcspb.Precondition) {
+	switch c := p.Condition.(type) {
+	case *vdcspb.Precondition_ExpectedValueHash:
+		e.uint32(preconditionValueHash)
+		e.bytes(c.ExpectedValueHash)
+	case *vdcspb.Precondition_MustNotExist:
+		e.uint32(preconditionNotExist)
+	case *vdcspb.Precondition_ExpectedModifiedIndex:
+		e.uint32(preconditionModifiedIndex)
+		e.uint64(c.ExpectedModifiedIndex)
+	default:
+		e.uint32(0)
+	}
+}
+
+// encoder appends fixed-width integers and length-prefixed byte strings.
+type encoder struct {
+	buf []byte
+	err error
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, v)
+}
+
+func (e *encoder) uint64(v uint64) {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, v)
+}
+
+func (e *encoder) bytes(b []byte) {
+	if len(b) > math.MaxUint32 {
+		e.err = fmt.Errorf("field too long to encode: %d bytes", len(b))
+		return
+	}
+	e.uint32(uint32(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// nested encodes a length-prefixed body built by fn.
+func (e *encoder) nested(fn func(*encoder)) {
+	body := &encoder{}
+	fn(body)
+	if body.err != nil && e.err == nil {
+		e.err = body.err
+	}
+	e.bytes(body.buf)
+}
+
+// extension encodes a tagged, length-prefixed extension built by fn.
+func (e *encoder) extension(tag uint32, fn func(*encoder)) {
+	e.uint32(tag)
+	e.nested(fn)
+}
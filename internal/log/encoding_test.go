@@ -0,0 +1,159 @@
+package log
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"testing"
+
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+// preconditionVector is a precondition in testdata/entry_vectors.json.
+// Exactly one field is set.
+type preconditionVector struct {
+	ExpectedValueHash     *string `json:"expected_value_hash,omitempty"`
+	MustNotExist          bool    `json:"must_not_exist,omitempty"`
+	ExpectedModifiedIndex *uint64 `json:"expected_modified_index,omitempty"`
+}
+
+func (p *preconditionVector) proto(t *testing.T) *vdcspb.Precondition {
+	switch {
+	case p == nil:
+		return nil
+	case p.ExpectedValueHash != nil:
+		return &vdcspb.Precondition{Condition: &vdcspb.Precondition_ExpectedValueHash{ExpectedValueHash: mustHex(t, *p.ExpectedValueHash)}}
+	case p.MustNotExist:
+		return &vdcspb.Precondition{Condition: &vdcspb.Precondition_MustNotExist{MustNotExist: true}}
+	case p.ExpectedModifiedIndex != nil:
+		return &vdcspb.Precondition{Condition: &vdcspb.Precondition_ExpectedModifiedIndex{ExpectedModifiedIndex: *p.ExpectedModifiedIndex}}
+	}
+	t.Fatal("empty precondition in test vector")
+	return nil
+}
+
+// entryVector mirrors one element of testdata/entry_vectors.json.
+type entryVector struct {
+	Name  string `json:"name"`
+	Entry struct {
+		Index     uint64 `json:"index"`
+		Timestamp int64  `json:"timestamp"`
+		AuthorID  string `json:"author_id"`
+		Key       string `json:"key"`
+		ValueHash string `json:"value_hash"`
+		Operation int32  `json:"operation"`
+		PrevHash  string `json:"prev_hash"`
+		Mutations []struct {
+			Key          string              `json:"key"`
+			ValueHash    string              `json:"value_hash"`
+			Operation    int32               `json:"operation"`
+			Precondition *preconditionVector `json:"precondition,omitempty"`
+		} `json:"mutations,omitempty"`
+		Precondition *preconditionVector `json:"precondition,omitempty"`
+		Intent       *struct {
+			AuthorID     string              `json:"author_id"`
+			Key          string              `json:"key"`
+			Operation    int32               `json:"operation"`
+			ValueHash    string              `json:"value_hash"`
+			Nonce        string              `json:"nonce"`
+			ExpiresAt    int64               `json:"expires_at"`
+			Precondition *preconditionVector `json:"precondition,omitempty"`
+		} `json:"intent,omitempty"`
+	} `json:"entry"`
+	// IntentEncoding and IntentHash are only set for sequenced entries.
+	IntentEncoding string `json:"intent_encoding,omitempty"`
+	IntentHash     string `json:"intent_hash,omitempty"`
+	Encoding       string `json:"encoding"`
+	EntryHash      string `json:"entry_hash"`
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestEntryHashVectors(t *testing.T) {
+	data, err := os.ReadFile("testdata/entry_vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []entryVector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) == 0 {
+		t.Fatal("no test vectors")
+	}
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			entry := &vdcspb.ConfigEntry{
+				Index:        v.Entry.Index,
+				Timestamp:    v.Entry.Timestamp,
+				AuthorId:     v.Entry.AuthorID,
+				Key:          v.Entry.Key,
+				ValueHash:    mustHex(t, v.Entry.ValueHash),
+				Operation:    vdcspb.Operation(v.Entry.Operation),
+				PrevHash:     mustHex(t, v.Entry.PrevHash),
+				Precondition: v.Entry.Precondition.proto(t),
+			}
+			for _, m := range v.Entry.Mutations {
+				entry.Mutations = append(entry.Mutations, &vdcspb.Mutation{
+					Key:          m.Key,
+					ValueHash:    mustHex(t, m.ValueHash),
+					Operation:    vdcspb.Operation(m.Operation),
+					Precondition: m.Precondition.proto(t),
+				})
+			}
+			if in := v.Entry.Intent; in != nil {
+				entry.Intent = &vdcspb.Intent{
+					AuthorId:     in.AuthorID,
+					Key:          in.Key,
+					Operation:    vdcspb.Operation(in.Operation),
+					ValueHash:    mustHex(t, in.ValueHash),
+					Nonce:        mustHex(t, in.Nonce),
+					ExpiresAt:    in.ExpiresAt,
+					Precondition: in.Precondition.proto(t),
+				}
+				intentEnc, err := EncodeIntent(entry.Intent)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := hex.EncodeToString(intentEnc); got != v.IntentEncoding {
+					t.Errorf("intent encoding mismatch:\n got  %s\n want %s", got, v.IntentEncoding)
+				}
+				if got, _ := ComputeIntentHash(entry.Intent); hex.EncodeToString(got) != v.IntentHash {
+					t.Errorf("intent hash mismatch: got %x, want %s", got, v.IntentHash)
+				}
+			}
+
+			enc, err := EncodeEntry(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(enc); got != v.Encoding {
+				t.Errorf("encoding mismatch:\n got  %s\n want %s", got, v.Encoding)
+			}
+
+			hash, err := ComputeEntryHash(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(hash); got != v.EntryHash {
+				t.Errorf("entry hash mismatch: got %s, want %s", got, v.EntryHash)
+			}
+
+			// Transport-only fields must not change the hash.
+			entry.Value = []byte("inline value")
+			entry.Signature = []byte("signature")
+			entry.EntryHash = hash
+			if again, _ := ComputeEntryHash(entry); hex.EncodeToString(again) != v.EntryHash {
+				t.Error("entry hash depends on Value, Signature or EntryHash")
+			}
+		})
+	}
+}
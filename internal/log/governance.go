@@ -0,0 +1,154 @@
+package log
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+	"strings"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	"github.com/rrb115/vdcs/internal/policy"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+// Governance entries manage trusted authors and the write policy through the
+// log. Their keys live in a reserved namespace that SET and DELETE cannot
+// touch.
+const (
+	GovernancePrefix = "_vdcs/"
+	AuthorKeyPrefix  = GovernancePrefix + "authors/"
+	PolicyKey        = GovernancePrefix + "policy"
+)
+
+// AuthorKey returns the governance key of an author.
+func AuthorKey(authorID string) string {
+	return AuthorKeyPrefix + authorID
+}
+
+// IsGovernance reports whether op is a governance operation.
+func IsGovernance(op vdcspb.Operation) bool {
+	switch op {
+	case vdcspb.Operation_OPERATION_ADD_AUTHOR,
+		vdcspb.Operation_OPERATION_REVOKE_AUTHOR,
+		vdcspb.Operation_OPERATION_ROTATE_KEY,
+		vdcspb.Operation_OPERATION_UPDATE_POLICY:
+		return true
+	}
+	return false
+}
+
+// validateGovernance checks a governance entry against the current author
+// set. Callers must hold l.mu.
+func (l *ConfigLog) validateGovernance(entry *vdcspb.ConfigEntry) error {
+	if entry.Operation == vdcspb.Operation_OPERATION_UPDATE_POLICY {
+		if entry.Key != PolicyKey {
+			return fmt.Errorf("%w: policy update must use key %s", ErrInvalidGovernance, PolicyKey)
+		}
+		if _, err := policy.Parse(entry.Value); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidGovernance, err)
+		}
+		return nil
+	}
+
+	authorID, ok := strings.CutPrefix(entry.Key, AuthorKeyPrefix)
+	if !ok || authorID == "" {
+		return fmt.Errorf("%w: %s needs a key of the form %s<id>", ErrInvalidGovernance, entry.Operation, AuthorKeyPrefix)
+	}
+	_, trusted := l.trustedKeys[authorID]
+
+	switch entry.Operation {
+	case vdcspb.Operation_OPERATION_ADD_AUTHOR:
+		if trusted {
+			return fmt.Errorf("%w: author %s already exists", ErrInvalidGovernance, authorID)
+		}
+		if _, ok := l.sequencers[authorID]; ok {
+			return fmt.Errorf("%w: %s is a sequencer", ErrInvalidGovernance, authorID)
+		}
+	case vdcspb.Operation_OPERATION_REVOKE_AUTHOR, vdcspb.Operation_OPERATION_ROTATE_KEY:
+		if !trusted {
+			return fmt.Errorf("%w: author %s does not exist", ErrInvalidGovernance, authorID)
+		}
+	}
+
+	if entry.Operation == vdcspb.Operation_OPERATION_REVOKE_AUTHOR {
+		if len(entry.ValueHash) > 0 || len(entry.Value) > 0 {
+			return fmt.Errorf("%w: revocation of %s carries a value", ErrInvalidGovernance, authorID)
+		}
+		return nil
+	}
+	// The Log needs the key itself, not only its hash. validateMutations has
+	// already bound Value to ValueHash.
+	if len(entry.Value) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: public key of %s is %d bytes", ErrInvalidGovernance, authorID, len(entry.Value))
+	}
+
+	// Every key has one author ID, and a fingerprint ID stays bound to the
+	// key it was derived from.
+	if strings.HasPrefix(authorID, crypto.FingerprintPrefix) {
+		if entry.Operation == vdcspb.Operation_OPERATION_ROTATE_KEY {
+			return fmt.Errorf("%w: %s is a key fingerprint and cannot be rotated", ErrInvalidGovernance, authorID)
+		}
+		if authorID != crypto.Fingerprint(entry.Value) {
+			return fmt.Errorf("%w: %s is not the fingerprint of its key", ErrInvalidGovernance, authorID)
+		}
+	}
+	for other, cfg := range l.authorConfig {
+		if other != authorID && bytes.Equal(cfg.PublicKey, entry.Value) {
+			return fmt.Errorf("%w: key of %s already belongs to %s", ErrInvalidGovernance, authorID, other)
+		}
+	}
+	return nil
+}
+
+// checkGovernanceAuthor denies a governance entry by an author other than
+// the genesis trust anchors if there is no policy, so that the permissive
+// default for data keys does not let any trusted author take over the
+// author set. A policy decides governance like any other write, and denies
+// it unless a rule allows it. Callers must hold l.mu.
+func (l *ConfigLog) checkGovernanceAuthor(author string, entry *vdcspb.ConfigEntry) error {
+	if l.policy != nil {
+		return nil
+	}
+	if _, ok := l.anchors[author]; !ok {
+		return &policy.DeniedError{Author: author, Key: entry.Key, Operation: entry.Operation}
+	}
+	return nil
+}
+
+// applyGovernance updates the author set or policy after a governance entry
+// was appended. Callers must hold l.mu.
+func (l *ConfigLog) applyGovernance(entry *vdcspb.ConfigEntry) {
+	authorID := strings.TrimPrefix(entry.Key, AuthorKeyPrefix)
+	switch entry.Operation {
+	case vdcspb.Operation_OPERATION_ADD_AUTHOR, vdcspb.Operation_OPERATION_ROTATE_KEY:
+		l.trustedKeys[authorID] = struct{}{}
+		l.authorConfig[authorID] = AuthorConfig{PublicKey: append([]byte(nil), entry.Value...)}
+	case vdcspb.Operation_OPERATION_REVOKE_AUTHOR:
+		delete(l.trustedKeys, authorID)
+		delete(l.authorConfig, authorID)
+	case vdcspb.Operation_OPERATION_UPDATE_POLICY:
+		// validateGovernance has parsed it once already.
+		p, _ := policy.Parse(entry.Value)
+		l.policy = p
+	}
+}
+
+// Authors returns the trusted authors and their public keys as of the last
+// appended entry.
+func (l *ConfigLog) Authors() map[string][]byte {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	authors := make(map[string][]byte, len(l.authorConfig))
+	for id, cfg := range l.authorConfig {
+		authors[id] = cfg.PublicKey
+	}
+	return authors
+}
+
+// Policy returns the write policy as of the last appended entry, or nil if
+// there is none.
+func (l *ConfigLog) Policy() *policy.Policy {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.policy
+}
@@ -0,0 +1,141 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	"github.com/rrb115/vdcs/internal/policy"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+func TestLogGovernance(t *testing.T) {
+	adminPub, adminPriv, _ := crypto.GenerateKey()
+	bobPub, bobPriv, _ := crypto.GenerateKey()
+	bobPub2, bobPriv2, _ := crypto.GenerateKey()
+
+	genesis := map[string][]byte{"admin": adminPub}
+	l := newLog(genesis)
+
+	var appended []*vdcspb.ConfigEntry
+	var authorsAt []map[string][]byte
+	entry := func(author, key string, op vdcspb.Operation, value []byte) *vdcspb.ConfigEntry {
+		e := next(l, &vdcspb.ConfigEntry{
+			Timestamp: 100,
+			AuthorId:  author,
+			Key:       key,
+			Operation: op,
+			Value:     value,
+		})
+		if len(value) > 0 {
+			h := crypto.Hash(value)
+			e.ValueHash = h[:]
+		}
+		return e
+	}
+	propose := func(e *vdcspb.ConfigEntry, priv []byte) error {
+		if err := l.Append(sign(t, e, priv)); err != nil {
+			return err
+		}
+		appended = append(appended, e)
+		authorsAt = append(authorsAt, l.Authors())
+		return nil
+	}
+	mustPropose := func(e *vdcspb.ConfigEntry, priv []byte) {
+		t.Helper()
+		if err := propose(e, priv); err != nil {
+			t.Fatalf("append %s %s failed: %v", e.Operation, e.Key, err)
+		}
+	}
+	set := vdcspb.Operation_OPERATION_SET
+
+	// 1. Adding an author lets them write
+	if err := propose(entry("bob", "k", set, []byte("v")), bobPriv); err == nil {
+		t.Fatal("untrusted author appended an entry")
+	}
+	mustPropose(entry("admin", AuthorKey("bob"), vdcspb.Operation_OPERATION_ADD_AUTHOR, bobPub), adminPriv)
+	mustPropose(entry("bob", "k", set, []byte("v")), bobPriv)
+
+	// 2. Malformed governance entries and reserved keys
+	invalid := []struct {
+		entry *vdcspb.ConfigEntry
+		err   error
+	}{
+		{entry("admin", AuthorKey("bob"), vdcspb.Operation_OPERATION_ADD_AUTHOR, bobPub2), ErrInvalidGovernance},
+		{entry("admin", AuthorKey("carol"), vdcspb.Operation_OPERATION_ADD_AUTHOR, []byte("short")), ErrInvalidGovernance},
+		{entry("admin", "bob", vdcspb.Operation_OPERATION_ADD_AUTHOR, bobPub2), ErrInvalidGovernance},
+		{entry("admin", AuthorKey("carol"), vdcspb.Operation_OPERATION_REVOKE_AUTHOR, nil), ErrInvalidGovernance},
+		{entry("admin", PolicyKey, vdcspb.Operation_OPERATION_UPDATE_POLICY, []byte(`{"rules": [{}]}`)), ErrInvalidGovernance},
+		{entry("admin", AuthorKey("carol"), set, bobPub2), ErrInvalidMutation},
+		// Author IDs stay bound to their keys
+		{entry("admin", AuthorKey("carol"), vdcspb.Operation_OPERATION_ADD_AUTHOR, bobPub), ErrInvalidGovernance},
+		{entry("admin", AuthorKey(crypto.Fingerprint(bobPub)), vdcspb.Operation_OPERATION_ADD_AUTHOR, bobPub2), ErrInvalidGovernance},
+	}
+	for _, tt := range invalid {
+		if err := propose(tt.entry, adminPriv); !errors.Is(err, tt.err) {
+			t.Errorf("%s %s: expected %v, got %v", tt.entry.Operation, tt.entry.Key, tt.err, err)
+		}
+	}
+
+	// 3. Rotation invalidates the old key
+	mustPropose(entry("admin", AuthorKey("bob"), vdcspb.Operation_OPERATION_ROTATE_KEY, bobPub2), adminPriv)
+	if err := propose(entry("bob", "k", set, []byte("v2")), bobPriv); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for the old key, got %v", err)
+	}
+	mustPropose(entry("bob", "k", set, []byte("v2")), bobPriv2)
+
+	// Fingerprint IDs are bound to their key and cannot be rotated
+	carolPub, _, _ := crypto.GenerateKey()
+	carolID := crypto.Fingerprint(carolPub)
+	mustPropose(entry("admin", AuthorKey(carolID), vdcspb.Operation_OPERATION_ADD_AUTHOR, carolPub), adminPriv)
+	if err := propose(entry("admin", AuthorKey(carolID), vdcspb.Operation_OPERATION_ROTATE_KEY, bobPub), adminPriv); !errors.Is(err, ErrInvalidGovernance) {
+		t.Errorf("expected ErrInvalidGovernance for rotating %s, got %v", carolID, err)
+	}
+
+	// Without a policy, only the genesis anchors may sign governance entries
+	if err := propose(entry("bob", AuthorKey("admin"), vdcspb.Operation_OPERATION_REVOKE_AUTHOR, nil), bobPriv2); !errors.Is(err, policy.ErrDenied) {
+		t.Errorf("expected policy denial for bob revoking admin, got %v", err)
+	}
+	if err := propose(entry("bob", AuthorKey("admin"), vdcspb.Operation_OPERATION_ROTATE_KEY, bobPub), bobPriv2); !errors.Is(err, policy.ErrDenied) {
+		t.Errorf("expected policy denial for bob rotating admin, got %v", err)
+	}
+
+	// 4. Policy updates apply from the next entry on
+	mustPropose(entry("admin", PolicyKey, vdcspb.Operation_OPERATION_UPDATE_POLICY, []byte(`{"rules": [
+		{"name": "governance", "effect": "allow", "authors": ["admin"], "prefixes": ["_vdcs/"]},
+		{"name": "bob-k", "effect": "allow", "authors": ["bob"], "prefixes": ["k"]}
+	]}`)), adminPriv)
+	if err := propose(entry("admin", "k", set, []byte("v3")), adminPriv); !errors.Is(err, policy.ErrDenied) {
+		t.Fatalf("expected policy denial, got %v", err)
+	}
+
+	// 5. Revocation
+	mustPropose(entry("admin", AuthorKey("bob"), vdcspb.Operation_OPERATION_REVOKE_AUTHOR, nil), adminPriv)
+	if err := propose(entry("bob", "k", set, []byte("v3")), bobPriv2); err == nil {
+		t.Fatal("revoked author appended an entry")
+	}
+	if _, ok := l.Authors()["bob"]; ok {
+		t.Error("bob is still trusted after revocation")
+	}
+
+	// 6. Replaying from the same genesis yields the same authors at every index
+	replayed := newLog(genesis)
+	for i, e := range appended {
+		if err := replayed.Append(e); err != nil {
+			t.Fatalf("replay failed at index %d: %v", i, err)
+		}
+		got := replayed.Authors()
+		if len(got) != len(authorsAt[i]) {
+			t.Fatalf("index %d: replay has %d authors, expected %d", i, len(got), len(authorsAt[i]))
+		}
+		for id, key := range authorsAt[i] {
+			if !bytes.Equal(got[id], key) {
+				t.Errorf("index %d: author %s has key %x after replay, expected %x", i, id, got[id], key)
+			}
+		}
+	}
+	if replayed.Policy() == nil {
+		t.Error("policy update was not replayed")
+	}
+}
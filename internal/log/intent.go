@@ -0,0 +1,96 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	vdcspb "github.com/rrb115/vdcs/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// MaxNonceSize is the maximum length of an intent nonce.
+const MaxNonceSize = 64
+
+// ComputeIntentHash calculates the SHA-256 hash of the canonical encoding of
+// the intent (see EncodeIntent). Signature and Value are excluded.
+func ComputeIntentHash(intent *vdcspb.Intent) ([]byte, error) {
+	data, err := EncodeIntent(intent)
+	if err != nil {
+		return nil, err
+	}
+	h := crypto.Hash(data)
+	return h[:], nil
+}
+
+// VerifyIntent checks the author's signature on an intent.
+func VerifyIntent(intent *vdcspb.Intent, pubKey []byte) error {
+	hash, err := ComputeIntentHash(intent)
+	if err != nil {
+		return err
+	}
+	if !crypto.Verify(pubKey, hash, intent.Signature) {
+		return fmt.Errorf("%w: intent by %s", ErrInvalidSignature, intent.AuthorId)
+	}
+	return nil
+}
+
+// validateIntent checks the intent wrapped in a sequenced entry.
+// Callers must hold l.mu.
+func (l *ConfigLog) validateIntent(entry *vdcspb.ConfigEntry) error {
+	intent := entry.Intent
+
+	// 1. Author Signature
+	if _, ok := l.trustedKeys[intent.AuthorId]; !ok {
+		return fmt.Errorf("author %s not trusted", intent.AuthorId)
+	}
+	if err := VerifyIntent(intent, l.authorConfig[intent.AuthorId].PublicKey); err != nil {
+		return err
+	}
+
+	// 2. The entry must make exactly the change the author signed.
+	if intent.Operation != vdcspb.Operation_OPERATION_SET && intent.Operation != vdcspb.Operation_OPERATION_DELETE {
+		return fmt.Errorf("%w: unsupported operation %s", ErrInvalidIntent, intent.Operation)
+	}
+	if entry.Key != intent.Key ||
+		entry.Operation != intent.Operation ||
+		!bytes.Equal(entry.ValueHash, intent.ValueHash) ||
+		!bytes.Equal(entry.Value, intent.Value) ||
+		!proto.Equal(entry.Precondition, intent.Precondition) {
+		return fmt.Errorf("%w: entry does not match intent", ErrInvalidIntent)
+	}
+
+	// 3. Nonce
+	if len(intent.Nonce) == 0 || len(intent.Nonce) > MaxNonceSize {
+		return fmt.Errorf("%w: nonce must be 1 to %d bytes", ErrInvalidIntent, MaxNonceSize)
+	}
+	if _, used := l.nonces[nonceKey(intent)]; used {
+		return fmt.Errorf("%w: nonce %x of %s", ErrNonceReused, intent.Nonce, intent.AuthorId)
+	}
+
+	// 4. Expiry
+	// Measured against the latest timestamp in the log, not just this
+	// entry's, so that forgetting expired nonces cannot re-admit an intent.
+	if intent.ExpiresAt < max(l.latestTimestamp, entry.Timestamp) {
+		return fmt.Errorf("%w: intent by %s expired at %d", ErrIntentExpired, intent.AuthorId, intent.ExpiresAt)
+	}
+	return nil
+}
+
+// recordTimestamp advances the latest timestamp and forgets the nonces of
+// intents that can no longer be sequenced. Callers must hold l.mu.
+func (l *ConfigLog) recordTimestamp(timestamp int64) {
+	if timestamp <= l.latestTimestamp {
+		return
+	}
+	l.latestTimestamp = timestamp
+	for k, expiresAt := range l.nonces {
+		if expiresAt < timestamp {
+			delete(l.nonces, k)
+		}
+	}
+}
+
+func nonceKey(intent *vdcspb.Intent) string {
+	return intent.AuthorId + "\x00" + string(intent.Nonce)
+}
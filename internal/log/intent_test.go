@@ -0,0 +1,108 @@
+package log
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+func TestLogSequencedIntent(t *testing.T) {
+	authorPub, authorPriv, _ := crypto.GenerateKey()
+	seqPub, seqPriv, _ := crypto.GenerateKey()
+	l := newLog(map[string][]byte{"author1": authorPub})
+	l.AddSequencer("node", seqPub)
+
+	valueHash := crypto.Hash([]byte("value1"))
+	newIntent := func(nonce string) *vdcspb.Intent {
+		intent := &vdcspb.Intent{
+			AuthorId:  "author1",
+			Key:       "k",
+			Operation: vdcspb.Operation_OPERATION_SET,
+			ValueHash: valueHash[:],
+			Nonce:     []byte(nonce),
+			ExpiresAt: 1000,
+			Value:     []byte("value1"),
+		}
+		signIntent(t, intent, authorPriv)
+		return intent
+	}
+	// sequence wraps an intent the way the node does.
+	sequence := func(intent *vdcspb.Intent, timestamp int64) *vdcspb.ConfigEntry {
+		return sign(t, next(l, &vdcspb.ConfigEntry{
+			Timestamp: timestamp,
+			AuthorId:  "node",
+			Key:       intent.Key,
+			ValueHash: intent.ValueHash,
+			Operation: intent.Operation,
+			Value:     intent.Value,
+			Intent:    intent,
+		}), seqPriv)
+	}
+
+	// 1. Valid sequenced entry
+	intent := newIntent("n1")
+	if err := l.Append(sequence(intent, 100)); err != nil {
+		t.Fatalf("failed to append sequenced entry: %v", err)
+	}
+	if err := VerifyIntent(intent, authorPub); err != nil {
+		t.Errorf("intent signature did not verify: %v", err)
+	}
+
+	// 2. Replayed nonce
+	if err := l.Append(sequence(newIntent("n1"), 200)); !errors.Is(err, ErrNonceReused) {
+		t.Errorf("expected ErrNonceReused, got %v", err)
+	}
+
+	// 3. Expired intent
+	if err := l.Append(sequence(newIntent("n2"), 1001)); !errors.Is(err, ErrIntentExpired) {
+		t.Errorf("expected ErrIntentExpired, got %v", err)
+	}
+
+	// 4. Entry does not match the intent
+	mismatch := newIntent("n3")
+	entry := sequence(mismatch, 300)
+	entry.Key = "other"
+	if err := l.Append(sign(t, entry, seqPriv)); !errors.Is(err, ErrInvalidIntent) {
+		t.Errorf("expected ErrInvalidIntent for mismatch, got %v", err)
+	}
+
+	// 5. Intent changed after the author signed it
+	tampered := newIntent("n4")
+	tampered.ExpiresAt = 5000
+	if err := l.Append(sequence(tampered, 300)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	// 6. Only sequencers may wrap intents, and they may not author entries
+	entry = sequence(newIntent("n5"), 300)
+	entry.AuthorId = "author1"
+	if err := l.Append(sign(t, entry, authorPriv)); !errors.Is(err, ErrInvalidIntent) {
+		t.Errorf("expected ErrInvalidIntent for author as sequencer, got %v", err)
+	}
+	entry = sequence(newIntent("n6"), 300)
+	entry.Intent = nil
+	if err := l.Append(sign(t, entry, seqPriv)); err == nil {
+		t.Error("expected error for sequencer authoring an entry")
+	}
+
+	// 7. An intent expiring before the latest timestamp in the log cannot be
+	// sequenced, not even by backdating the new entry.
+	if err := l.Append(sequence(newIntent("n7"), 900)); err != nil {
+		t.Fatal(err)
+	}
+	late := newIntent("n8")
+	late.ExpiresAt = 800
+	signIntent(t, late, authorPriv)
+	if err := l.Append(sequence(late, 700)); !errors.Is(err, ErrTimestampDecreased) {
+		t.Errorf("expected ErrTimestampDecreased for a backdated entry, got %v", err)
+	}
+	if err := l.Append(sequence(late, 900)); !errors.Is(err, ErrIntentExpired) {
+		t.Errorf("expected ErrIntentExpired against latest timestamp, got %v", err)
+	}
+
+	if l.Size() != 2 {
+		t.Errorf("expected 2 entries, got %d", l.Size())
+	}
+}
@@ -8,53 +8,136 @@ import (
 	"time"
 
 	"github.com/rrb115/vdcs/internal/crypto"
+	"github.com/rrb115/vdcs/internal/merkle"
+	"github.com/rrb115/vdcs/internal/policy"
 	vdcspb "github.com/rrb115/vdcs/proto"
-	"google.golang.org/protobuf/proto"
 )
 
 var (
-	ErrInvalidIndex     = errors.New("invalid index")
-	ErrInvalidPrevHash  = errors.New("invalid previous hash")
-	ErrInvalidSignature = errors.New("invalid signature")
-	ErrInvalidHash      = errors.New("invalid entry hash")
+	ErrInvalidIndex       = errors.New("invalid index")
+	ErrCompacted          = errors.New("entry compacted")
+	ErrInvalidPrevHash    = errors.New("invalid previous hash")
+	ErrTimestampDecreased = errors.New("timestamp decreased")
+	ErrTimestampSkew      = errors.New("timestamp too far from clock")
+	ErrInvalidSignature   = errors.New("invalid signature")
+	ErrInvalidHash        = errors.New("invalid entry hash")
+	ErrValueMismatch      = errors.New("value does not match value hash")
+	ErrInvalidMutation    = errors.New("invalid mutation")
+	ErrInvalidIntent      = errors.New("invalid intent")
+	ErrIntentExpired      = errors.New("intent expired")
+	ErrNonceReused        = errors.New("intent nonce already used")
+	ErrInvalidGovernance  = errors.New("invalid governance entry")
+	ErrInvalidApproval    = errors.New("invalid approval")
 )
 
 // ConfigLog represents the append-only log of configuration changes.
 type ConfigLog struct {
-	mu           sync.RWMutex
-	entries      []*vdcspb.ConfigEntry
-	trustedKeys  map[string]struct{}     // Set of trusted AuthorIDs
-	authorConfig map[string]AuthorConfig // Map AuthorID -> Public Key
+	mu            sync.RWMutex
+	compacted     uint64                  // Number of compacted entries, the first index
+	compactedHead []byte                  // EntryHash of the last compacted entry
+	cached        uint64                  // Index of entries[0]
+	entries       []*vdcspb.ConfigEntry   // Entries [cached, size), at most 2*tail with a store
+	store         EntryReader             // Reads entries before cached; nil keeps all in memory
+	tail          int                     // Entries kept in memory with a store
+	tree          *merkle.LogTree         // Merkle tree over EntryHashes, pruned up to first
+	trustedKeys   map[string]struct{}     // Set of trusted AuthorIDs
+	authorConfig  map[string]AuthorConfig // Map AuthorID -> Public Key
+	anchors       map[string]struct{}     // Genesis AuthorIDs, added with AddTrustedAuthor
+	sequencers    map[string][]byte       // Map SequencerID -> Public Key
+	policy        *policy.Policy          // Nil allows trusted authors to write any data key
+	timestamps    TimestampRules
+
+	nonces          map[string]int64 // Unexpired intent nonces -> ExpiresAt
+	latestTimestamp int64            // Latest entry timestamp
 }
 
 type AuthorConfig struct {
 	PublicKey []byte
 }
 
+// EntryReader reads committed entries back, e.g. from a storage.Store.
+type EntryReader interface {
+	Get(index uint64) (*vdcspb.ConfigEntry, error)
+	ReadRange(start, end uint64) ([]*vdcspb.ConfigEntry, error)
+}
+
+// DefaultTailSize is how many of the latest entries a log with a store keeps
+// in memory.
+const DefaultTailSize = 1024
+
 // NewConfigLog creates a new empty log.
 func NewConfigLog() *ConfigLog {
 	return &ConfigLog{
 		entries:      make([]*vdcspb.ConfigEntry, 0),
+		tree:         merkle.NewLogTree(),
 		trustedKeys:  make(map[string]struct{}),
 		authorConfig: make(map[string]AuthorConfig),
+		anchors:      make(map[string]struct{}),
+		sequencers:   make(map[string][]byte),
+		nonces:       make(map[string]int64),
+		timestamps:   TimestampRules{Now: time.Now},
+		tail:         DefaultTailSize,
 	}
 }
 
-// AddTrustedAuthor adds a trusted author to the log.
+// SetStore makes the log keep only about the latest DefaultTailSize entries
+// in memory, and read older ones through r. r must hold every entry the log
+// drops from memory, i.e. all but the latest one, until they are compacted.
+func (l *ConfigLog) SetStore(r EntryReader) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.store = r
+	l.trim()
+}
+
+// AddTrustedAuthor adds a trusted author to the log. Authors added this way
+// are the genesis trust anchors: without a policy, only they may append
+// governance entries.
 func (l *ConfigLog) AddTrustedAuthor(authorID string, pubKey []byte) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	l.trustedKeys[authorID] = struct{}{}
+	l.anchors[authorID] = struct{}{}
 	l.authorConfig[authorID] = AuthorConfig{PublicKey: pubKey}
 }
 
+// AddSequencer allows the holder of pubKey to append sequenced entries, which
+// wrap an intent signed by a trusted author, under sequencerID.
+func (l *ConfigLog) AddSequencer(sequencerID string, pubKey []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sequencers[sequencerID] = pubKey
+}
+
+// SetPolicy restricts which keys and operations each author may write. Only
+// entries appended afterwards are checked, so set the policy before replaying
+// a log. A nil policy allows every data write, and governance entries by the
+// genesis trust anchors.
+func (l *ConfigLog) SetPolicy(p *policy.Policy) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.policy = p
+}
+
 // Append validates and adds a new entry to the log.
 func (l *ConfigLog) Append(entry *vdcspb.ConfigEntry) error {
+	return l.append(entry, true)
+}
+
+// Replay validates and adds an entry that was appended before, e.g. one
+// read back from storage. It checks everything Append checks except the
+// clock skew of the timestamp.
+func (l *ConfigLog) Replay(entry *vdcspb.ConfigEntry) error {
+	return l.append(entry, false)
+}
+
+// append adds an entry; live entries are new and checked against the clock.
+func (l *ConfigLog) append(entry *vdcspb.ConfigEntry, live bool) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
 	// 1. Validate Basic Fields
-	nextIndex := uint64(len(l.entries)) // 0-indexed log storage, but entries usually 1-indexed?
+	nextIndex := l.size() // 0-indexed log storage, but entries usually 1-indexed?
 	// Let's assume 0-indexed for simplicity or match spec.
 	// Spec says "Index uint64".
 	// If first entry is 0:
@@ -69,19 +152,19 @@ func (l *ConfigLog) Append(entry *vdcspb.ConfigEntry) error {
 			return fmt.Errorf("%w: genesis prevHash must be empty", ErrInvalidPrevHash)
 		}
 	} else {
-		lastEntry := l.entries[nextIndex-1]
 		// We need the hash of the last entry.
 		// ideally entry.PrevHash == Hash(lastEntry without signature? or with?)
 		// Spec says: "EntryHash [32]byte ... Signature covers EntryHash".
 		// So PrevHash should point to the previous entry's EntryHash.
-		if !bytes.Equal(entry.PrevHash, lastEntry.EntryHash) {
+		if !bytes.Equal(entry.PrevHash, l.lastHash()) {
 			return fmt.Errorf("%w: mismatch", ErrInvalidPrevHash)
 		}
 	}
 
-	// 3. Validate Timestamp (strictly monotonic? or just plausible?)
-	// For now, let's just ignore strict timestamp checks unless required.
-	_ = time.Unix(0, entry.Timestamp)
+	// 3. Validate Timestamp
+	if err := l.validateTimestamp(entry.Timestamp, live); err != nil {
+		return err
+	}
 
 	// 4. Validate EntryHash
 	// Recompute hash.
@@ -93,56 +176,228 @@ func (l *ConfigLog) Append(entry *vdcspb.ConfigEntry) error {
 		return fmt.Errorf("%w: computed %x != provided %x", ErrInvalidHash, computedHash, entry.EntryHash)
 	}
 
-	// 5. Validate Signature
-	if _, ok := l.trustedKeys[entry.AuthorId]; !ok {
-		return fmt.Errorf("author %s not trusted", entry.AuthorId)
+	// 5. Validate Mutations (and inline values, which EntryHash does not cover)
+	if err := validateMutations(entry); err != nil {
+		return err
+	}
+
+	// 6. Validate Signature
+	// Sequenced entries are signed by a sequencer, all others by an author.
+	var pubKey []byte
+	if entry.Intent != nil {
+		key, ok := l.sequencers[entry.AuthorId]
+		if !ok {
+			return fmt.Errorf("%w: %s is not a sequencer", ErrInvalidIntent, entry.AuthorId)
+		}
+		pubKey = key
+	} else {
+		if _, ok := l.trustedKeys[entry.AuthorId]; !ok {
+			return fmt.Errorf("author %s not trusted", entry.AuthorId)
+		}
+		pubKey = l.authorConfig[entry.AuthorId].PublicKey
 	}
-	pubKey := l.authorConfig[entry.AuthorId].PublicKey
 	if !crypto.Verify(pubKey, entry.EntryHash, entry.Signature) {
 		return ErrInvalidSignature
 	}
 
-	// 6. Commit
-	l.entries = append(l.entries, entry)
+	// 7. Validate Intent (author signature, nonce and expiry)
+	if entry.Intent != nil {
+		if err := l.validateIntent(entry); err != nil {
+			return err
+		}
+	}
+
+	// 8. Validate Governance (against the authors before this entry)
+	if IsGovernance(entry.Operation) {
+		if err := l.validateGovernance(entry); err != nil {
+			return err
+		}
+	}
+
+	// 9. Verify Approvals
+	// signers[0] is the author; sequenced entries are checked against the
+	// intent's author, not the sequencer.
+	signers, err := l.signers(entry)
+	if err != nil {
+		return err
+	}
+
+	// 10. Authorize every Mutation, and check it has enough signers
+	if IsGovernance(entry.Operation) {
+		if err := l.checkGovernanceAuthor(signers[0], entry); err != nil {
+			return err
+		}
+	}
+	for _, m := range Mutations(entry) {
+		if err := l.policy.Check(signers[0], m.Key, m.Operation); err != nil {
+			return err
+		}
+		if err := l.policy.CheckApprovals(m.Key, m.Operation, signers); err != nil {
+			return err
+		}
+	}
+
+	// 11. Commit
+	l.push(entry)
+	if IsGovernance(entry.Operation) {
+		l.applyGovernance(entry)
+	}
+	if entry.Intent != nil {
+		l.nonces[nonceKey(entry.Intent)] = entry.Intent.ExpiresAt
+	}
+	l.recordTimestamp(entry.Timestamp)
 	return nil
 }
 
-// Get returns the entry at the given index.
+// Get returns the entry at the given index, or an error wrapping
+// ErrCompacted if it was compacted.
 func (l *ConfigLog) Get(index uint64) (*vdcspb.ConfigEntry, error) {
 	l.mu.RLock()
-	defer l.mu.RUnlock()
-
-	if index >= uint64(len(l.entries)) {
+	if index >= l.size() {
+		l.mu.RUnlock()
 		return nil, ErrInvalidIndex
 	}
-	return l.entries[index], nil
+	if first := l.first(); index < first {
+		l.mu.RUnlock()
+		return nil, fmt.Errorf("%w: entry %d, the first available is %d", ErrCompacted, index, first)
+	}
+	if index >= l.cached {
+		defer l.mu.RUnlock()
+		return l.entries[index-l.cached], nil
+	}
+	l.mu.RUnlock()
+	return l.read(index)
+}
+
+// Range returns the entries with indexes in [start, end), or an error
+// wrapping ErrCompacted if some were compacted.
+func (l *ConfigLog) Range(start, end uint64) ([]*vdcspb.ConfigEntry, error) {
+	l.mu.RLock()
+	if start > end || end > l.size() {
+		l.mu.RUnlock()
+		return nil, fmt.Errorf("%w: range [%d, %d) of log of size %d", ErrInvalidIndex, start, end, l.size())
+	}
+	first := l.first()
+	if start < first {
+		l.mu.RUnlock()
+		return nil, fmt.Errorf("%w: entries before %d, requested from %d", ErrCompacted, first, start)
+	}
+	// Copy the entries in memory, then read the older ones without the lock.
+	mid := min(max(start, l.cached), end)
+	cached := make([]*vdcspb.ConfigEntry, end-mid)
+	copy(cached, l.entries[mid-l.cached:end-l.cached])
+	l.mu.RUnlock()
+
+	if start == mid {
+		return cached, nil
+	}
+	entries, err := l.store.ReadRange(start, mid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read entries [%d, %d): %w", start, mid, err)
+	}
+	return append(entries, cached...), nil
+}
+
+// read reads an entry older than the ones in memory through the store.
+func (l *ConfigLog) read(index uint64) (*vdcspb.ConfigEntry, error) {
+	entry, err := l.store.Get(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read entry %d: %w", index, err)
+	}
+	return entry, nil
+}
+
+// entryHash returns the EntryHash of the entry at index, which must be in
+// [first, size). Callers must hold l.mu.
+func (l *ConfigLog) entryHash(index uint64) ([]byte, error) {
+	if index >= l.cached {
+		return l.entries[index-l.cached].EntryHash, nil
+	}
+	entry, err := l.read(index)
+	if err != nil {
+		return nil, err
+	}
+	return entry.EntryHash, nil
+}
+
+// push adds a validated entry, then trims the entries in memory. Callers
+// must hold l.mu.
+func (l *ConfigLog) push(entry *vdcspb.ConfigEntry) {
+	l.entries = append(l.entries, entry)
+	l.tree.Append(entry.EntryHash)
+	if len(l.entries) >= 2*l.tail {
+		l.trim()
+	}
+}
+
+// trim drops all but the latest tail entries from memory if the log has a
+// store. Callers must hold l.mu.
+func (l *ConfigLog) trim() {
+	if l.store == nil || len(l.entries) <= l.tail {
+		return
+	}
+	drop := len(l.entries) - l.tail
+	l.cached += uint64(drop)
+	// Copy the rest, so the dropped entries can be freed.
+	l.entries = append([]*vdcspb.ConfigEntry(nil), l.entries[drop:]...)
 }
 
 // Size returns the current size of the log.
 func (l *ConfigLog) Size() uint64 {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
-	return uint64(len(l.entries))
+	return l.size()
+}
+
+// size returns the size of the log. Callers must hold l.mu.
+func (l *ConfigLog) size() uint64 {
+	return l.cached + uint64(len(l.entries))
+}
+
+// first returns the index of the first entry that was not compacted.
+// Callers must hold l.mu.
+func (l *ConfigLog) first() uint64 {
+	return l.compacted
+}
+
+// lastHash returns the EntryHash of the last entry, or nil if the log is
+// empty. Callers must hold l.mu.
+func (l *ConfigLog) lastHash() []byte {
+	if n := len(l.entries); n > 0 {
+		return l.entries[n-1].EntryHash
+	}
+	return l.compactedHead
+}
+
+// Root returns the size and the Merkle root of the log.
+func (l *ConfigLog) Root() (uint64, []byte) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.tree.Size(), l.tree.Root()
+}
+
+// InclusionProof proves that the entry at index is part of the log of the given size.
+// It returns an error wrapping ErrCompacted if the entry was compacted.
+func (l *ConfigLog) InclusionProof(index, size uint64) ([][]byte, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	proof, err := l.tree.InclusionProof(index, size)
+	return proof, proofError(err)
+}
+
+// ConsistencyProof proves that the log of size second extends the log of size first.
+// It returns an error wrapping ErrCompacted if first is below the first index.
+func (l *ConfigLog) ConsistencyProof(first, second uint64) ([][]byte, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	proof, err := l.tree.ConsistencyProof(first, second)
+	return proof, proofError(err)
 }
 
-// ComputeEntryHash calculates the SHA-256 hash of the entry fields (excluding signature).
-// To be deterministic, we should serialize the fields or a subset of them.
-// We used protobuf.
-// Strategy: Create a copy, clear Signature, Marshal, Hash.
+// ComputeEntryHash calculates the SHA-256 hash of the canonical encoding of
+// the entry (see EncodeEntry). Signature, EntryHash and Value are excluded.
 func ComputeEntryHash(entry *vdcspb.ConfigEntry) ([]byte, error) {
-	// Shallow copy to avoid mutating the original
-	c := *entry
-	// Clear fields not part of the hash
-	c.Signature = nil
-	// Valid question: Is EntryHash part of the hash?
-	// User spec: "EntryHash [32]byte ... Signature covers *all fields*".
-	// "EntryHash" is the hash of the entry. It can't contain itself.
-	// So EntryHash field in the struct is likely just a container for the result.
-	// We MUST clear EntryHash before hashing.
-	c.EntryHash = nil
-
-	// Serialize
-	data, err := proto.Marshal(&c)
+	data, err := EncodeEntry(entry)
 	if err != nil {
 		return nil, err
 	}
@@ -1,13 +1,62 @@
 package log
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/rrb115/vdcs/internal/crypto"
+	"github.com/rrb115/vdcs/internal/merkle"
+	"github.com/rrb115/vdcs/internal/policy"
 	vdcspb "github.com/rrb115/vdcs/proto"
+	"google.golang.org/protobuf/proto"
 )
 
+// newLog returns a log that trusts authors.
+func newLog(authors map[string][]byte) *ConfigLog {
+	l := NewConfigLog()
+	for id, key := range authors {
+		l.AddTrustedAuthor(id, key)
+	}
+	return l
+}
+
+// next sets the index and PrevHash of entry to follow the last entry of l,
+// and returns it.
+func next(l *ConfigLog, entry *vdcspb.ConfigEntry) *vdcspb.ConfigEntry {
+	entry.Index = l.Size()
+	if last, err := l.Get(entry.Index - 1); err == nil {
+		entry.PrevHash = last.EntryHash
+	}
+	return entry
+}
+
+// sign sets the EntryHash of entry, signs it with priv and returns it.
+func sign(t *testing.T, entry *vdcspb.ConfigEntry, priv ed25519.PrivateKey) *vdcspb.ConfigEntry {
+	t.Helper()
+	h, err := ComputeEntryHash(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry.EntryHash = h
+	entry.Signature = crypto.Sign(priv, h)
+	return entry
+}
+
+// signIntent signs intent with priv and returns its hash.
+func signIntent(t *testing.T, intent *vdcspb.Intent, priv ed25519.PrivateKey) []byte {
+	t.Helper()
+	h, err := ComputeIntentHash(intent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intent.Signature = crypto.Sign(priv, h)
+	return h
+}
+
 func TestLogAppend(t *testing.T) {
 	// 1. Setup keys
 	pub, priv, _ := crypto.GenerateKey()
@@ -65,6 +114,26 @@ func TestLogAppend(t *testing.T) {
 	if err := l.Append(entry2); err != nil {
 		t.Fatalf("failed to append second entry: %v", err)
 	}
+
+	// 6. Log Tree proofs
+	size, root := l.Root()
+	if size != 2 {
+		t.Fatalf("expected log tree size 2, got %d", size)
+	}
+	proof, err := l.InclusionProof(0, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merkle.VerifyLogInclusion(entry.EntryHash, 0, size, proof, root) {
+		t.Error("inclusion proof failed for genesis entry")
+	}
+	consistency, err := l.ConsistencyProof(1, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merkle.VerifyLogConsistency(1, size, merkle.LogLeafHash(entry.EntryHash), root, consistency) {
+		t.Error("consistency proof failed")
+	}
 }
 
 func TestLogValidation(t *testing.T) {
@@ -110,3 +179,383 @@ func TestLogValidation(t *testing.T) {
 		t.Error("expected error for hash mismatch")
 	}
 }
+
+func TestLogValueBinding(t *testing.T) {
+	pub, priv, _ := crypto.GenerateKey()
+	l := NewConfigLog()
+	l.AddTrustedAuthor("author1", pub)
+
+	valueHash := crypto.Hash([]byte("value1"))
+	entry := &vdcspb.ConfigEntry{
+		Index:     0,
+		Timestamp: 100,
+		AuthorId:  "author1",
+		Key:       "k",
+		ValueHash: valueHash[:],
+		Operation: vdcspb.Operation_OPERATION_SET,
+		Value:     []byte("tampered"),
+	}
+	h, _ := ComputeEntryHash(entry)
+	entry.EntryHash = h
+	entry.Signature = crypto.Sign(priv, h)
+
+	// Value is outside the signature, so a mismatching Value must be rejected.
+	if err := l.Append(entry); !errors.Is(err, ErrValueMismatch) {
+		t.Fatalf("expected ErrValueMismatch, got %v", err)
+	}
+
+	entry.Value = []byte("value1")
+	if err := l.Append(entry); err != nil {
+		t.Fatalf("failed to append entry with matching value: %v", err)
+	}
+}
+
+func TestLogRange(t *testing.T) {
+	pub, priv, _ := crypto.GenerateKey()
+	l := newLog(map[string][]byte{"author1": pub})
+
+	for i := 0; i < 5; i++ {
+		entry := next(l, &vdcspb.ConfigEntry{
+			Timestamp: 100,
+			AuthorId:  "author1",
+			Key:       "k",
+			Operation: vdcspb.Operation_OPERATION_DELETE,
+		})
+		if err := l.Append(sign(t, entry, priv)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := l.Range(1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[0].Index != 1 || entries[2].Index != 3 {
+		t.Errorf("unexpected range: %v", entries)
+	}
+	if entries, err := l.Range(5, 5); err != nil || len(entries) != 0 {
+		t.Errorf("expected empty range at end, got %v, %v", entries, err)
+	}
+	for _, r := range [][2]uint64{{3, 2}, {0, 6}} {
+		if _, err := l.Range(r[0], r[1]); !errors.Is(err, ErrInvalidIndex) {
+			t.Errorf("expected ErrInvalidIndex for [%d, %d), got %v", r[0], r[1], err)
+		}
+	}
+}
+
+// entryStore is an EntryReader over the entries appended to it.
+type entryStore struct {
+	entries []*vdcspb.ConfigEntry
+}
+
+func (s *entryStore) Get(index uint64) (*vdcspb.ConfigEntry, error) {
+	if index >= uint64(len(s.entries)) {
+		return nil, fmt.Errorf("entry %d not found", index)
+	}
+	return s.entries[index], nil
+}
+
+func (s *entryStore) ReadRange(start, end uint64) ([]*vdcspb.ConfigEntry, error) {
+	if end > uint64(len(s.entries)) {
+		return nil, fmt.Errorf("entries [%d, %d) not found", start, end)
+	}
+	return append([]*vdcspb.ConfigEntry(nil), s.entries[start:end]...), nil
+}
+
+func TestLogStore(t *testing.T) {
+	pub, priv, _ := crypto.GenerateKey()
+	l := newLog(map[string][]byte{"author1": pub})
+	store := &entryStore{}
+	l.SetStore(store)
+	l.tail = 2
+
+	for i := 0; i < 10; i++ {
+		entry := sign(t, next(l, &vdcspb.ConfigEntry{
+			Timestamp: 100,
+			AuthorId:  "author1",
+			Key:       "k",
+			Operation: vdcspb.Operation_OPERATION_DELETE,
+		}), priv)
+		if err := l.Append(entry); err != nil {
+			t.Fatal(err)
+		}
+		store.entries = append(store.entries, entry)
+		if len(l.entries) >= 2*l.tail {
+			t.Fatalf("%d entries in memory with a tail of %d", len(l.entries), l.tail)
+		}
+	}
+
+	// 1. Older entries are read through the store
+	if e, err := l.Get(1); err != nil || e.Index != 1 {
+		t.Errorf("Get(1) = %v, %v", e, err)
+	}
+	entries, err := l.Range(0, 10)
+	if err != nil || len(entries) != 10 {
+		t.Fatalf("Range(0, 10) = %d entries, %v", len(entries), err)
+	}
+	for i, e := range entries {
+		if e.Index != uint64(i) {
+			t.Errorf("entry %d has index %d", i, e.Index)
+		}
+	}
+
+	// 2. Compaction takes the hash of the last compacted entry from the store
+	if err := l.Compact(5); err != nil {
+		t.Fatal(err)
+	}
+	if c := l.Compacted(); !bytes.Equal(c.HeadHash, store.entries[4].EntryHash) {
+		t.Errorf("compacted head %x, expected %x", c.HeadHash, store.entries[4].EntryHash)
+	}
+	if _, err := l.Get(4); !errors.Is(err, ErrCompacted) {
+		t.Errorf("expected ErrCompacted, got %v", err)
+	}
+	if entries, err := l.Range(5, 10); err != nil || len(entries) != 5 || entries[0].Index != 5 {
+		t.Errorf("Range(5, 10) = %v, %v", entries, err)
+	}
+
+	// 3. Read failures are returned
+	store.entries = nil
+	if _, err := l.Get(5); err == nil {
+		t.Error("expected an error reading a missing entry")
+	}
+}
+
+func TestLogPolicy(t *testing.T) {
+	pub, priv, _ := crypto.GenerateKey()
+	l := newLog(map[string][]byte{"payments": pub})
+	l.SetPolicy(&policy.Policy{Rules: []policy.Rule{
+		{Name: "payments-write", Effect: policy.Allow, Authors: []string{"payments"}, Prefixes: []string{"payments/"}},
+	}})
+
+	// 1. Writes outside the author's prefix are rejected, also inside a
+	// transaction.
+	outside := sign(t, &vdcspb.ConfigEntry{
+		AuthorId:  "payments",
+		Key:       "search/index",
+		Operation: vdcspb.Operation_OPERATION_DELETE,
+	}, priv)
+	var de *policy.DeniedError
+	if err := l.Append(outside); !errors.As(err, &de) || de.Key != "search/index" {
+		t.Fatalf("expected denial for search/index, got %v", err)
+	}
+	txn := sign(t, &vdcspb.ConfigEntry{
+		AuthorId:  "payments",
+		Operation: vdcspb.Operation_OPERATION_TRANSACTION,
+		Mutations: []*vdcspb.Mutation{setMutation("payments/limit", "1"), setMutation("search/index", "2")},
+	}, priv)
+	if err := l.Append(txn); !errors.Is(err, policy.ErrDenied) {
+		t.Fatalf("expected denial for transaction, got %v", err)
+	}
+	if l.Size() != 0 {
+		t.Fatalf("denied entries changed the log size to %d", l.Size())
+	}
+
+	// 2. Writes inside the prefix are accepted
+	if err := l.Append(sign(t, &vdcspb.ConfigEntry{
+		AuthorId:  "payments",
+		Key:       "payments/limit",
+		Operation: vdcspb.Operation_OPERATION_DELETE,
+	}, priv)); err != nil {
+		t.Fatalf("append failed: %v", err)
+	}
+}
+
+func TestLogTimestamps(t *testing.T) {
+	pub, priv, _ := crypto.GenerateKey()
+	now := time.Unix(1000, 0)
+	newTimedLog := func() *ConfigLog {
+		l := newLog(map[string][]byte{"author1": pub})
+		l.SetTimestampRules(TimestampRules{MaxSkew: time.Minute, Now: func() time.Time { return now }})
+		return l
+	}
+	l := newTimedLog()
+
+	var appended []*vdcspb.ConfigEntry
+	entry := func(timestamp time.Time) *vdcspb.ConfigEntry {
+		return sign(t, next(l, &vdcspb.ConfigEntry{
+			Timestamp: timestamp.UnixNano(),
+			AuthorId:  "author1",
+			Key:       "k",
+			Operation: vdcspb.Operation_OPERATION_DELETE,
+		}), priv)
+	}
+	mustAppend := func(e *vdcspb.ConfigEntry) {
+		t.Helper()
+		if err := l.Append(e); err != nil {
+			t.Fatalf("append at %d failed: %v", e.Timestamp, err)
+		}
+		appended = append(appended, e)
+	}
+
+	// 1. Timestamps within the skew window, not decreasing
+	mustAppend(entry(now.Add(-30 * time.Second)))
+	mustAppend(entry(now.Add(-30 * time.Second)))
+	mustAppend(entry(now.Add(30 * time.Second)))
+
+	// 2. Violations
+	tests := []struct {
+		timestamp time.Time
+		err       error
+	}{
+		{now, ErrTimestampDecreased},
+		{now.Add(2 * time.Minute), ErrTimestampSkew},
+	}
+	for _, tt := range tests {
+		if err := l.Append(entry(tt.timestamp)); !errors.Is(err, tt.err) {
+			t.Errorf("timestamp %s: expected %v, got %v", tt.timestamp, tt.err, err)
+		}
+	}
+
+	// 3. Replay skips the skew check, but not the ordering
+	now = now.Add(time.Hour)
+	replayed := newTimedLog()
+	for _, e := range appended {
+		if err := replayed.Replay(e); err != nil {
+			t.Fatalf("replay failed at index %d: %v", e.Index, err)
+		}
+	}
+	if err := replayed.Replay(entry(now.Add(-2 * time.Hour))); !errors.Is(err, ErrTimestampDecreased) {
+		t.Errorf("expected ErrTimestampDecreased, got %v", err)
+	}
+}
+
+func TestLogRestore(t *testing.T) {
+	pub, priv, _ := crypto.GenerateKey()
+	l := newLog(map[string][]byte{"author1": pub})
+
+	h := crypto.Hash([]byte("v"))
+	intent := &vdcspb.Intent{
+		AuthorId:  "author1",
+		Key:       "k",
+		Operation: vdcspb.Operation_OPERATION_SET,
+		ValueHash: h[:],
+		Value:     []byte("v"),
+		Nonce:     []byte("n"),
+		ExpiresAt: time.Now().Add(time.Hour).UnixNano(),
+	}
+	signIntent(t, intent, priv)
+	_, seqPriv, _ := crypto.GenerateKey()
+	l.AddSequencer("node", seqPriv.Public().(ed25519.PublicKey))
+	entry := sign(t, &vdcspb.ConfigEntry{
+		Timestamp: time.Now().UnixNano(),
+		AuthorId:  "node",
+		Key:       "k",
+		ValueHash: h[:],
+		Operation: vdcspb.Operation_OPERATION_SET,
+		Value:     []byte("v"),
+		Intent:    intent,
+	}, seqPriv)
+	if err := l.Append(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	// 1. Restore checks the hash chain, not signatures
+	restored := NewConfigLog()
+	if err := restored.Restore(entry); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	bad := proto.Clone(entry).(*vdcspb.ConfigEntry)
+	bad.Index = 1
+	if err := restored.Restore(bad); !errors.Is(err, ErrInvalidPrevHash) {
+		t.Errorf("expected ErrInvalidPrevHash, got %v", err)
+	}
+	_, root := l.Root()
+	if _, restoredRoot := restored.Root(); !bytes.Equal(restoredRoot, root) {
+		t.Error("restored log root differs")
+	}
+
+	// 2. The validation state carries over, including used nonces
+	v := l.Validation()
+	if len(v.Nonces) != 1 || string(v.Nonces[0].Nonce) != "n" || v.LatestTimestamp != entry.Timestamp {
+		t.Fatalf("unexpected validation state %+v", v)
+	}
+	restored.SetValidation(v)
+	restored.AddSequencer("node", seqPriv.Public().(ed25519.PublicKey))
+	again := proto.Clone(entry).(*vdcspb.ConfigEntry)
+	again.Index = 1
+	again.PrevHash = entry.EntryHash
+	if err := restored.Append(sign(t, again, seqPriv)); !errors.Is(err, ErrNonceReused) {
+		t.Errorf("expected ErrNonceReused, got %v", err)
+	}
+}
+
+func TestLogCompact(t *testing.T) {
+	pub, priv, _ := crypto.GenerateKey()
+	l := newLog(map[string][]byte{"author1": pub})
+
+	var entries []*vdcspb.ConfigEntry
+	for i := 0; i < 6; i++ {
+		entry := sign(t, next(l, &vdcspb.ConfigEntry{
+			Timestamp: 100,
+			AuthorId:  "author1",
+			Key:       "k",
+			Operation: vdcspb.Operation_OPERATION_DELETE,
+		}), priv)
+		if err := l.Append(entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	_, root := l.Root()
+	proof, _ := l.ConsistencyProof(4, 6)
+	compaction, err := l.Compaction(4)
+	if err != nil || compaction.Size != 4 || !bytes.Equal(compaction.HeadHash, entries[3].EntryHash) {
+		t.Fatalf("unexpected compaction %+v, %v", compaction, err)
+	}
+
+	// 1. Compacted entries are no longer served, but the root and proofs
+	// from later sizes are unchanged
+	if err := l.Compact(4); err != nil {
+		t.Fatal(err)
+	}
+	if l.FirstIndex() != 4 || l.Size() != 6 {
+		t.Errorf("unexpected first index %d and size %d", l.FirstIndex(), l.Size())
+	}
+	if _, err := l.Get(3); !errors.Is(err, ErrCompacted) {
+		t.Errorf("expected ErrCompacted, got %v", err)
+	}
+	if _, err := l.Range(2, 5); !errors.Is(err, ErrCompacted) {
+		t.Errorf("expected ErrCompacted for a range, got %v", err)
+	}
+	if e, err := l.Get(4); err != nil || e.Index != 4 {
+		t.Errorf("Get(4) = %v, %v", e, err)
+	}
+	if _, compactedRoot := l.Root(); !bytes.Equal(compactedRoot, root) {
+		t.Error("compaction changed the log root")
+	}
+	if p, err := l.ConsistencyProof(4, 6); err != nil || !bytes.Equal(bytes.Join(p, nil), bytes.Join(proof, nil)) {
+		t.Errorf("consistency proof changed: %x, %v", p, err)
+	}
+	if _, err := l.ConsistencyProof(2, 6); !errors.Is(err, ErrCompacted) {
+		t.Errorf("expected ErrCompacted for a consistency proof, got %v", err)
+	}
+	if _, err := l.InclusionProof(3, 6); !errors.Is(err, ErrCompacted) {
+		t.Errorf("expected ErrCompacted for an inclusion proof, got %v", err)
+	}
+	if c := l.Compacted(); c == nil || !bytes.Equal(bytes.Join(c.Frontier, nil), bytes.Join(compaction.Frontier, nil)) {
+		t.Errorf("compacted %+v differs from %+v", c, compaction)
+	}
+	if err := l.Compact(7); !errors.Is(err, ErrInvalidIndex) {
+		t.Errorf("expected ErrInvalidIndex, got %v", err)
+	}
+
+	// 2. A log restored from the frontier continues the same chain
+	restored := NewConfigLog()
+	if err := restored.RestoreCompacted(compaction); err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.Restore(entries[5]); !errors.Is(err, ErrInvalidIndex) {
+		t.Errorf("expected ErrInvalidIndex, got %v", err)
+	}
+	for _, e := range entries[4:] {
+		if err := restored.Restore(e); err != nil {
+			t.Fatalf("restore failed: %v", err)
+		}
+	}
+	if _, restoredRoot := restored.Root(); !bytes.Equal(restoredRoot, root) {
+		t.Error("restored log root differs")
+	}
+	if err := restored.RestoreCompacted(compaction); !errors.Is(err, ErrInvalidIndex) {
+		t.Errorf("expected ErrInvalidIndex for a log that is not empty, got %v", err)
+	}
+}
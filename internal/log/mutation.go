@@ -0,0 +1,125 @@
+package log
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+// Mutations returns the key changes made by an entry: the mutation list of a
+// transaction, or the single key of any other entry.
+func Mutations(entry *vdcspb.ConfigEntry) []*vdcspb.Mutation {
+	if entry.Operation == vdcspb.Operation_OPERATION_TRANSACTION {
+		return entry.Mutations
+	}
+	return []*vdcspb.Mutation{{
+		Key:          entry.Key,
+		ValueHash:    entry.ValueHash,
+		Operation:    entry.Operation,
+		Value:        entry.Value,
+		Precondition: entry.Precondition,
+	}}
+}
+
+// validateMutations checks that an entry's key changes are well formed and
+// that every inline value matches its value hash.
+func validateMutations(entry *vdcspb.ConfigEntry) error {
+	if entry.Operation != vdcspb.Operation_OPERATION_TRANSACTION {
+		if len(entry.Mutations) > 0 {
+			return fmt.Errorf("%w: mutations are only allowed in transactions", ErrInvalidMutation)
+		}
+		if !IsGovernance(entry.Operation) {
+			if err := validateKey(entry.Key); err != nil {
+				return err
+			}
+		}
+		if err := validatePrecondition(entry.Key, entry.Precondition); err != nil {
+			return err
+		}
+		return validateValue(entry.Key, entry.Value, entry.ValueHash)
+	}
+
+	// Transaction: the entry itself carries no key change.
+	if entry.Key != "" || len(entry.ValueHash) > 0 || len(entry.Value) > 0 || entry.Precondition != nil {
+		return fmt.Errorf("%w: transaction must not set key, value hash, value or precondition", ErrInvalidMutation)
+	}
+	if len(entry.Mutations) == 0 {
+		return fmt.Errorf("%w: empty transaction", ErrInvalidMutation)
+	}
+	seen := make(map[string]struct{}, len(entry.Mutations))
+	for i, m := range entry.Mutations {
+		if m.Key == "" {
+			return fmt.Errorf("%w: mutation %d has no key", ErrInvalidMutation, i)
+		}
+		if err := validateKey(m.Key); err != nil {
+			return err
+		}
+		if _, dup := seen[m.Key]; dup {
+			return fmt.Errorf("%w: key %s changed twice", ErrInvalidMutation, m.Key)
+		}
+		seen[m.Key] = struct{}{}
+
+		switch m.Operation {
+		case vdcspb.Operation_OPERATION_SET:
+		case vdcspb.Operation_OPERATION_DELETE:
+			if len(m.ValueHash) > 0 || len(m.Value) > 0 {
+				return fmt.Errorf("%w: delete of %s carries a value", ErrInvalidMutation, m.Key)
+			}
+		default:
+			return fmt.Errorf("%w: unsupported operation %s for key %s", ErrInvalidMutation, m.Operation, m.Key)
+		}
+		if err := validatePrecondition(m.Key, m.Precondition); err != nil {
+			return err
+		}
+		if err := validateValue(m.Key, m.Value, m.ValueHash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateKey rejects SET and DELETE of keys in the governance namespace.
+func validateKey(key string) error {
+	if strings.HasPrefix(key, GovernancePrefix) {
+		return fmt.Errorf("%w: key %s is reserved for governance entries", ErrInvalidMutation, key)
+	}
+	return nil
+}
+
+// validatePrecondition checks that a precondition, if present, has exactly
+// one well-formed condition.
+func validatePrecondition(key string, p *vdcspb.Precondition) error {
+	if p == nil {
+		return nil
+	}
+	switch c := p.Condition.(type) {
+	case *vdcspb.Precondition_ExpectedValueHash:
+		if len(c.ExpectedValueHash) != sha256.Size {
+			return fmt.Errorf("%w: expected value hash for %s is %d bytes", ErrInvalidMutation, key, len(c.ExpectedValueHash))
+		}
+	case *vdcspb.Precondition_MustNotExist:
+		if !c.MustNotExist {
+			return fmt.Errorf("%w: must_not_exist for %s is false", ErrInvalidMutation, key)
+		}
+	case *vdcspb.Precondition_ExpectedModifiedIndex:
+	default:
+		return fmt.Errorf("%w: empty precondition for %s", ErrInvalidMutation, key)
+	}
+	return nil
+}
+
+// validateValue checks that an inline value hashes to its value hash.
+// Value is not part of EntryHash, so this is what binds it to the entry.
+func validateValue(key string, value, valueHash []byte) error {
+	if len(value) == 0 {
+		return nil
+	}
+	if vh := crypto.Hash(value); !bytes.Equal(vh[:], valueHash) {
+		return fmt.Errorf("%w: key %s", ErrValueMismatch, key)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package log
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+func setMutation(key, value string) *vdcspb.Mutation {
+	h := crypto.Hash([]byte(value))
+	return &vdcspb.Mutation{
+		Key:       key,
+		ValueHash: h[:],
+		Operation: vdcspb.Operation_OPERATION_SET,
+		Value:     []byte(value),
+	}
+}
+
+func TestLogTransaction(t *testing.T) {
+	pub, priv, _ := crypto.GenerateKey()
+	l := newLog(map[string][]byte{"author1": pub})
+
+	txn := func(mutations ...*vdcspb.Mutation) *vdcspb.ConfigEntry {
+		return &vdcspb.ConfigEntry{
+			Index:     0,
+			Timestamp: 100,
+			AuthorId:  "author1",
+			Operation: vdcspb.Operation_OPERATION_TRANSACTION,
+			Mutations: mutations,
+		}
+	}
+
+	// 1. Malformed transactions
+	deleteWithValue := setMutation("b", "v")
+	deleteWithValue.Operation = vdcspb.Operation_OPERATION_DELETE
+	tampered := setMutation("b", "v")
+	tampered.Value = []byte("other")
+	withKey := txn(setMutation("a", "1"))
+	withKey.Key = "a"
+	emptyPrecondition := setMutation("b", "v")
+	emptyPrecondition.Precondition = &vdcspb.Precondition{}
+	shortHash := setMutation("b", "v")
+	shortHash.Precondition = &vdcspb.Precondition{
+		Condition: &vdcspb.Precondition_ExpectedValueHash{ExpectedValueHash: []byte{1}},
+	}
+	stray := &vdcspb.ConfigEntry{
+		Key:       "a",
+		Operation: vdcspb.Operation_OPERATION_SET,
+		AuthorId:  "author1",
+		Mutations: []*vdcspb.Mutation{setMutation("b", "2")},
+	}
+
+	for name, tc := range map[string]struct {
+		entry *vdcspb.ConfigEntry
+		err   error
+	}{
+		"empty":              {txn(), ErrInvalidMutation},
+		"duplicate key":      {txn(setMutation("a", "1"), setMutation("a", "2")), ErrInvalidMutation},
+		"missing key":        {txn(setMutation("", "1")), ErrInvalidMutation},
+		"delete with value":  {txn(deleteWithValue), ErrInvalidMutation},
+		"value mismatch":     {txn(tampered), ErrValueMismatch},
+		"empty precondition": {txn(emptyPrecondition), ErrInvalidMutation},
+		"short value hash":   {txn(shortHash), ErrInvalidMutation},
+		"entry key set":      {withKey, ErrInvalidMutation},
+		"mutations on set":   {stray, ErrInvalidMutation},
+	} {
+		if err := l.Append(sign(t, tc.entry, priv)); !errors.Is(err, tc.err) {
+			t.Errorf("%s: expected %v, got %v", name, tc.err, err)
+		}
+	}
+	if l.Size() != 0 {
+		t.Fatalf("rejected transactions changed the log")
+	}
+
+	// 2. Valid transaction
+	entry := sign(t, txn(setMutation("a", "1"), &vdcspb.Mutation{
+		Key:       "b",
+		Operation: vdcspb.Operation_OPERATION_DELETE,
+	}), priv)
+	if err := l.Append(entry); err != nil {
+		t.Fatalf("failed to append transaction: %v", err)
+	}
+
+	// 3. Mutations are signed
+	entry.Mutations[0].Key = "c"
+	if again, _ := ComputeEntryHash(entry); string(again) == string(entry.EntryHash) {
+		t.Error("entry hash does not cover mutations")
+	}
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rrb115/vdcs/internal/policy"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+// Validation is what the Log knows, beyond the entries themselves, to
+// validate the next entry: the authors, the policy, the unexpired intent
+// nonces and the latest timestamp. A snapshot saves it so that the entries
+// it covers need not be validated again.
+type Validation struct {
+	Authors         map[string][]byte `json:"authors"`          // AuthorID -> Public Key
+	Policy          *policy.Policy    `json:"policy,omitempty"` // Nil allows every data write
+	Nonces          []Nonce           `json:"nonces,omitempty"`
+	LatestTimestamp int64             `json:"latest_timestamp"`
+}
+
+// Nonce is the nonce of a sequenced intent that has not expired yet.
+type Nonce struct {
+	AuthorID  string `json:"author_id"`
+	Nonce     []byte `json:"nonce"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// Validation returns the validation state as of the last appended entry.
+// Nonces are sorted, so equal states compare equal.
+func (l *ConfigLog) Validation() Validation {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	v := Validation{
+		Authors:         make(map[string][]byte, len(l.authorConfig)),
+		Policy:          l.policy,
+		LatestTimestamp: l.latestTimestamp,
+	}
+	for id, cfg := range l.authorConfig {
+		v.Authors[id] = cfg.PublicKey
+	}
+	for k, expiresAt := range l.nonces {
+		authorID, nonce, _ := strings.Cut(k, "\x00")
+		v.Nonces = append(v.Nonces, Nonce{AuthorID: authorID, Nonce: []byte(nonce), ExpiresAt: expiresAt})
+	}
+	sort.Slice(v.Nonces, func(i, j int) bool {
+		a, b := v.Nonces[i], v.Nonces[j]
+		if a.AuthorID != b.AuthorID {
+			return a.AuthorID < b.AuthorID
+		}
+		return bytes.Compare(a.Nonce, b.Nonce) < 0
+	})
+	return v
+}
+
+// Restore adds an entry that was validated before, e.g. one covered by a
+// snapshot. It only checks that the entry extends the log: its index, its
+// previous hash and its entry hash. Signatures, intents, governance and the
+// policy are not checked, and the validation state is left as it is; call
+// SetValidation once the covered entries are restored.
+func (l *ConfigLog) Restore(entry *vdcspb.ConfigEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	nextIndex := l.size()
+	if entry.Index != nextIndex {
+		return fmt.Errorf("%w: expected %d, got %d", ErrInvalidIndex, nextIndex, entry.Index)
+	}
+	if !bytes.Equal(entry.PrevHash, l.lastHash()) {
+		return fmt.Errorf("%w: mismatch", ErrInvalidPrevHash)
+	}
+	computedHash, err := ComputeEntryHash(entry)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(computedHash, entry.EntryHash) {
+		return fmt.Errorf("%w: computed %x != provided %x", ErrInvalidHash, computedHash, entry.EntryHash)
+	}
+
+	l.push(entry)
+	return nil
+}
+
+// SetValidation replaces the authors, policy, nonces and latest timestamp,
+// e.g. with those saved in a snapshot. Sequencers, genesis trust anchors and
+// timestamp rules are kept.
+func (l *ConfigLog) SetValidation(v Validation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.trustedKeys = make(map[string]struct{}, len(v.Authors))
+	l.authorConfig = make(map[string]AuthorConfig, len(v.Authors))
+	for id, pub := range v.Authors {
+		l.trustedKeys[id] = struct{}{}
+		l.authorConfig[id] = AuthorConfig{PublicKey: pub}
+	}
+	l.policy = v.Policy
+	l.nonces = make(map[string]int64, len(v.Nonces))
+	for _, n := range v.Nonces {
+		l.nonces[n.AuthorID+"\x00"+string(n.Nonce)] = n.ExpiresAt
+	}
+	l.latestTimestamp = v.LatestTimestamp
+}
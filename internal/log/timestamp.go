@@ -0,0 +1,45 @@
+package log
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimestampRules configures how the log checks entry timestamps. Timestamps
+// must never decrease; MaxSkew also bounds new entries by the clock.
+type TimestampRules struct {
+	// MaxSkew is how far the timestamp of an appended entry may be from the
+	// clock, in either direction. Zero disables the check. Replay skips it:
+	// old entries were checked against the clock when they were appended.
+	MaxSkew time.Duration
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// SetTimestampRules replaces the timestamp rules. Only entries added
+// afterwards are checked.
+func (l *ConfigLog) SetTimestampRules(rules TimestampRules) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if rules.Now == nil {
+		rules.Now = time.Now
+	}
+	l.timestamps = rules
+}
+
+// validateTimestamp checks an entry's timestamp against the previous entries
+// and, for live entries, against the clock. Callers must hold l.mu.
+func (l *ConfigLog) validateTimestamp(timestamp int64, live bool) error {
+	if timestamp < l.latestTimestamp {
+		return fmt.Errorf("%w: %d is before %d", ErrTimestampDecreased, timestamp, l.latestTimestamp)
+	}
+	if !live || l.timestamps.MaxSkew == 0 {
+		return nil
+	}
+	now := l.timestamps.Now()
+	skew := time.Unix(0, timestamp).Sub(now)
+	if skew > l.timestamps.MaxSkew || -skew > l.timestamps.MaxSkew {
+		return fmt.Errorf("%w: %d is %s from the clock (max %s)", ErrTimestampSkew, timestamp, skew.Round(time.Millisecond), l.timestamps.MaxSkew)
+	}
+	return nil
+}
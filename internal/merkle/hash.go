@@ -0,0 +1,49 @@
+package merkle
+
+import (
+	"encoding/binary"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+)
+
+// TreeFormatVersion identifies the hashing scheme used to build roots.
+// It is advertised in ConfigState so clients can tell which scheme produced a
+// root before trying to verify proofs against it.
+//
+//   - 0: unversioned. Leaf = Hash(key || valueHash), Node = Hash(left || right).
+//   - 1: RFC 6962-style domain separation. Leaf = Hash(0x00 || len(key) || key || valueHash),
+//     Node = Hash(0x01 || left || right), with len(key) as a 4-byte big-endian integer.
+//     An empty sparse subtree hashes to Hash("empty").
+//   - 2: as 1, but an empty sparse subtree hashes to Hash(0x02), so it has a
+//     domain of its own.
+const TreeFormatVersion uint32 = 2
+
+// Domain separation prefixes, as in RFC 6962 section 2.1, plus one for the
+// empty subtrees of the sparse tree.
+const (
+	leafHashPrefix  byte = 0x00
+	nodeHashPrefix  byte = 0x01
+	emptyHashPrefix byte = 0x02
+)
+
+// LeafHash computes the hash of a leaf binding key to valueHash.
+// The key is length-prefixed so the key/value boundary is unambiguous.
+func LeafHash(key, valueHash []byte) []byte {
+	input := make([]byte, 0, 1+4+len(key)+len(valueHash))
+	input = append(input, leafHashPrefix)
+	input = binary.BigEndian.AppendUint32(input, uint32(len(key)))
+	input = append(input, key...)
+	input = append(input, valueHash...)
+	h := crypto.Hash(input)
+	return h[:]
+}
+
+// NodeHash computes the hash of an interior node from its children.
+func NodeHash(left, right []byte) []byte {
+	input := make([]byte, 0, 1+len(left)+len(right))
+	input = append(input, nodeHashPrefix)
+	input = append(input, left...)
+	input = append(input, right...)
+	h := crypto.Hash(input)
+	return h[:]
+}
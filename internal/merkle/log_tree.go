@@ -0,0 +1,337 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+)
+
+var (
+	ErrIndexOutOfRange = errors.New("leaf index out of range")
+	ErrInvalidTreeSize = errors.New("invalid tree size")
+	ErrPruned          = errors.New("leaves pruned from tree")
+)
+
+// LogTree is an append-only Merkle tree over log entries, as defined in
+// RFC 6962 section 2.1 (and RFC 9162 section 2.1). It supports inclusion
+// proofs for single entries and consistency proofs between two tree sizes.
+//
+// Leaves are LogLeafHash(EntryHash) and interior nodes are NodeHash, so the
+// tree can be verified with any RFC 6962 implementation.
+//
+// A tree can be pruned up to a size: it then keeps only the frontier of the
+// leaves before it, the roots of the perfect subtrees covering them. Roots at
+// later sizes, inclusion proofs for later leaves and consistency proofs from
+// later sizes do not need anything else.
+type LogTree struct {
+	// levels[0] holds the leaf hashes. levels[k][i] holds the root of the
+	// perfect subtree covering leaves [(offsets[k]+i)*2^k, (offsets[k]+i+1)*2^k).
+	// Only complete subtrees are stored, so any subtree root can be
+	// assembled in O(log n).
+	levels  [][][]byte
+	offsets []uint64 // Index of the first node levels[k] holds
+	pruned  uint64   // Leaves before it are only known by the frontier
+}
+
+// NewLogTree creates an empty log tree.
+func NewLogTree() *LogTree {
+	return &LogTree{}
+}
+
+// LogLeafHash computes the leaf hash of a log entry.
+// Leaf = Hash(0x00 || EntryHash)
+func LogLeafHash(entryHash []byte) []byte {
+	h := crypto.Hash(append([]byte{leafHashPrefix}, entryHash...))
+	return h[:]
+}
+
+// emptyLogRoot is the root of an empty log tree, Hash("").
+func emptyLogRoot() []byte {
+	h := crypto.Hash(nil)
+	return h[:]
+}
+
+// Append adds the entry with the given EntryHash as the next leaf.
+func (t *LogTree) Append(entryHash []byte) {
+	h := LogLeafHash(entryHash)
+	for k := 0; ; k++ {
+		if k == len(t.levels) {
+			t.levels = append(t.levels, nil)
+			t.offsets = append(t.offsets, 0)
+		}
+		t.levels[k] = append(t.levels[k], h)
+		n := len(t.levels[k])
+		if (t.offsets[k]+uint64(n))%2 == 1 {
+			return
+		}
+		// This append completed a perfect subtree one level up.
+		h = NodeHash(t.levels[k][n-2], t.levels[k][n-1])
+	}
+}
+
+// Size returns the number of leaves in the tree.
+func (t *LogTree) Size() uint64 {
+	if len(t.levels) == 0 {
+		return 0
+	}
+	return t.offsets[0] + uint64(len(t.levels[0]))
+}
+
+// Pruned returns the size the tree was pruned up to.
+func (t *LogTree) Pruned() uint64 {
+	return t.pruned
+}
+
+// Root returns the root of the tree at its current size.
+func (t *LogTree) Root() []byte {
+	root, _ := t.RootAt(t.Size())
+	return root
+}
+
+// RootAt returns the root the tree had when it contained `size` leaves.
+func (t *LogTree) RootAt(size uint64) ([]byte, error) {
+	if size > t.Size() {
+		return nil, fmt.Errorf("%w: %d > %d", ErrInvalidTreeSize, size, t.Size())
+	}
+	if size == 0 {
+		return emptyLogRoot(), nil
+	}
+	if size < t.pruned {
+		return nil, fmt.Errorf("%w: root at size %d of tree pruned up to %d", ErrPruned, size, t.pruned)
+	}
+	return t.subtreeHash(0, size), nil
+}
+
+// Frontier returns the roots of the perfect subtrees covering the first size
+// leaves, largest first. size must not be below the size the tree was pruned
+// up to.
+func (t *LogTree) Frontier(size uint64) ([][]byte, error) {
+	if size > t.Size() {
+		return nil, fmt.Errorf("%w: %d > %d", ErrInvalidTreeSize, size, t.Size())
+	}
+	if size < t.pruned {
+		return nil, fmt.Errorf("%w: frontier at size %d of tree pruned up to %d", ErrPruned, size, t.pruned)
+	}
+	frontier := make([][]byte, 0, bits.OnesCount64(size))
+	lo := uint64(0)
+	for k := bits.Len64(size) - 1; k >= 0; k-- {
+		if size&(1<<k) != 0 {
+			frontier = append(frontier, t.node(k, lo>>k))
+			lo += 1 << k
+		}
+	}
+	return frontier, nil
+}
+
+// Prune drops the nodes that only proofs about the first size leaves need,
+// keeping their frontier. Pruning up to a size the tree was already pruned
+// up to does nothing.
+func (t *LogTree) Prune(size uint64) error {
+	if size > t.Size() {
+		return fmt.Errorf("%w: %d > %d", ErrInvalidTreeSize, size, t.Size())
+	}
+	if size <= t.pruned {
+		return nil
+	}
+	for k := range t.levels {
+		// The frontier node on level k, if any, is the last one before size.
+		start := size >> k
+		if size&(1<<k) != 0 {
+			start--
+		}
+		if start > t.offsets[k] {
+			// Copy the rest, so the dropped nodes can be freed.
+			t.levels[k] = append([][]byte(nil), t.levels[k][start-t.offsets[k]:]...)
+			t.offsets[k] = start
+		}
+	}
+	t.pruned = size
+	return nil
+}
+
+// RestoreLogTree returns a tree of the given size, pruned up to it, from its
+// frontier as returned by Frontier.
+func RestoreLogTree(size uint64, frontier [][]byte) (*LogTree, error) {
+	if len(frontier) != bits.OnesCount64(size) {
+		return nil, fmt.Errorf("%w: %d frontier nodes for size %d", ErrInvalidTreeSize, len(frontier), size)
+	}
+	t := &LogTree{
+		levels:  make([][][]byte, bits.Len64(size)),
+		offsets: make([]uint64, bits.Len64(size)),
+		pruned:  size,
+	}
+	j := 0
+	for k := len(t.levels) - 1; k >= 0; k-- {
+		t.offsets[k] = size >> k
+		if size&(1<<k) != 0 {
+			t.offsets[k]--
+			t.levels[k] = [][]byte{frontier[j]}
+			j++
+		}
+	}
+	return t, nil
+}
+
+// InclusionProof returns the audit path for the leaf at `index` in the tree
+// of the given size (RFC 9162 section 2.1.3.1).
+func (t *LogTree) InclusionProof(index, size uint64) ([][]byte, error) {
+	if size > t.Size() {
+		return nil, fmt.Errorf("%w: %d > %d", ErrInvalidTreeSize, size, t.Size())
+	}
+	if index >= size {
+		return nil, fmt.Errorf("%w: %d >= %d", ErrIndexOutOfRange, index, size)
+	}
+	if index < t.pruned {
+		return nil, fmt.Errorf("%w: leaf %d of tree pruned up to %d", ErrPruned, index, t.pruned)
+	}
+	return t.path(index, 0, size), nil
+}
+
+// ConsistencyProof returns a proof that the tree of size `second` is an
+// append-only extension of the tree of size `first` (RFC 9162 section 2.1.4.1).
+func (t *LogTree) ConsistencyProof(first, second uint64) ([][]byte, error) {
+	if second > t.Size() {
+		return nil, fmt.Errorf("%w: %d > %d", ErrInvalidTreeSize, second, t.Size())
+	}
+	if first > second {
+		return nil, fmt.Errorf("%w: first %d > second %d", ErrInvalidTreeSize, first, second)
+	}
+	if first == 0 || first == second {
+		return [][]byte{}, nil
+	}
+	if first < t.pruned {
+		return nil, fmt.Errorf("%w: consistency from size %d of tree pruned up to %d", ErrPruned, first, t.pruned)
+	}
+	return t.subproof(first, 0, second, true), nil
+}
+
+// subtreeHash returns MTH(D[lo:hi]).
+func (t *LogTree) subtreeHash(lo, hi uint64) []byte {
+	n := hi - lo
+	if n&(n-1) == 0 && lo%n == 0 {
+		// Perfect, aligned subtree: stored directly.
+		k := bits.TrailingZeros64(n)
+		return t.node(k, lo>>k)
+	}
+	k := splitPoint(n)
+	return NodeHash(t.subtreeHash(lo, lo+k), t.subtreeHash(lo+k, hi))
+}
+
+// node returns the root of the i-th perfect subtree on level k.
+func (t *LogTree) node(k int, i uint64) []byte {
+	return t.levels[k][i-t.offsets[k]]
+}
+
+// path computes PATH(m, D[lo:hi]).
+func (t *LogTree) path(m, lo, hi uint64) [][]byte {
+	if hi-lo == 1 {
+		return [][]byte{}
+	}
+	k := splitPoint(hi - lo)
+	if m < k {
+		return append(t.path(m, lo, lo+k), t.subtreeHash(lo+k, hi))
+	}
+	return append(t.path(m-k, lo+k, hi), t.subtreeHash(lo, lo+k))
+}
+
+// subproof computes SUBPROOF(m, D[lo:hi], b).
+func (t *LogTree) subproof(m, lo, hi uint64, b bool) [][]byte {
+	n := hi - lo
+	if m == n {
+		if b {
+			return [][]byte{}
+		}
+		return [][]byte{t.subtreeHash(lo, hi)}
+	}
+	k := splitPoint(n)
+	if m <= k {
+		return append(t.subproof(m, lo, lo+k, b), t.subtreeHash(lo+k, hi))
+	}
+	return append(t.subproof(m-k, lo+k, hi, false), t.subtreeHash(lo, lo+k))
+}
+
+// splitPoint returns the largest power of two smaller than n (n > 1).
+func splitPoint(n uint64) uint64 {
+	return 1 << (bits.Len64(n-1) - 1)
+}
+
+// VerifyLogInclusion checks that the entry with the given EntryHash is the
+// leaf at `index` of the log tree with the given size and root
+// (RFC 9162 section 2.1.3.2).
+func VerifyLogInclusion(entryHash []byte, index, size uint64, proof [][]byte, root []byte) bool {
+	if index >= size {
+		return false
+	}
+	fn, sn := index, size-1
+	r := LogLeafHash(entryHash)
+	for _, p := range proof {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = NodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = NodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(r, root)
+}
+
+// VerifyLogConsistency checks that the log tree of size `second` with root
+// secondRoot extends the tree of size `first` with root firstRoot
+// (RFC 9162 section 2.1.4.2).
+func VerifyLogConsistency(first, second uint64, firstRoot, secondRoot []byte, proof [][]byte) bool {
+	switch {
+	case first > second:
+		return false
+	case first == second:
+		return len(proof) == 0 && bytes.Equal(firstRoot, secondRoot)
+	case first == 0:
+		// Every tree extends the empty tree.
+		return len(proof) == 0
+	case len(proof) == 0:
+		return false
+	}
+
+	path := proof
+	if first&(first-1) == 0 {
+		// The old tree is a perfect subtree of the new one, so its root is
+		// the first node of the path.
+		path = append([][]byte{firstRoot}, proof...)
+	}
+
+	fn, sn := first-1, second-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+
+	fr, sr := path[0], path[0]
+	for _, c := range path[1:] {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = NodeHash(c, fr)
+			sr = NodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = NodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(fr, firstRoot) && bytes.Equal(sr, secondRoot)
+}
@@ -0,0 +1,203 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+)
+
+// referenceRoot computes MTH(D[n]) directly from RFC 6962 section 2.1.
+func referenceRoot(entryHashes [][]byte) []byte {
+	switch n := uint64(len(entryHashes)); n {
+	case 0:
+		h := crypto.Hash(nil)
+		return h[:]
+	case 1:
+		return LogLeafHash(entryHashes[0])
+	default:
+		k := splitPoint(n)
+		return NodeHash(referenceRoot(entryHashes[:k]), referenceRoot(entryHashes[k:]))
+	}
+}
+
+func testEntryHashes(n int) [][]byte {
+	hashes := make([][]byte, n)
+	for i := range hashes {
+		h := crypto.Hash([]byte(fmt.Sprintf("entry%d", i)))
+		hashes[i] = h[:]
+	}
+	return hashes
+}
+
+func TestLogTree_Root(t *testing.T) {
+	hashes := testEntryHashes(40)
+	tree := NewLogTree()
+
+	if !bytes.Equal(tree.Root(), referenceRoot(nil)) {
+		t.Error("unexpected empty root")
+	}
+	for i, h := range hashes {
+		tree.Append(h)
+		if tree.Size() != uint64(i+1) {
+			t.Fatalf("expected size %d, got %d", i+1, tree.Size())
+		}
+		if !bytes.Equal(tree.Root(), referenceRoot(hashes[:i+1])) {
+			t.Fatalf("root mismatch at size %d", i+1)
+		}
+	}
+
+	// Historical roots stay available.
+	for size := uint64(0); size <= tree.Size(); size++ {
+		root, err := tree.RootAt(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(root, referenceRoot(hashes[:size])) {
+			t.Errorf("historical root mismatch at size %d", size)
+		}
+	}
+	if _, err := tree.RootAt(tree.Size() + 1); err == nil {
+		t.Error("expected error for root beyond tree size")
+	}
+}
+
+func TestLogTree_InclusionProof(t *testing.T) {
+	hashes := testEntryHashes(33)
+	tree := NewLogTree()
+	for _, h := range hashes {
+		tree.Append(h)
+	}
+
+	for size := uint64(1); size <= tree.Size(); size++ {
+		root, _ := tree.RootAt(size)
+		for index := uint64(0); index < size; index++ {
+			proof, err := tree.InclusionProof(index, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !VerifyLogInclusion(hashes[index], index, size, proof, root) {
+				t.Fatalf("inclusion proof failed for index %d size %d", index, size)
+			}
+			// Wrong index or wrong entry must fail.
+			if size > 1 && VerifyLogInclusion(hashes[index], (index+1)%size, size, proof, root) {
+				t.Fatalf("inclusion proof verified at wrong index %d size %d", index, size)
+			}
+			if VerifyLogInclusion(hashes[(index+1)%uint64(len(hashes))], index, size, proof, root) {
+				t.Fatalf("inclusion proof verified for wrong entry at index %d size %d", index, size)
+			}
+		}
+	}
+
+	if _, err := tree.InclusionProof(5, 5); err == nil {
+		t.Error("expected error for index beyond tree size")
+	}
+}
+
+func TestLogTree_ConsistencyProof(t *testing.T) {
+	hashes := testEntryHashes(33)
+	tree := NewLogTree()
+	for _, h := range hashes {
+		tree.Append(h)
+	}
+
+	for second := uint64(0); second <= tree.Size(); second++ {
+		secondRoot, _ := tree.RootAt(second)
+		for first := uint64(0); first <= second; first++ {
+			firstRoot, _ := tree.RootAt(first)
+			proof, err := tree.ConsistencyProof(first, second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !VerifyLogConsistency(first, second, firstRoot, secondRoot, proof) {
+				t.Fatalf("consistency proof failed for %d -> %d", first, second)
+			}
+
+			// A forked history must fail.
+			if first > 0 && first < second {
+				forked := append([]byte{}, firstRoot...)
+				forked[0] ^= 0xFF
+				if VerifyLogConsistency(first, second, forked, secondRoot, proof) {
+					t.Fatalf("consistency proof verified for forked root %d -> %d", first, second)
+				}
+			}
+		}
+	}
+
+	if _, err := tree.ConsistencyProof(3, 2); err == nil {
+		t.Error("expected error for first > second")
+	}
+}
+
+func TestLogTree_Prune(t *testing.T) {
+	hashes := testEntryHashes(33)
+	full := NewLogTree()
+	for _, h := range hashes {
+		full.Append(h)
+	}
+
+	for pruned := uint64(0); pruned <= 20; pruned++ {
+		// A tree pruned while it grows, and one restored from the frontier,
+		// must serve the same roots and proofs as the full tree.
+		tree := NewLogTree()
+		for _, h := range hashes[:20] {
+			tree.Append(h)
+		}
+		frontier, err := tree.Frontier(pruned)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tree.Prune(pruned); err != nil {
+			t.Fatal(err)
+		}
+		restored, err := RestoreLogTree(pruned, frontier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, h := range hashes[pruned:20] {
+			restored.Append(h)
+		}
+		for _, h := range hashes[20:] {
+			tree.Append(h)
+			restored.Append(h)
+		}
+
+		for _, tr := range []*LogTree{tree, restored} {
+			if tr.Size() != full.Size() || tr.Pruned() != pruned {
+				t.Fatalf("pruned %d: size %d, pruned %d", pruned, tr.Size(), tr.Pruned())
+			}
+			for second := pruned; second <= full.Size(); second++ {
+				want, _ := full.RootAt(second)
+				if root, err := tr.RootAt(second); err != nil || !bytes.Equal(root, want) {
+					t.Fatalf("pruned %d: root at %d differs: %v", pruned, second, err)
+				}
+				for first := pruned; first <= second; first++ {
+					want, _ := full.ConsistencyProof(first, second)
+					if proof, err := tr.ConsistencyProof(first, second); err != nil || !bytes.Equal(bytes.Join(proof, nil), bytes.Join(want, nil)) {
+						t.Fatalf("pruned %d: consistency proof %d -> %d differs: %v", pruned, first, second, err)
+					}
+				}
+				for index := pruned; index < second; index++ {
+					want, _ := full.InclusionProof(index, second)
+					if proof, err := tr.InclusionProof(index, second); err != nil || !bytes.Equal(bytes.Join(proof, nil), bytes.Join(want, nil)) {
+						t.Fatalf("pruned %d: inclusion proof %d in %d differs: %v", pruned, index, second, err)
+					}
+				}
+			}
+			if pruned > 1 {
+				if _, err := tr.InclusionProof(pruned-1, tr.Size()); !errors.Is(err, ErrPruned) {
+					t.Errorf("pruned %d: expected ErrPruned for an inclusion proof, got %v", pruned, err)
+				}
+				if _, err := tr.ConsistencyProof(pruned-1, tr.Size()); !errors.Is(err, ErrPruned) {
+					t.Errorf("pruned %d: expected ErrPruned for a consistency proof, got %v", pruned, err)
+				}
+			}
+		}
+	}
+
+	if _, err := RestoreLogTree(5, testEntryHashes(1)); !errors.Is(err, ErrInvalidTreeSize) {
+		t.Errorf("expected ErrInvalidTreeSize for a short frontier, got %v", err)
+	}
+}
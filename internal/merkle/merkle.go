@@ -23,10 +23,7 @@ func NewTree(kv map[string][]byte) *Tree {
 
 	for k, vHash := range kv {
 		keys = append(keys, k)
-		// Leaf = Hash(Key || ValueHash)
-		input := append([]byte(k), vHash...)
-		hash := crypto.Hash(input)
-		leaves[k] = hash[:]
+		leaves[k] = LeafHash([]byte(k), vHash)
 		valueHashes[k] = vHash
 	}
 
@@ -60,6 +57,5 @@ func (t *Tree) computeRoot(nodes []string) []byte {
 	leftRoot := t.computeRoot(nodes[:mid])
 	rightRoot := t.computeRoot(nodes[mid:])
 
-	h := crypto.Hash(append(leftRoot, rightRoot...))
-	return h[:]
+	return NodeHash(leftRoot, rightRoot)
 }
@@ -61,8 +61,8 @@ func TestMerkleTree_Single(t *testing.T) {
 	tree := NewTree(kv)
 	root := tree.Root()
 
-	// Leaf = Hash(k || v)
-	expected := crypto.Hash(append([]byte("k"), []byte("v")...))
+	// Leaf = Hash(0x00 || len(k) || k || v)
+	expected := crypto.Hash([]byte{0x00, 0, 0, 0, 1, 'k', 'v'})
 	if !bytes.Equal(root, expected[:]) {
 		t.Errorf("expected leaf hash for single node tree")
 	}
@@ -114,3 +114,24 @@ func TestMerkleTree_Tamper(t *testing.T) {
 		}
 	}
 }
+
+func TestHashDomainSeparation(t *testing.T) {
+	left := bytes.Repeat([]byte{0xAA}, 32)
+	right := bytes.Repeat([]byte{0xBB}, 32)
+
+	// 1. A leaf over the same bytes as a node must not collide with it.
+	if bytes.Equal(LeafHash(left, right), NodeHash(left, right)) {
+		t.Error("leaf and node hashes collide")
+	}
+
+	// 2. Moving bytes across the key/value boundary must change the leaf.
+	if bytes.Equal(LeafHash([]byte("ab"), []byte("c")), LeafHash([]byte("a"), []byte("bc"))) {
+		t.Error("key/value boundary is ambiguous")
+	}
+
+	// 3. Node = Hash(0x01 || left || right)
+	expected := crypto.Hash(append(append([]byte{0x01}, left...), right...))
+	if !bytes.Equal(NodeHash(left, right), expected[:]) {
+		t.Error("unexpected node hash encoding")
+	}
+}
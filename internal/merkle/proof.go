@@ -3,8 +3,6 @@ package merkle
 import (
 	"bytes"
 	"errors"
-
-	"github.com/rrb115/vdcs/internal/crypto"
 )
 
 // Proof represents a Merkle inclusion proof.
@@ -13,8 +11,8 @@ type Proof struct {
 	ValueHash []byte
 	Siblings  [][]byte
 	// IsLeft indicates if the Sibling at the same index is on the Left side.
-	// If true: H(Sibling || Current)
-	// If false: H(Current || Sibling)
+	// If true: NodeHash(Sibling, Current)
+	// If false: NodeHash(Current, Sibling)
 	IsLeft []bool
 }
 
@@ -100,9 +98,7 @@ func (t *Tree) generateRecursiveProof(nodes []string, targetKey string) (*Proof,
 // Verify checks if the proof is valid for the given root.
 func (p *Proof) Verify(root []byte) bool {
 	// 1. Start with the leaf hash.
-	input := append([]byte(p.Key), p.ValueHash...)
-	currentHash := crypto.Hash(input)
-	currentHashBytes := currentHash[:]
+	currentHashBytes := LeafHash([]byte(p.Key), p.ValueHash)
 
 	// 2. Apply siblings up to the root.
 	if len(p.Siblings) != len(p.IsLeft) {
@@ -113,19 +109,13 @@ func (p *Proof) Verify(root []byte) bool {
 		sibling := p.Siblings[i]
 		isLeft := p.IsLeft[i]
 
-		var nextInput []byte
 		if isLeft {
 			// Sibling is Left, Current is Right
-			// Hash(Sibling || Current)
-			nextInput = append(sibling, currentHashBytes...)
+			currentHashBytes = NodeHash(sibling, currentHashBytes)
 		} else {
 			// Sibling is Right, Current is Left
-			// Hash(Current || Sibling)
-			nextInput = append(currentHashBytes, sibling...)
+			currentHashBytes = NodeHash(currentHashBytes, sibling)
 		}
-
-		newHash := crypto.Hash(nextInput)
-		currentHashBytes = newHash[:]
 	}
 
 	return bytes.Equal(currentHashBytes, root)
@@ -0,0 +1,215 @@
+package merkle
+
+import (
+	"bytes"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+)
+
+// SparseTree is a sparse Merkle tree keyed by the SHA-256 hash of each key.
+// Every possible key has a fixed position (the bits of its hash, most
+// significant first), so the tree can prove that a key is present and also
+// that it is absent.
+//
+// Empty subtrees hash to a constant placeholder and subtrees holding a single
+// leaf are collapsed into that leaf, so the depth of the tree stays close to
+// log2(n) instead of 256.
+//
+// The tree is persistent: internal node hashes are cached, and Set and Delete
+// only rehash the path of the changed key. It is not safe for concurrent use.
+type SparseTree struct {
+	root *sparseNode
+}
+
+// sparseNode is either a leaf (keyHash set) or an internal node.
+// A nil child is an empty subtree.
+type sparseNode struct {
+	hash        []byte
+	left, right *sparseNode // Internal nodes only
+	keyHash     []byte      // Leaf nodes only
+	valueHash   []byte      // Leaf nodes only
+}
+
+func (n *sparseNode) isLeaf() bool {
+	return n.keyHash != nil
+}
+
+func newSparseLeaf(keyHash, valueHash []byte) *sparseNode {
+	return &sparseNode{
+		hash:      sparseLeafHash(keyHash, valueHash),
+		keyHash:   keyHash,
+		valueHash: valueHash,
+	}
+}
+
+// rehash recomputes the hash of an internal node from its children.
+func (n *sparseNode) rehash() {
+	n.hash = NodeHash(subtreeHash(n.left), subtreeHash(n.right))
+}
+
+func subtreeHash(n *sparseNode) []byte {
+	if n == nil {
+		return emptySubtree()
+	}
+	return n.hash
+}
+
+// NewSparseTree builds a sparse Merkle tree from the given map of key -> valueHash.
+func NewSparseTree(kv map[string][]byte) *SparseTree {
+	t := &SparseTree{}
+	for k, vHash := range kv {
+		t.Set(k, vHash)
+	}
+	return t
+}
+
+// Root returns the Merkle root of the tree.
+func (t *SparseTree) Root() []byte {
+	return subtreeHash(t.root)
+}
+
+// Set inserts or replaces the value hash of a key.
+func (t *SparseTree) Set(key string, valueHash []byte) {
+	kh := crypto.Hash([]byte(key))
+	t.root = insert(t.root, 0, newSparseLeaf(kh[:], valueHash))
+}
+
+// Delete removes a key. Deleting a missing key is a no-op.
+func (t *SparseTree) Delete(key string) {
+	kh := crypto.Hash([]byte(key))
+	t.root = remove(t.root, 0, kh[:])
+}
+
+// insert places leaf into the subtree n at the given depth and returns the new subtree.
+func insert(n *sparseNode, depth int, leaf *sparseNode) *sparseNode {
+	if n == nil {
+		return leaf
+	}
+	if n.isLeaf() {
+		if bytes.Equal(n.keyHash, leaf.keyHash) {
+			return leaf
+		}
+		return split(n, leaf, depth)
+	}
+
+	if bit(leaf.keyHash, depth) == 0 {
+		n.left = insert(n.left, depth+1, leaf)
+	} else {
+		n.right = insert(n.right, depth+1, leaf)
+	}
+	n.rehash()
+	return n
+}
+
+// split builds the internal nodes needed to hold two leaves whose key hashes
+// share the first `depth` bits.
+func split(a, b *sparseNode, depth int) *sparseNode {
+	n := &sparseNode{}
+	aBit, bBit := bit(a.keyHash, depth), bit(b.keyHash, depth)
+	switch {
+	case aBit != bBit && aBit == 0:
+		n.left, n.right = a, b
+	case aBit != bBit:
+		n.left, n.right = b, a
+	case aBit == 0:
+		n.left = split(a, b, depth+1)
+	default:
+		n.right = split(a, b, depth+1)
+	}
+	n.rehash()
+	return n
+}
+
+// remove deletes keyHash from the subtree n and returns the new subtree.
+// An internal node left with a single leaf below it collapses into that leaf.
+func remove(n *sparseNode, depth int, keyHash []byte) *sparseNode {
+	if n == nil {
+		return nil
+	}
+	if n.isLeaf() {
+		if bytes.Equal(n.keyHash, keyHash) {
+			return nil
+		}
+		return n
+	}
+
+	if bit(keyHash, depth) == 0 {
+		n.left = remove(n.left, depth+1, keyHash)
+	} else {
+		n.right = remove(n.right, depth+1, keyHash)
+	}
+
+	switch {
+	case n.left == nil && n.right == nil:
+		return nil
+	case n.left == nil && n.right.isLeaf():
+		return n.right
+	case n.right == nil && n.left.isLeaf():
+		return n.left
+	}
+	n.rehash()
+	return n
+}
+
+// GenerateProof creates an inclusion proof if the key is present and a
+// non-inclusion proof otherwise.
+func (t *SparseTree) GenerateProof(key string) *SparseProof {
+	kh := crypto.Hash([]byte(key))
+	keyHash := kh[:]
+	proof := &SparseProof{Key: key}
+
+	// 1. Walk down the path of keyHash, collecting siblings root first.
+	var siblings [][]byte
+	n := t.root
+	for depth := 0; n != nil && !n.isLeaf(); depth++ {
+		if bit(keyHash, depth) == 0 {
+			siblings = append(siblings, subtreeHash(n.right))
+			n = n.left
+		} else {
+			siblings = append(siblings, subtreeHash(n.left))
+			n = n.right
+		}
+	}
+
+	// 2. Record the terminal of the path.
+	switch {
+	case n == nil:
+		// Path ends in an empty subtree: the key is absent.
+	case bytes.Equal(n.keyHash, keyHash):
+		proof.Exists = true
+		proof.ValueHash = n.valueHash
+	default:
+		// Path ends in another key's leaf: the key is absent.
+		proof.LeafKeyHash = n.keyHash
+		proof.LeafValueHash = n.valueHash
+	}
+
+	// 3. Proofs list siblings deepest first.
+	proof.Siblings = make([][]byte, len(siblings))
+	for i, s := range siblings {
+		proof.Siblings[len(siblings)-1-i] = s
+	}
+	return proof
+}
+
+// bit returns the bit of h at the given depth, most significant bit first.
+func bit(h []byte, depth int) byte {
+	return (h[depth/8] >> (7 - uint(depth%8))) & 1
+}
+
+// sparseLeafHash computes the hash of a leaf in the sparse tree.
+// Leaves are positioned and keyed by the hash of the key.
+func sparseLeafHash(keyHash, valueHash []byte) []byte {
+	return LeafHash(keyHash, valueHash)
+}
+
+// emptySubtreeHash is the placeholder hash of a subtree with no leaves,
+// Hash(0x02). The prefix keeps it apart from leaf and node hashes.
+var emptySubtreeHash = func() []byte {
+	h := crypto.Hash([]byte{emptyHashPrefix})
+	return h[:]
+}()
+
+func emptySubtree() []byte {
+	return emptySubtreeHash
+}
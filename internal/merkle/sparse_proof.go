@@ -0,0 +1,78 @@
+package merkle
+
+import (
+	"bytes"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+)
+
+// keyHashBits is the maximum depth of a SparseTree (one level per bit of SHA-256).
+const keyHashBits = 256
+
+// SparseProof is an inclusion or non-inclusion proof against a SparseTree root.
+type SparseProof struct {
+	Key string
+	// Exists reports whether the proof shows the key as present.
+	Exists bool
+	// ValueHash is the value hash of Key. Only set when Exists is true.
+	ValueHash []byte
+	// Siblings along the path of Hash(Key), deepest first. The direction at
+	// each level is given by the bits of Hash(Key).
+	Siblings [][]byte
+	// LeafKeyHash and LeafValueHash describe the leaf of another key that
+	// occupies the path of Key. Only set for non-inclusion proofs that do not
+	// end in an empty subtree.
+	LeafKeyHash   []byte
+	LeafValueHash []byte
+}
+
+// Verify checks if the proof is valid for the given root.
+func (p *SparseProof) Verify(root []byte) bool {
+	kh := crypto.Hash([]byte(p.Key))
+	keyHash := kh[:]
+	depth := len(p.Siblings)
+	if depth > keyHashBits {
+		return false
+	}
+
+	// 1. Start with the terminal of the path.
+	var currentHash []byte
+	switch {
+	case p.Exists:
+		if p.LeafKeyHash != nil || p.LeafValueHash != nil {
+			return false
+		}
+		currentHash = sparseLeafHash(keyHash, p.ValueHash)
+	case p.LeafKeyHash != nil:
+		// The other leaf must sit on our path, i.e. share the first `depth`
+		// bits with our key hash, but must not be our key.
+		if len(p.LeafKeyHash) != len(keyHash) || bytes.Equal(p.LeafKeyHash, keyHash) {
+			return false
+		}
+		for d := 0; d < depth; d++ {
+			if bit(p.LeafKeyHash, d) != bit(keyHash, d) {
+				return false
+			}
+		}
+		currentHash = sparseLeafHash(p.LeafKeyHash, p.LeafValueHash)
+	default:
+		if p.LeafValueHash != nil {
+			return false
+		}
+		currentHash = emptySubtree()
+	}
+
+	// 2. Apply siblings up to the root.
+	for i, sibling := range p.Siblings {
+		d := depth - 1 - i
+		if bit(keyHash, d) == 1 {
+			// Sibling is Left, Current is Right
+			currentHash = NodeHash(sibling, currentHash)
+		} else {
+			// Sibling is Right, Current is Left
+			currentHash = NodeHash(currentHash, sibling)
+		}
+	}
+
+	return bytes.Equal(currentHash, root)
+}
@@ -0,0 +1,233 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+func TestSparseTree(t *testing.T) {
+	kv := map[string][]byte{}
+	for i := 0; i < 50; i++ {
+		kv[fmt.Sprintf("key%d", i)] = []byte(fmt.Sprintf("value%d", i))
+	}
+	tree := NewSparseTree(kv)
+	root := tree.Root()
+	if len(root) != 32 {
+		t.Fatalf("expected root length 32, got %d", len(root))
+	}
+
+	// 1. Inclusion proofs
+	for k, v := range kv {
+		proof := tree.GenerateProof(k)
+		if !proof.Exists {
+			t.Fatalf("expected %s to exist", k)
+		}
+		if !bytes.Equal(proof.ValueHash, v) {
+			t.Errorf("proof value hash mismatch for %s", k)
+		}
+		if !proof.Verify(root) {
+			t.Errorf("inclusion proof failed for %s", k)
+		}
+	}
+
+	// 2. Non-inclusion proofs
+	for i := 0; i < 50; i++ {
+		k := fmt.Sprintf("missing%d", i)
+		proof := tree.GenerateProof(k)
+		if proof.Exists {
+			t.Fatalf("expected %s to be absent", k)
+		}
+		if !proof.Verify(root) {
+			t.Errorf("non-inclusion proof failed for %s", k)
+		}
+	}
+
+	// 3. Root is independent of insertion order
+	if !bytes.Equal(NewSparseTree(kv).Root(), root) {
+		t.Error("root is not deterministic")
+	}
+}
+
+// TestSparseTreeVectors pins roots of TreeFormatVersion 2. The keys "b" and
+// "c" share a 3-bit prefix, so their root also hashes empty subtrees.
+func TestSparseTreeVectors(t *testing.T) {
+	vectors := []struct {
+		kv   map[string][]byte
+		root string
+	}{
+		{map[string][]byte{}, "dbc1b4c900ffe48d575b5da5c638040125f65db0fe3e24494b76ea986457d986"},
+		{map[string][]byte{"a": []byte("1")}, "9148dfdbd6368ee8901ae923dd7e2899f7849dbba2fc625b7f4e74abeec92e3e"},
+		{map[string][]byte{"a": []byte("1"), "b": []byte("2"), "c": []byte("3")}, "93f356ea2488d6764124358b19b69c04832ee8fe9a3bb5466caf753e8bed5f7f"},
+	}
+	for _, v := range vectors {
+		if root := hex.EncodeToString(NewSparseTree(v.kv).Root()); root != v.root {
+			t.Errorf("root of %d keys = %s, expected %s", len(v.kv), root, v.root)
+		}
+	}
+}
+
+func TestSparseTree_Empty(t *testing.T) {
+	tree := NewSparseTree(map[string][]byte{})
+	if !bytes.Equal(tree.Root(), emptySubtree()) {
+		t.Error("expected empty hash for empty tree")
+	}
+	proof := tree.GenerateProof("k")
+	if proof.Exists || !proof.Verify(tree.Root()) {
+		t.Error("non-inclusion proof failed for empty tree")
+	}
+}
+
+func TestSparseTree_Tamper(t *testing.T) {
+	kv := map[string][]byte{
+		"a": []byte("1"),
+		"b": []byte("2"),
+		"c": []byte("3"),
+	}
+	tree := NewSparseTree(kv)
+	root := tree.Root()
+
+	// 1. Claiming a present key is absent must fail.
+	proof := tree.GenerateProof("a")
+	proof.Exists = false
+	proof.ValueHash = nil
+	if proof.Verify(root) {
+		t.Error("absent claim verified for present key")
+	}
+
+	// 2. Claiming an absent key is present must fail.
+	proof = tree.GenerateProof("missing")
+	proof.Exists = true
+	proof.ValueHash = []byte("1")
+	proof.LeafKeyHash, proof.LeafValueHash = nil, nil
+	if proof.Verify(root) {
+		t.Error("inclusion verified for absent key")
+	}
+
+	// 3. Tampered value hash must fail.
+	proof = tree.GenerateProof("b")
+	proof.ValueHash = []byte("x")
+	if proof.Verify(root) {
+		t.Error("proof verified with tampered ValueHash")
+	}
+
+	// 4. Tampered sibling must fail.
+	proof = tree.GenerateProof("c")
+	if len(proof.Siblings) > 0 {
+		proof.Siblings[0] = append([]byte{}, proof.Siblings[0]...)
+		proof.Siblings[0][0] ^= 0xFF
+		if proof.Verify(root) {
+			t.Error("proof verified with tampered Sibling")
+		}
+	}
+
+	// 5. Proof against a different root must fail.
+	kv["a"] = []byte("changed")
+	if tree.GenerateProof("a").Verify(NewSparseTree(kv).Root()) {
+		t.Error("proof verified against stale root")
+	}
+}
+
+func TestSparseTree_Incremental(t *testing.T) {
+	kv := map[string][]byte{}
+	tree := NewSparseTree(nil)
+
+	// Interleave sets, overwrites and deletes, checking against a full rebuild.
+	for i := 0; i < 300; i++ {
+		k := fmt.Sprintf("key%d", i%97)
+		if i%5 == 4 {
+			delete(kv, k)
+			tree.Delete(k)
+		} else {
+			v := []byte(fmt.Sprintf("value%d", i))
+			kv[k] = v
+			tree.Set(k, v)
+		}
+
+		if !bytes.Equal(tree.Root(), NewSparseTree(kv).Root()) {
+			t.Fatalf("incremental root diverged from rebuild after step %d", i)
+		}
+	}
+
+	for k := range kv {
+		if !tree.GenerateProof(k).Verify(tree.Root()) {
+			t.Errorf("proof failed for %s", k)
+		}
+	}
+
+	// Deleting everything returns to the empty root.
+	for k := range kv {
+		tree.Delete(k)
+	}
+	tree.Delete("never-set")
+	if !bytes.Equal(tree.Root(), emptySubtree()) {
+		t.Error("expected empty root after deleting all keys")
+	}
+}
+
+func benchmarkKV(n int) map[string][]byte {
+	kv := make(map[string][]byte, n)
+	for i := 0; i < n; i++ {
+		kv[fmt.Sprintf("flags/feature-%d", i)] = []byte(fmt.Sprintf("value%d", i))
+	}
+	return kv
+}
+
+var benchmarkSizes = []int{1000, 10000, 50000}
+
+// BenchmarkRebuild measures the cost of one SET followed by Root() when the
+// tree is rebuilt from the full key-value map, as StateMachine used to do.
+func BenchmarkRebuild(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		kv := benchmarkKV(n)
+		b.Run(fmt.Sprintf("Tree/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				kv["flags/feature-0"] = []byte(fmt.Sprintf("v%d", i))
+				NewTree(kv).Root()
+			}
+		})
+		b.Run(fmt.Sprintf("SparseTree/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				kv["flags/feature-0"] = []byte(fmt.Sprintf("v%d", i))
+				NewSparseTree(kv).Root()
+			}
+		})
+	}
+}
+
+// BenchmarkIncremental measures the cost of one SET followed by Root() on a
+// persistent SparseTree.
+func BenchmarkIncremental(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		tree := NewSparseTree(benchmarkKV(n))
+		b.Run(fmt.Sprintf("SparseTree/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				tree.Set("flags/feature-0", []byte(fmt.Sprintf("v%d", i)))
+				tree.Root()
+			}
+		})
+	}
+}
+
+// BenchmarkProof compares proof generation on the rebuilt Tree with the
+// cached SparseTree.
+func BenchmarkProof(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		kv := benchmarkKV(n)
+		tree := NewTree(kv)
+		b.Run(fmt.Sprintf("Tree/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := tree.GenerateProof("flags/feature-0"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		sparse := NewSparseTree(kv)
+		b.Run(fmt.Sprintf("SparseTree/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sparse.GenerateProof("flags/feature-0")
+			}
+		})
+	}
+}
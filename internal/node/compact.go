@@ -0,0 +1,82 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/rrb115/vdcs/internal/log"
+	"github.com/rrb115/vdcs/internal/snapshot"
+)
+
+// Compact removes the entries before index before from the store and from
+// memory. If archiveDir is not empty, the store moves them there instead of
+// deleting them, for audits.
+//
+// It first saves a signed snapshot of the current state, and keeps the entry
+// the snapshot was taken at, so that the node can restart from it. The
+// snapshot also holds what the log keeps of the removed entries: the hash of
+// the last one and the frontier of the log tree over them. Roots, inclusion
+// proofs for later entries and consistency proofs from later sizes still
+// work. It returns the new first index. The store may keep some of the
+// entries before it, e.g. because a FileStore removes whole segments, but
+// the node no longer serves them.
+func (n *Node) Compact(before uint64, archiveDir string) (uint64, error) {
+	if n.snapshots == nil {
+		return 0, ErrSnapshotsDisabled
+	}
+	n.snapMu.Lock()
+	defer n.snapMu.Unlock()
+
+	// 1. Snapshot the current state, with the frontier of the entries to
+	// remove, before removing them
+	n.mu.RLock()
+	snap, err := n.capture()
+	if err == nil && snap != nil {
+		before = max(min(before, snap.Version), n.log.FirstIndex())
+		snap.Compacted, err = n.log.Compaction(before)
+	}
+	n.mu.RUnlock()
+	if err != nil || snap == nil {
+		return 0, err
+	}
+	if before == n.log.FirstIndex() {
+		return before, nil
+	}
+	if err := n.writeSnapshot(snap); err != nil {
+		return 0, err
+	}
+
+	// 2. Remove them from memory, then from the store, which the log may
+	// still read the last of them from
+	if err := n.log.Compact(before); err != nil {
+		return 0, err
+	}
+	if _, err := n.store.Compact(before, archiveDir); err != nil {
+		return 0, fmt.Errorf("failed to compact store: %w", err)
+	}
+	return before, nil
+}
+
+// restoreCompacted starts the log with what snap keeps of the compacted
+// entries, if any, and returns the index of the first entry after them. The
+// store must hold every entry from there on; restoring snap checks that
+// they lead to its log root.
+func (n *Node) restoreCompacted(snap *snapshot.Snapshot) (uint64, error) {
+	first, err := n.store.FirstIndex()
+	if err != nil {
+		return 0, err
+	}
+	if snap == nil || snap.Compacted == nil {
+		if first > 0 {
+			return 0, fmt.Errorf("%w: the store starts at index %d, and no snapshot restores the entries before it", log.ErrCompacted, first)
+		}
+		return 0, nil
+	}
+	c := snap.Compacted
+	if first > c.Size {
+		return 0, fmt.Errorf("%w: the store starts at index %d, but the snapshot only restores the entries before %d", log.ErrCompacted, first, c.Size)
+	}
+	if err := n.log.RestoreCompacted(c); err != nil {
+		return 0, fmt.Errorf("failed to restore compacted entries: %w", err)
+	}
+	return c.Size, nil
+}
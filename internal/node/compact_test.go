@@ -0,0 +1,99 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rrb115/vdcs/internal/log"
+	"github.com/rrb115/vdcs/internal/storage"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+func TestNodeCompact(t *testing.T) {
+	dir := t.TempDir()
+	snapDir := filepath.Join(dir, "snapshots")
+	archive := filepath.Join(dir, "archive")
+	cfg, priv := newTestConfig(t)
+	cfg.SnapshotDir = snapDir
+
+	// Every entry fills a segment, so compaction removes exactly the
+	// entries asked for.
+	start := func(verify bool) (*Node, error) {
+		cfg.VerifySnapshots = verify
+		return openTestNode(t, filepath.Join(dir, "log.bin"), storage.FileStoreOptions{SegmentSize: 1}, cfg)
+	}
+	set := func(n *Node, k, value string) {
+		if _, err := n.ProposeEntry(signedEntry(t, n, "admin", priv, vdcspb.Operation_OPERATION_SET, k, []byte(value))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 1. Compact the first entries of a log of 5
+	node, err := start(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"a", "b", "c", "d", "e"} {
+		set(node, "key-"+v, v)
+	}
+	proof, _ := node.GetConsistencyProof(3, 5)
+	first, err := node.Compact(3, archive)
+	if err != nil || first != 3 {
+		t.Fatalf("Compact(3) = %d, %v", first, err)
+	}
+	if node.FirstIndex() != 3 {
+		t.Errorf("expected first index 3, got %d", node.FirstIndex())
+	}
+	if _, _, err := node.GetEntries(0, 0, 0); !errors.Is(err, log.ErrCompacted) {
+		t.Errorf("expected ErrCompacted, got %v", err)
+	}
+	if entries, _, err := node.GetEntries(3, 0, 0); err != nil || len(entries) != 2 {
+		t.Errorf("GetEntries(3) = %v, %v", entries, err)
+	}
+	if files, _ := os.ReadDir(archive); len(files) != 3 {
+		t.Errorf("expected 3 archived segments, got %d", len(files))
+	}
+	set(node, "key-f", "f")
+	head := node.GetLatestRoot()
+	node.Close()
+
+	// 2. A restart restores the frontier and the snapshot, and proofs from
+	// the compaction point on still verify
+	node, err = start(false)
+	if err != nil {
+		t.Fatalf("failed to restart: %v", err)
+	}
+	restarted := node.GetLatestRoot()
+	if !bytes.Equal(restarted.StateRoot, head.StateRoot) || !bytes.Equal(restarted.LogRoot, head.LogRoot) || restarted.LogSize != head.LogSize {
+		t.Errorf("restarted head %+v differs from %+v", restarted, head)
+	}
+	if node.FirstIndex() != 3 {
+		t.Errorf("expected first index 3 after restart, got %d", node.FirstIndex())
+	}
+	if value, _ := node.state.GetValue("key-a"); string(value) != "a" {
+		t.Errorf("expected a, got %q", value)
+	}
+	p, err := node.GetConsistencyProof(3, 5)
+	if err != nil || !bytes.Equal(bytes.Join(p, nil), bytes.Join(proof, nil)) {
+		t.Errorf("consistency proof changed: %x, %v", p, err)
+	}
+	if _, err := node.GetConsistencyProof(2, 5); !errors.Is(err, log.ErrCompacted) {
+		t.Errorf("expected ErrCompacted for a proof from before the compaction, got %v", err)
+	}
+	set(node, "key-g", "g")
+	node.Close()
+
+	// 3. The full log is gone, so it can no longer be verified
+	if _, err := start(true); !errors.Is(err, log.ErrCompacted) {
+		t.Errorf("expected ErrCompacted, got %v", err)
+	}
+
+	// 4. Without the snapshots, the node refuses to start
+	os.RemoveAll(snapDir)
+	if _, err := start(false); !errors.Is(err, log.ErrCompacted) {
+		t.Errorf("expected ErrCompacted without snapshots, got %v", err)
+	}
+}
@@ -1,11 +1,19 @@
 package node
 
 import (
+	"crypto/ed25519"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/rrb115/vdcs/internal/checkpoint"
+	"github.com/rrb115/vdcs/internal/crypto"
 	"github.com/rrb115/vdcs/internal/log"
 	"github.com/rrb115/vdcs/internal/merkle"
+	"github.com/rrb115/vdcs/internal/policy"
+	"github.com/rrb115/vdcs/internal/snapshot"
 	"github.com/rrb115/vdcs/internal/state"
 	"github.com/rrb115/vdcs/internal/storage"
 	vdcspb "github.com/rrb115/vdcs/proto"
@@ -18,12 +26,62 @@ type Node struct {
 	state       *state.StateMachine
 	store       storage.Store
 	trustedKeys map[string][]byte
+	signingKey  ed25519.PrivateKey
+	origin      string
+	mmd         time.Duration
+	changed     chan struct{} // Closed and replaced on every commit
+	proposals   *ProposalStore
+
+	snapMu      sync.Mutex    // Serializes Snapshot
+	snapshots   *snapshot.Dir // Nil if snapshots are disabled
+	genesis     []byte        // Digest of the trusted keys and policy, see snapshot.Genesis
+	snapshotted uint64        // Log size at the last snapshot written or restored
+	restored    *snapshot.Snapshot
 }
 
+// DefaultOrigin is the checkpoint origin used when Config.Origin is empty.
+const DefaultOrigin = "vdcs"
+
+// DefaultMaxMergeDelay is the merge delay promised in receipts when
+// Config.MaxMergeDelay is zero. Entries are added to the log tree before the
+// receipt is signed, so this only has to cover clock skew and restarts.
+const DefaultMaxMergeDelay = 5 * time.Minute
+
+// DefaultMaxClockSkew is how far the timestamp of a proposed entry may be
+// from the node's clock when Config.MaxClockSkew is zero.
+const DefaultMaxClockSkew = 5 * time.Minute
+
 // Config holds node configuration.
 type Config struct {
-	Store       storage.Store
+	Store storage.Store
+	// TrustedKeys are the authors trusted at genesis. Governance entries in
+	// the log add, revoke and rotate authors from there, so a node must be
+	// restarted with the same keys to replay its log.
 	TrustedKeys map[string][]byte // AuthorID -> PubKey
+	// SigningKey is the node's own Ed25519 identity, used to sign checkpoints.
+	SigningKey ed25519.PrivateKey
+	// Origin names the log in signed checkpoints. Defaults to DefaultOrigin.
+	Origin string
+	// MaxMergeDelay is the longest time, promised in every receipt, before
+	// an accepted entry appears in a signed checkpoint. It is rounded down
+	// to whole seconds. Defaults to DefaultMaxMergeDelay.
+	MaxMergeDelay time.Duration
+	// MaxClockSkew is how far the timestamp of a proposed entry may be from
+	// the node's clock. Replay does not check it. Defaults to
+	// DefaultMaxClockSkew.
+	MaxClockSkew time.Duration
+	// Policy restricts which keys each author may write. It is enforced on
+	// new entries and on replay, until an UPDATE_POLICY entry replaces it.
+	// Nil allows every trusted author to write any data key, and only the
+	// TrustedKeys to append governance entries.
+	Policy *policy.Policy
+	// SnapshotDir is where Snapshot saves signed snapshots of the state.
+	// NewNode restores the latest one it can use and only replays the
+	// entries after it. Empty disables snapshots.
+	SnapshotDir string
+	// VerifySnapshots makes NewNode replay the whole log, and check every
+	// snapshot in SnapshotDir against the state replayed up to its index.
+	VerifySnapshots bool
 }
 
 // NewNode initializes a new node.
@@ -33,22 +91,78 @@ func NewNode(cfg Config) (*Node, error) {
 	for id, key := range cfg.TrustedKeys {
 		l.AddTrustedAuthor(id, key)
 	}
+	l.SetPolicy(cfg.Policy)
 
 	sm := state.NewStateMachine()
 
 	if cfg.Store == nil {
 		return nil, fmt.Errorf("storage store is required in config")
 	}
+	l.SetStore(cfg.Store)
+	if len(cfg.SigningKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("signing key is required in config")
+	}
+	origin := cfg.Origin
+	if origin == "" {
+		origin = DefaultOrigin
+	}
+	if strings.ContainsAny(origin, "\n") {
+		return nil, fmt.Errorf("origin must be a single line")
+	}
+	// The node signs sequenced entries under its origin.
+	if _, ok := cfg.TrustedKeys[origin]; ok {
+		return nil, fmt.Errorf("origin %s is also a trusted author", origin)
+	}
+	l.AddSequencer(origin, cfg.SigningKey.Public().(ed25519.PublicKey))
+	mmd := cfg.MaxMergeDelay.Truncate(time.Second)
+	if mmd < 0 {
+		return nil, fmt.Errorf("max merge delay must not be negative")
+	}
+	if mmd == 0 {
+		mmd = DefaultMaxMergeDelay
+	}
+	skew := cfg.MaxClockSkew
+	if skew < 0 {
+		return nil, fmt.Errorf("max clock skew must not be negative")
+	}
+	if skew == 0 {
+		skew = DefaultMaxClockSkew
+	}
+	l.SetTimestampRules(log.TimestampRules{MaxSkew: skew})
 
 	n := &Node{
 		log:         l,
 		state:       sm,
 		store:       cfg.Store,
 		trustedKeys: cfg.TrustedKeys,
+		signingKey:  cfg.SigningKey,
+		origin:      origin,
+		mmd:         mmd,
+		changed:     make(chan struct{}),
+		proposals:   NewProposalStore(),
+	}
+	if cfg.SnapshotDir != "" {
+		dir, err := snapshot.OpenDir(cfg.SnapshotDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open snapshot dir: %w", err)
+		}
+		genesis, err := snapshot.Genesis(cfg.TrustedKeys, cfg.Policy)
+		if err != nil {
+			return nil, err
+		}
+		n.snapshots, n.genesis = dir, genesis
+	} else if cfg.VerifySnapshots {
+		return nil, fmt.Errorf("verifying snapshots needs a snapshot dir")
 	}
 
-	// 2. Replay log
-	if err := n.replay(); err != nil {
+	// 2. Restore a snapshot and replay the log after it
+	var err error
+	if cfg.VerifySnapshots {
+		err = n.verifySnapshots()
+	} else {
+		err = n.load()
+	}
+	if err != nil {
 		// Do not close store here as it was passed in. caller handles lifecycle.
 		// However, NewNode failing implies we shouldn't use the node.
 		return nil, fmt.Errorf("failed to replay log: %w", err)
@@ -57,28 +171,68 @@ func NewNode(cfg Config) (*Node, error) {
 	return n, nil
 }
 
-// replay loads all entries from disk and applies them.
-func (n *Node) replay() error {
-	entries, err := n.store.LoadAll()
+// load restores the latest usable snapshot, if any, then replays the entries
+// after it.
+func (n *Node) load() error {
+	snap, err := n.latestSnapshot()
 	if err != nil {
 		return err
 	}
+	first, err := n.restoreCompacted(snap)
+	if err != nil {
+		return err
+	}
+	if snap == nil {
+		return n.replay(nil)
+	}
+	return n.store.Iterate(first, func(entry *vdcspb.ConfigEntry) error {
+		if entry.Index > snap.Version {
+			return n.replayEntry(entry)
+		}
+		// The snapshot covers the entry: only check that it chains up to
+		// the snapshot's head.
+		if err := n.log.Restore(entry); err != nil {
+			return fmt.Errorf("restore failed at index %d: %w", entry.Index, err)
+		}
+		if entry.Index == snap.Version {
+			return n.restore(snap)
+		}
+		return nil
+	})
+}
 
-	for _, entry := range entries {
-		// We trust the disk content implies it was validated when written?
-		// Or should we re-validate?
-		// Ideally re-validate to detect corruption.
-		if err := n.log.Append(entry); err != nil {
-			return fmt.Errorf("replay validation failed at index %d: %w", entry.Index, err)
+// replay streams all entries from disk and applies them. If fn is not nil,
+// it is called after each entry.
+func (n *Node) replay(fn func(*vdcspb.ConfigEntry) error) error {
+	return n.store.Iterate(0, func(entry *vdcspb.ConfigEntry) error {
+		if err := n.replayEntry(entry); err != nil {
+			return err
+		}
+		if fn != nil {
+			return fn(entry)
 		}
-		n.state.Apply(entry)
+		return nil
+	})
+}
+
+// replayEntry validates and applies an entry read back from disk.
+func (n *Node) replayEntry(entry *vdcspb.ConfigEntry) error {
+	// We trust the disk content implies it was validated when written?
+	// Or should we re-validate?
+	// Ideally re-validate to detect corruption.
+	if err := n.state.CheckPreconditions(entry); err != nil {
+		return fmt.Errorf("replay validation failed at index %d: %w", entry.Index, err)
+	}
+	if err := n.log.Replay(entry); err != nil {
+		return fmt.Errorf("replay validation failed at index %d: %w", entry.Index, err)
 	}
+	n.state.Apply(entry)
 	return nil
 }
 
-// ProposeEntry adds a new configuration entry.
+// ProposeEntry adds a new configuration entry and returns a receipt for it.
 // For now, this is a direct operation. In Raft, this would Propose to the cluster.
-func (n *Node) ProposeEntry(entry *vdcspb.ConfigEntry) error {
+func (n *Node) ProposeEntry(entry *vdcspb.ConfigEntry) (*checkpoint.SignedReceipt, error) {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
@@ -86,11 +240,77 @@ func (n *Node) ProposeEntry(entry *vdcspb.ConfigEntry) error {
 	// The AUTHOR should sign the index.
 	// If the Author doesn't know the index, they can't sign it.
 	// This implies the client must query HEAD, get index+1, sign, and submit.
-	// Optimistic concurrency.
+	// Optimistic concurrency. Authors who do not want to race on the index
+	// use ProposeIntent instead.
+	return n.commit(entry)
+}
+
+// ProposeIntent sequences an intent signed by an author: the node assigns
+// the next index and previous hash, signs the wrapping entry with its own
+// key, and appends it. It returns the appended entry and a receipt for it.
+func (n *Node) ProposeIntent(intent *vdcspb.Intent) (*vdcspb.ConfigEntry, *checkpoint.SignedReceipt, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.sequence(intent)
+}
+
+// sequence wraps an intent in an entry signed by the node and commits it.
+// Callers must hold n.mu.
+func (n *Node) sequence(intent *vdcspb.Intent) (*vdcspb.ConfigEntry, *checkpoint.SignedReceipt, error) {
+	// 1. Wrap the Intent
+	// Timestamps must not decrease, even if an author's clock ran ahead of
+	// the node's.
+	index := n.log.Size()
+	var prevHash []byte
+	timestamp := time.Now().UnixNano()
+	if index > 0 {
+		last, err := n.log.Get(index - 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		prevHash = last.EntryHash
+		timestamp = max(timestamp, last.Timestamp)
+	}
+	entry := &vdcspb.ConfigEntry{
+		Index:        index,
+		Timestamp:    timestamp,
+		AuthorId:     n.origin,
+		Key:          intent.Key,
+		ValueHash:    intent.ValueHash,
+		Operation:    intent.Operation,
+		PrevHash:     prevHash,
+		Value:        intent.Value,
+		Precondition: intent.Precondition,
+		Intent:       intent,
+	}
+	hash, err := log.ComputeEntryHash(entry)
+	if err != nil {
+		return nil, nil, err
+	}
+	entry.EntryHash = hash
+	entry.Signature = crypto.Sign(n.signingKey, hash)
+
+	// 2. Commit like any other entry. The Log checks the author's signature
+	// on the intent, its nonce and its expiry.
+	receipt, err := n.commit(entry)
+	if err != nil {
+		return nil, nil, err
+	}
+	return entry, receipt, nil
+}
+
+// commit validates, persists and applies an entry, and returns a receipt for
+// it. Callers must hold n.mu.
+func (n *Node) commit(entry *vdcspb.ConfigEntry) (*checkpoint.SignedReceipt, error) {
+	// 1. Check Preconditions against the current State.
+	// Returns a *state.PreconditionError if one does not hold.
+	if err := n.state.CheckPreconditions(entry); err != nil {
+		return nil, err
+	}
 
 	// 2. Validate against Log
 	if err := n.log.Append(entry); err != nil {
-		return err
+		return nil, err
 	}
 
 	// 3. Persist
@@ -100,23 +320,60 @@ func (n *Node) ProposeEntry(entry *vdcspb.ConfigEntry) error {
 		// Rollback is hard. Panic is safer.
 		// Or careful Log structure.
 		// For v1, let's just return error and assume the node might need restart if critical.
-		return fmt.Errorf("failed to persist: %w", err)
+		return nil, fmt.Errorf("failed to persist: %w", err)
 	}
 
 	// 4. Apply to State
 	n.state.Apply(entry)
 
-	return nil
+	// 5. Wake up watchers
+	close(n.changed)
+	n.changed = make(chan struct{})
+
+	// 6. Sign Receipt
+	return checkpoint.SignReceipt(&checkpoint.Receipt{
+		Origin:        n.origin,
+		Index:         entry.Index,
+		EntryHash:     entry.EntryHash,
+		StateRoot:     n.state.Root(),
+		Timestamp:     time.Now().UnixNano(),
+		MaxMergeDelay: n.mmd,
+	}, n.signingKey), nil
 }
 
-// GetLatestRoot returns the current Merkle root, version, and head entry hash.
-func (n *Node) GetLatestRoot() (uint64, []byte, []byte) {
+// Head describes the latest committed state of the node.
+type Head struct {
+	Version       uint64 // Last applied index
+	StateRoot     []byte // Root of the state Merkle tree
+	LastEntryHash []byte // EntryHash of the last log entry (nil for an empty log)
+	LogSize       uint64 // Number of entries in the log
+	LogRoot       []byte // Root of the log Merkle tree
+	// Checkpoint is the node's signature over LogSize, LogRoot and StateRoot.
+	Checkpoint *checkpoint.Signed
+}
+
+// GetLatestRoot returns the current state root, log root, version, and head entry hash.
+func (n *Node) GetLatestRoot() Head {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
+	return n.head()
+}
 
+// head builds the signed Head. Callers must hold n.mu.
+func (n *Node) head() Head {
 	// Get version from State
-	ver := n.state.Version()
-	root := n.state.Root()
+	head := Head{
+		Version:   n.state.Version(),
+		StateRoot: n.state.Root(),
+	}
+	head.LogSize, head.LogRoot = n.log.Root()
+	head.Checkpoint = checkpoint.Sign(&checkpoint.Checkpoint{
+		Origin:    n.origin,
+		LogSize:   head.LogSize,
+		LogRoot:   head.LogRoot,
+		StateRoot: head.StateRoot,
+		Timestamp: time.Now().UnixNano(),
+	}, n.signingKey)
 
 	// Get Head Hash from Log
 	// Log stores entries. Head is at index = ver.
@@ -128,27 +385,119 @@ func (n *Node) GetLatestRoot() (uint64, []byte, []byte) {
 	// If log is empty (Version 0 implies genesis? Or empty?)
 	// My Log logic: "nextIndex" starts at 0.
 	// If Log size is 0, no head.
-	size := n.log.Size()
-
-	if size == 0 {
-		return 0, root, nil // Genesis state
+	if head.LogSize == 0 {
+		return head // Genesis state
 	}
 
 	// Head is at size-1
-	headEntry, err := n.log.Get(size - 1)
+	headEntry, err := n.log.Get(head.LogSize - 1)
 	if err != nil {
 		// Should not happen if size > 0
-		return ver, root, nil
+		return head
+	}
+
+	head.LastEntryHash = headEntry.EntryHash
+	return head
+}
+
+// Changes returns a channel that is closed when the next entry is committed.
+// Watchers take the channel before reading the head so they do not miss a
+// commit in between.
+func (n *Node) Changes() <-chan struct{} {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.changed
+}
+
+// GetEntry returns the committed entry at index.
+func (n *Node) GetEntry(index uint64) (*vdcspb.ConfigEntry, error) {
+	return n.log.Get(index)
+}
+
+// FirstIndex returns the index of the first entry the node still serves.
+// Entries before it were compacted, see Compact.
+func (n *Node) FirstIndex() uint64 {
+	return n.log.FirstIndex()
+}
+
+// Page sizes for GetEntries.
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// GetEntries returns up to pageSize entries with indexes in [start, end), and
+// the size of the log they were read from. An end of 0 means the end of the
+// log. A pageSize of 0 means DefaultPageSize, and it is capped at MaxPageSize.
+// Entries are read from the store, not from memory. Ranges that start before
+// FirstIndex return an error wrapping log.ErrCompacted.
+func (n *Node) GetEntries(start, end uint64, pageSize int) ([]*vdcspb.ConfigEntry, uint64, error) {
+	// Commits hold n.mu while they append to the log and the store, so
+	// under it the store holds every entry below size.
+	n.mu.RLock()
+	size, first := n.log.Size(), n.log.FirstIndex()
+	n.mu.RUnlock()
+
+	if end == 0 {
+		end = size
+	}
+	if start > end || end > size {
+		return nil, size, fmt.Errorf("%w: range [%d, %d) of log of size %d", log.ErrInvalidIndex, start, end, size)
+	}
+	if start < first {
+		return nil, size, fmt.Errorf("%w: entries before %d are no longer served", log.ErrCompacted, first)
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	pageSize = min(pageSize, MaxPageSize)
+	end = min(end, start+uint64(pageSize))
+
+	entries, err := n.store.ReadRange(start, end)
+	if errors.Is(err, storage.ErrNotFound) {
+		// The entries may have been compacted since.
+		if first, ferr := n.store.FirstIndex(); ferr == nil && start < first {
+			return nil, size, fmt.Errorf("%w: entries before %d are no longer served", log.ErrCompacted, first)
+		}
+	}
+	if err != nil {
+		return nil, size, fmt.Errorf("failed to read entries: %w", err)
 	}
+	return entries, size, nil
+}
+
+// GetValue returns the value of a key with a proof against the returned Head.
+// ok is false if the key is absent or was set without an inline value, and
+// true if value holds it, even when it is empty.
+func (n *Node) GetValue(key string) (Head, []byte, bool, *merkle.SparseProof) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
 
-	return ver, root, headEntry.EntryHash
+	value, ok := n.state.GetValue(key)
+	return n.head(), value, ok, n.state.Prove(key)
 }
 
-// GetProof returns a proof for a key.
-func (n *Node) GetProof(key string) (*merkle.Proof, error) {
+// PublicKey returns the node's public key, used to verify its checkpoints.
+func (n *Node) PublicKey() ed25519.PublicKey {
+	return n.signingKey.Public().(ed25519.PublicKey)
+}
+
+// GetProof returns an inclusion proof for a key, or a non-inclusion proof if
+// the key is not set.
+func (n *Node) GetProof(key string) *merkle.SparseProof {
 	return n.state.Prove(key)
 }
 
+// GetInclusionProof proves that the entry at index is part of the log of the given size.
+func (n *Node) GetInclusionProof(index, size uint64) ([][]byte, error) {
+	return n.log.InclusionProof(index, size)
+}
+
+// GetConsistencyProof proves that the log of size second extends the log of size first.
+func (n *Node) GetConsistencyProof(first, second uint64) ([][]byte, error) {
+	return n.log.ConsistencyProof(first, second)
+}
+
 // Close shuts down the node.
 func (n *Node) Close() error {
 	return n.store.Close()
@@ -2,6 +2,8 @@ package node
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -9,10 +11,114 @@ import (
 
 	"github.com/rrb115/vdcs/internal/crypto"
 	"github.com/rrb115/vdcs/internal/log"
+	"github.com/rrb115/vdcs/internal/state"
 	"github.com/rrb115/vdcs/internal/storage"
 	vdcspb "github.com/rrb115/vdcs/proto"
 )
 
+// newTestConfig returns a Config that trusts the author "admin", with a new
+// node key, and the private key of admin.
+func newTestConfig(t *testing.T) (Config, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, nodeKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Config{TrustedKeys: map[string][]byte{"admin": pub}, SigningKey: nodeKey}, priv
+}
+
+// openTestNode starts a node with cfg on the FileStore at path, opened with
+// opts. It closes the store again if the node fails to start.
+func openTestNode(t *testing.T, path string, opts storage.FileStoreOptions, cfg Config) (*Node, error) {
+	t.Helper()
+	st, err := storage.OpenFileStore(path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Store = st
+	n, err := NewNode(cfg)
+	if err != nil {
+		st.Close()
+	}
+	return n, err
+}
+
+// newTestNode starts a node with cfg on a new FileStore, and closes it when
+// the test ends.
+func newTestNode(t *testing.T, cfg Config) *Node {
+	t.Helper()
+	n, err := openTestNode(t, filepath.Join(t.TempDir(), "log.bin"), storage.FileStoreOptions{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { n.Close() })
+	return n
+}
+
+// signedEntry returns the next entry of the log of n, in which author
+// applies op to key with value, signed with priv.
+func signedEntry(t *testing.T, n *Node, author string, priv ed25519.PrivateKey, op vdcspb.Operation, key string, value []byte) *vdcspb.ConfigEntry {
+	t.Helper()
+	e := &vdcspb.ConfigEntry{
+		Index:     n.log.Size(),
+		Timestamp: time.Now().UnixNano(),
+		AuthorId:  author,
+		Key:       key,
+		Operation: op,
+		Value:     value,
+	}
+	if value != nil {
+		h := crypto.Hash(value)
+		e.ValueHash = h[:]
+	}
+	if e.Index > 0 {
+		last, err := n.log.Get(e.Index - 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.PrevHash = last.EntryHash
+	}
+	return signEntry(t, e, priv)
+}
+
+// signEntry sets the EntryHash of e, signs it with priv and returns it.
+func signEntry(t *testing.T, e *vdcspb.ConfigEntry, priv ed25519.PrivateKey) *vdcspb.ConfigEntry {
+	t.Helper()
+	h, err := log.ComputeEntryHash(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.EntryHash = h
+	e.Signature = crypto.Sign(priv, h)
+	return e
+}
+
+// signedIntent returns an intent by author to set key to value, with nonce,
+// that expires after ttl, signed with priv.
+func signedIntent(t *testing.T, author string, priv ed25519.PrivateKey, key, value, nonce string, ttl time.Duration) *vdcspb.Intent {
+	t.Helper()
+	h := crypto.Hash([]byte(value))
+	intent := &vdcspb.Intent{
+		AuthorId:  author,
+		Key:       key,
+		Operation: vdcspb.Operation_OPERATION_SET,
+		ValueHash: h[:],
+		Value:     []byte(value),
+		Nonce:     []byte(nonce),
+		ExpiresAt: time.Now().Add(ttl).UnixNano(),
+	}
+	hash, err := log.ComputeIntentHash(intent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intent.Signature = crypto.Sign(priv, hash)
+	return intent
+}
+
 func TestNodeLifecycle(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "vdcs-node-test")
 	if err != nil {
@@ -28,6 +134,7 @@ func TestNodeLifecycle(t *testing.T) {
 	trustedKeys := map[string][]byte{
 		authorID: pub,
 	}
+	_, nodeKey, _ := crypto.GenerateKey()
 
 	// 1. Start Node 1
 	st, err := storage.NewFileStore(storagePath)
@@ -37,6 +144,7 @@ func TestNodeLifecycle(t *testing.T) {
 	cfg := Config{
 		Store:       st,
 		TrustedKeys: trustedKeys,
+		SigningKey:  nodeKey,
 	}
 	node, err := NewNode(cfg)
 	if err != nil {
@@ -51,25 +159,48 @@ func TestNodeLifecycle(t *testing.T) {
 		Key:       "db_host",
 		ValueHash: func() []byte { h := crypto.Hash([]byte("localhost")); return h[:] }(),
 		Operation: vdcspb.Operation_OPERATION_SET,
+		Value:     []byte("localhost"),
 	}
 	// Sign
 	hash, _ := log.ComputeEntryHash(entry)
 	entry.EntryHash = hash
 	entry.Signature = crypto.Sign(priv, hash)
 
-	if err := node.ProposeEntry(entry); err != nil {
+	signedReceipt, err := node.ProposeEntry(entry)
+	if err != nil {
 		t.Fatalf("propose failed: %v", err)
 	}
 
 	// Check State
-	_, root, _ := node.GetLatestRoot()
-	proof, err := node.GetProof("db_host")
+	head := node.GetLatestRoot()
+	root := head.StateRoot
+	receipt, err := signedReceipt.Verify(node.PublicKey())
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("receipt verification failed: %v", err)
+	}
+	if receipt.Index != 0 || !bytes.Equal(receipt.EntryHash, entry.EntryHash) || !bytes.Equal(receipt.StateRoot, root) {
+		t.Errorf("receipt does not match entry: %+v", receipt)
+	}
+	if receipt.MaxMergeDelay != DefaultMaxMergeDelay {
+		t.Errorf("expected default merge delay, got %v", receipt.MaxMergeDelay)
 	}
-	if !proof.Verify(root) {
+	if head.LogSize != 1 || !bytes.Equal(head.LastEntryHash, entry.EntryHash) {
+		t.Errorf("unexpected head: size %d, last entry %x", head.LogSize, head.LastEntryHash)
+	}
+	cp, err := head.Checkpoint.Verify(node.PublicKey())
+	if err != nil {
+		t.Fatalf("checkpoint verification failed: %v", err)
+	}
+	if cp.Origin != DefaultOrigin || cp.LogSize != 1 || !bytes.Equal(cp.LogRoot, head.LogRoot) || !bytes.Equal(cp.StateRoot, root) {
+		t.Errorf("checkpoint does not match head: %+v", cp)
+	}
+	proof := node.GetProof("db_host")
+	if !proof.Exists || !proof.Verify(root) {
 		t.Error("proof failed")
 	}
+	if absent := node.GetProof("db_port"); absent.Exists || !absent.Verify(root) {
+		t.Error("absence proof failed")
+	}
 
 	// 3. Restart Node
 	if err := node.Close(); err != nil {
@@ -90,16 +221,212 @@ func TestNodeLifecycle(t *testing.T) {
 	defer node2.Close()
 
 	// 4. Verify State recovered
-	_, root2, _ := node2.GetLatestRoot()
+	head2 := node2.GetLatestRoot()
+	root2 := head2.StateRoot
 	if !bytes.Equal(root, root2) {
 		t.Error("root mismatch after restart")
 	}
+	if !bytes.Equal(head.LogRoot, head2.LogRoot) {
+		t.Error("log root mismatch after restart")
+	}
+
+	proof2 := node2.GetProof("db_host")
+	if !proof2.Verify(root2) {
+		t.Error("proof failed after restart")
+	}
+
+	// 5. Verify Value recovered
+	head3, value, _, proof3 := node2.GetValue("db_host")
+	if string(value) != "localhost" {
+		t.Errorf("expected value localhost, got %q", value)
+	}
+	if !proof3.Exists || !proof3.Verify(head3.StateRoot) {
+		t.Error("value proof failed after restart")
+	}
+}
 
-	proof2, err := node2.GetProof("db_host")
+func TestNodePreconditions(t *testing.T) {
+	cfg, priv := newTestConfig(t)
+	node := newTestNode(t, cfg)
+
+	propose := func(value string, p *vdcspb.Precondition) error {
+		entry := signedEntry(t, node, "admin", priv, vdcspb.Operation_OPERATION_SET, "db_host", []byte(value))
+		entry.Precondition = p
+		_, err := node.ProposeEntry(signEntry(t, entry, priv))
+		return err
+	}
+	notExist := &vdcspb.Precondition{Condition: &vdcspb.Precondition_MustNotExist{MustNotExist: true}}
+
+	// 1. Create-if-absent succeeds once
+	if err := propose("localhost", notExist); err != nil {
+		t.Fatalf("propose failed: %v", err)
+	}
+	var pe *state.PreconditionError
+	if err := propose("remote", notExist); !errors.As(err, &pe) {
+		t.Fatalf("expected precondition error, got %v", err)
+	}
+	localhost := crypto.Hash([]byte("localhost"))
+	if !bytes.Equal(pe.ValueHash, localhost[:]) {
+		t.Errorf("expected current value hash %x, got %x", localhost, pe.ValueHash)
+	}
+	if size := node.GetLatestRoot().LogSize; size != 1 {
+		t.Fatalf("failed proposal changed the log size to %d", size)
+	}
+
+	// 2. Compare-and-swap on the last modified index
+	if err := propose("remote", &vdcspb.Precondition{
+		Condition: &vdcspb.Precondition_ExpectedModifiedIndex{ExpectedModifiedIndex: 0},
+	}); err != nil {
+		t.Fatalf("compare-and-swap failed: %v", err)
+	}
+}
+
+func TestNodeProposeIntent(t *testing.T) {
+	storagePath := filepath.Join(t.TempDir(), "log.bin")
+	cfg, priv := newTestConfig(t)
+	pub := cfg.TrustedKeys["admin"]
+
+	start := func() *Node {
+		node, err := openTestNode(t, storagePath, storage.FileStoreOptions{}, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return node
+	}
+	newIntent := func(nonce string) *vdcspb.Intent {
+		return signedIntent(t, "admin", priv, "db_host", "localhost", nonce, time.Minute)
+	}
+
+	// 1. The node assigns the index, so two intents built from the same
+	// head both succeed.
+	node := start()
+	for i, nonce := range []string{"a", "b"} {
+		entry, _, err := node.ProposeIntent(newIntent(nonce))
+		if err != nil {
+			t.Fatalf("propose intent failed: %v", err)
+		}
+		if entry.Index != uint64(i) || entry.AuthorId != DefaultOrigin {
+			t.Errorf("unexpected sequenced entry: index %d, author %s", entry.Index, entry.AuthorId)
+		}
+		if err := log.VerifyIntent(entry.Intent, pub); err != nil {
+			t.Errorf("author signature on wrapped intent: %v", err)
+		}
+	}
+	if _, value, _, _ := node.GetValue("db_host"); string(value) != "localhost" {
+		t.Errorf("expected value localhost, got %q", value)
+	}
+	node.Close()
+
+	// 2. Nonces survive a restart
+	node = start()
+	defer node.Close()
+	if _, _, err := node.ProposeIntent(newIntent("a")); !errors.Is(err, log.ErrNonceReused) {
+		t.Errorf("expected ErrNonceReused after restart, got %v", err)
+	}
+}
+
+func TestNodeTimestamps(t *testing.T) {
+	cfg, priv := newTestConfig(t)
+	cfg.MaxClockSkew = time.Minute
+	node := newTestNode(t, cfg)
+
+	entry := func(timestamp time.Time) *vdcspb.ConfigEntry {
+		e := signedEntry(t, node, "admin", priv, vdcspb.Operation_OPERATION_DELETE, "k", nil)
+		e.Timestamp = timestamp.UnixNano()
+		return signEntry(t, e, priv)
+	}
+
+	// 1. Entries outside the skew window are rejected
+	if _, err := node.ProposeEntry(entry(time.Now().Add(time.Hour))); !errors.Is(err, log.ErrTimestampSkew) {
+		t.Fatalf("expected ErrTimestampSkew, got %v", err)
+	}
+
+	// 2. An author whose clock runs ahead does not stall sequenced entries
+	ahead := entry(time.Now().Add(30 * time.Second))
+	if _, err := node.ProposeEntry(ahead); err != nil {
+		t.Fatalf("propose failed: %v", err)
+	}
+	sequenced, _, err := node.ProposeIntent(signedIntent(t, "admin", priv, "k", "v", "n", time.Hour))
 	if err != nil {
+		t.Fatalf("propose intent failed: %v", err)
+	}
+	if sequenced.Timestamp < ahead.Timestamp {
+		t.Errorf("sequenced timestamp %d is before %d", sequenced.Timestamp, ahead.Timestamp)
+	}
+
+	// 3. Entries may not go back in time
+	if _, err := node.ProposeEntry(entry(time.Now())); !errors.Is(err, log.ErrTimestampDecreased) {
+		t.Errorf("expected ErrTimestampDecreased, got %v", err)
+	}
+}
+
+func TestNodeChanges(t *testing.T) {
+	cfg, priv := newTestConfig(t)
+	node := newTestNode(t, cfg)
+
+	changed := node.Changes()
+	select {
+	case <-changed:
+		t.Fatal("change signaled before any commit")
+	default:
+	}
+
+	entry := signedEntry(t, node, "admin", priv, vdcspb.Operation_OPERATION_DELETE, "db_host", nil)
+	if _, err := node.ProposeEntry(entry); err != nil {
 		t.Fatal(err)
 	}
-	if !proof2.Verify(root2) {
-		t.Error("proof failed after restart")
+
+	select {
+	case <-changed:
+	default:
+		t.Fatal("commit did not signal a change")
+	}
+	if got, err := node.GetEntry(0); err != nil || !bytes.Equal(got.EntryHash, entry.EntryHash) {
+		t.Errorf("GetEntry(0) = %v, %v", got, err)
+	}
+	if node.Changes() == changed {
+		t.Error("change channel was not replaced")
+	}
+}
+
+func TestNodeGetEntries(t *testing.T) {
+	cfg, priv := newTestConfig(t)
+	node := newTestNode(t, cfg)
+
+	const total = MaxPageSize + 50
+	for i := 0; i < total; i++ {
+		entry := signedEntry(t, node, "admin", priv, vdcspb.Operation_OPERATION_DELETE, "k", nil)
+		if _, err := node.ProposeEntry(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 1. Default and capped page sizes
+	if entries, _, _ := node.GetEntries(0, 0, 0); len(entries) != DefaultPageSize {
+		t.Errorf("expected default page of %d, got %d", DefaultPageSize, len(entries))
+	}
+	if entries, _, _ := node.GetEntries(0, 0, 10*MaxPageSize); len(entries) != MaxPageSize {
+		t.Errorf("expected page capped at %d, got %d", MaxPageSize, len(entries))
+	}
+
+	// 2. Paging through the whole log
+	var next uint64
+	for next < total {
+		entries, size, err := node.GetEntries(next, 0, 300)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size != total || len(entries) == 0 || entries[0].Index != next {
+			t.Fatalf("unexpected page at %d: %d entries, size %d", next, len(entries), size)
+		}
+		next += uint64(len(entries))
+	}
+
+	// 3. Bounded and invalid ranges
+	if entries, _, _ := node.GetEntries(10, 15, 0); len(entries) != 5 {
+		t.Errorf("expected 5 entries in [10, 15), got %d", len(entries))
+	}
+	if _, _, err := node.GetEntries(0, total+1, 0); !errors.Is(err, log.ErrInvalidIndex) {
+		t.Errorf("expected ErrInvalidIndex beyond the log, got %v", err)
 	}
 }
@@ -0,0 +1,324 @@
+package node
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rrb115/vdcs/internal/checkpoint"
+	"github.com/rrb115/vdcs/internal/crypto"
+	"github.com/rrb115/vdcs/internal/log"
+	"github.com/rrb115/vdcs/internal/policy"
+	vdcspb "github.com/rrb115/vdcs/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrProposalNotFound = errors.New("proposal not found")
+	ErrProposalExists   = errors.New("proposal already exists")
+	ErrProposalClosed   = errors.New("proposal is closed")
+)
+
+// ProposalRetention is how long closed proposals stay listed.
+const ProposalRetention = time.Hour
+
+// Domain tags of the messages signed to close a proposal.
+const (
+	RejectDomain   = "vdcs/proposal-reject/v1"
+	WithdrawDomain = "vdcs/proposal-withdraw/v1"
+)
+
+// RejectMessage returns the message a reviewer signs to reject a proposal.
+func RejectMessage(id, reason string) []byte {
+	return []byte(RejectDomain + "\x00" + id + "\x00" + reason)
+}
+
+// WithdrawMessage returns the message an author signs to withdraw their
+// proposal.
+func WithdrawMessage(id string) []byte {
+	return []byte(WithdrawDomain + "\x00" + id)
+}
+
+// ProposalStore holds proposals that wait for approvals, and recently closed
+// ones. Proposals are kept in memory: a pending proposal is lost when the node
+// restarts, and its author resubmits the same signed intent. ProposalStore is
+// not safe for concurrent use; Node guards it with its own mutex.
+type ProposalStore struct {
+	proposals map[string]*vdcspb.Proposal // ID -> Proposal
+}
+
+// NewProposalStore creates an empty store.
+func NewProposalStore() *ProposalStore {
+	return &ProposalStore{proposals: make(map[string]*vdcspb.Proposal)}
+}
+
+// get returns the proposal with the given ID.
+func (s *ProposalStore) get(id string) (*vdcspb.Proposal, error) {
+	p, ok := s.proposals[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProposalNotFound, id)
+	}
+	return p, nil
+}
+
+// pending returns the proposal with the given ID if it is still pending.
+func (s *ProposalStore) pending(id string) (*vdcspb.Proposal, error) {
+	p, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != vdcspb.ProposalStatus_PROPOSAL_STATUS_PENDING {
+		return nil, fmt.Errorf("%w: %s is %s", ErrProposalClosed, id, statusName(p.Status))
+	}
+	return p, nil
+}
+
+// list returns the proposals for keys starting with prefix, oldest first.
+func (s *ProposalStore) list(prefix string, includeClosed bool) []*vdcspb.Proposal {
+	var list []*vdcspb.Proposal
+	for _, p := range s.proposals {
+		if !includeClosed && p.Status != vdcspb.ProposalStatus_PROPOSAL_STATUS_PENDING {
+			continue
+		}
+		if strings.HasPrefix(p.Intent.Key, prefix) {
+			list = append(list, p)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].SubmittedAt != list[j].SubmittedAt {
+			return list[i].SubmittedAt < list[j].SubmittedAt
+		}
+		return list[i].Id < list[j].Id
+	})
+	return list
+}
+
+// expire closes pending proposals whose intent has expired, and forgets
+// proposals closed more than ProposalRetention ago. It runs lazily, before
+// every operation on the store.
+func (s *ProposalStore) expire(now time.Time) {
+	for id, p := range s.proposals {
+		switch {
+		case p.Status == vdcspb.ProposalStatus_PROPOSAL_STATUS_PENDING:
+			if now.UnixNano() > p.Intent.ExpiresAt {
+				closeProposal(p, vdcspb.ProposalStatus_PROPOSAL_STATUS_EXPIRED, "intent expired", now)
+			}
+		case now.Sub(time.Unix(0, p.ClosedAt)) > ProposalRetention:
+			delete(s.proposals, id)
+		}
+	}
+}
+
+// closeProposal sets the final status of a proposal.
+func closeProposal(p *vdcspb.Proposal, status vdcspb.ProposalStatus, reason string, now time.Time) {
+	p.Status = status
+	p.Reason = reason
+	p.ClosedAt = now.UnixNano()
+}
+
+// statusName returns "pending", "committed", etc.
+func statusName(status vdcspb.ProposalStatus) string {
+	return strings.ToLower(strings.TrimPrefix(status.String(), "PROPOSAL_STATUS_"))
+}
+
+// Commit is an entry committed on behalf of a proposal.
+type Commit struct {
+	Entry   *vdcspb.ConfigEntry
+	Receipt *checkpoint.SignedReceipt
+}
+
+// SubmitProposal queues a signed intent for review. If the intent already
+// has the approvals the policy requires, it is committed right away and
+// the returned Commit is set. The log rejects intents for other reasons
+// immediately, and they are not queued.
+func (n *Node) SubmitProposal(intent *vdcspb.Intent) (*vdcspb.Proposal, *Commit, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	now := time.Now()
+	n.proposals.expire(now)
+
+	// 1. Identify and authenticate the Proposal
+	hash, err := log.ComputeIntentHash(intent)
+	if err != nil {
+		return nil, nil, err
+	}
+	id := hex.EncodeToString(hash)
+	if _, err := n.proposals.get(id); err == nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrProposalExists, id)
+	}
+	pub, ok := n.log.Authors()[intent.AuthorId]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: author %s not trusted", log.ErrInvalidIntent, intent.AuthorId)
+	}
+	if err := log.VerifyIntent(intent, pub); err != nil {
+		return nil, nil, err
+	}
+
+	// 2. Commit it if it is already approved, queue it if not
+	p := &vdcspb.Proposal{
+		Id:          id,
+		Intent:      proto.Clone(intent).(*vdcspb.Intent),
+		Status:      vdcspb.ProposalStatus_PROPOSAL_STATUS_PENDING,
+		SubmittedAt: now.UnixNano(),
+	}
+	c, err := n.tryCommit(p, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	n.proposals.proposals[id] = p
+	return n.proposalView(p), c, nil
+}
+
+// ApproveProposal adds an approval to a pending proposal and commits the
+// proposal if it now has the approvals the policy requires. If the log
+// rejects it for another reason, the proposal is closed as failed.
+func (n *Node) ApproveProposal(id string, approval *vdcspb.Approval) (*vdcspb.Proposal, *Commit, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	now := time.Now()
+	n.proposals.expire(now)
+
+	p, err := n.proposals.pending(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 1. Check the Approval now, so that a bad one does not fail the proposal
+	if approval.AuthorId == p.Intent.AuthorId {
+		return nil, nil, fmt.Errorf("%w: %s cannot approve their own proposal", log.ErrInvalidApproval, approval.AuthorId)
+	}
+	for _, a := range p.Intent.Approvals {
+		if a.AuthorId == approval.AuthorId {
+			return nil, nil, fmt.Errorf("%w: %s already approved", log.ErrInvalidApproval, approval.AuthorId)
+		}
+	}
+	hash, err := log.ComputeIntentHash(p.Intent)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := n.verifyAuthor(approval.AuthorId, hash, approval.Signature); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", log.ErrInvalidApproval, err)
+	}
+
+	// 2. Add it and try to commit
+	p.Intent.Approvals = append(p.Intent.Approvals, proto.Clone(approval).(*vdcspb.Approval))
+	c, err := n.tryCommit(p, now)
+	if err != nil {
+		closeProposal(p, vdcspb.ProposalStatus_PROPOSAL_STATUS_FAILED, err.Error(), now)
+	}
+	return n.proposalView(p), c, nil
+}
+
+// RejectProposal closes a pending proposal. Any trusted author may reject
+// it, by signing RejectMessage(id, reason).
+func (n *Node) RejectProposal(id, authorID, reason string, signature []byte) (*vdcspb.Proposal, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	now := time.Now()
+	n.proposals.expire(now)
+
+	p, err := n.proposals.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.verifyAuthor(authorID, RejectMessage(id, reason), signature); err != nil {
+		return nil, err
+	}
+	closeProposal(p, vdcspb.ProposalStatus_PROPOSAL_STATUS_REJECTED, fmt.Sprintf("rejected by %s: %s", authorID, reason), now)
+	return n.proposalView(p), nil
+}
+
+// WithdrawProposal closes a pending proposal. Only its author may withdraw
+// it, by signing WithdrawMessage(id).
+func (n *Node) WithdrawProposal(id string, signature []byte) (*vdcspb.Proposal, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	now := time.Now()
+	n.proposals.expire(now)
+
+	p, err := n.proposals.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.verifyAuthor(p.Intent.AuthorId, WithdrawMessage(id), signature); err != nil {
+		return nil, err
+	}
+	closeProposal(p, vdcspb.ProposalStatus_PROPOSAL_STATUS_WITHDRAWN, "withdrawn by author", now)
+	return n.proposalView(p), nil
+}
+
+// GetProposal returns a proposal with a diff against the current state.
+func (n *Node) GetProposal(id string) (*vdcspb.Proposal, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.proposals.expire(time.Now())
+
+	p, err := n.proposals.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return n.proposalView(p), nil
+}
+
+// ListProposals returns the pending proposals for keys starting with prefix,
+// oldest first, with diffs against the current state. With includeClosed,
+// recently closed proposals are included too.
+func (n *Node) ListProposals(prefix string, includeClosed bool) []*vdcspb.Proposal {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.proposals.expire(time.Now())
+
+	var list []*vdcspb.Proposal
+	for _, p := range n.proposals.list(prefix, includeClosed) {
+		list = append(list, n.proposalView(p))
+	}
+	return list
+}
+
+// tryCommit sequences the proposal's intent. It returns nil, nil if the
+// intent still needs approvals, and closes the proposal as committed if it
+// was appended. Callers must hold n.mu.
+func (n *Node) tryCommit(p *vdcspb.Proposal, now time.Time) (*Commit, error) {
+	entry, receipt, err := n.sequence(proto.Clone(p.Intent).(*vdcspb.Intent))
+	if errors.Is(err, policy.ErrNeedsApprovals) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	closeProposal(p, vdcspb.ProposalStatus_PROPOSAL_STATUS_COMMITTED, "", now)
+	p.Index = entry.Index
+	return &Commit{Entry: entry, Receipt: receipt}, nil
+}
+
+// verifyAuthor checks a signature by a currently trusted author. Callers
+// must hold n.mu.
+func (n *Node) verifyAuthor(authorID string, message, signature []byte) error {
+	pub, ok := n.log.Authors()[authorID]
+	if !ok {
+		return fmt.Errorf("%w: author %s not trusted", log.ErrInvalidSignature, authorID)
+	}
+	if !crypto.Verify(pub, message, signature) {
+		return fmt.Errorf("%w: by %s", log.ErrInvalidSignature, authorID)
+	}
+	return nil
+}
+
+// proposalView returns a copy of p with the current state of its key.
+// Callers must hold n.mu.
+func (n *Node) proposalView(p *vdcspb.Proposal) *vdcspb.Proposal {
+	view := proto.Clone(p).(*vdcspb.Proposal)
+	key := p.Intent.Key
+	current := &vdcspb.KeyState{}
+	if valueHash, ok := n.state.Get(key); ok {
+		current.Exists = true
+		current.ValueHash = valueHash
+		current.Value, _ = n.state.GetValue(key)
+		current.ModifiedIndex, _ = n.state.ModifiedIndex(key)
+	}
+	view.Current = current
+	return view
+}
@@ -0,0 +1,111 @@
+package node
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	"github.com/rrb115/vdcs/internal/log"
+	"github.com/rrb115/vdcs/internal/policy"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+func TestNodeProposals(t *testing.T) {
+	alicePub, alicePriv, _ := crypto.GenerateKey()
+	bobPub, bobPriv, _ := crypto.GenerateKey()
+	cfg, _ := newTestConfig(t)
+	cfg.TrustedKeys = map[string][]byte{"alice": alicePub, "bob": bobPub}
+	cfg.Policy = &policy.Policy{
+		Rules: []policy.Rule{
+			{Name: "all", Effect: policy.Allow, Authors: []string{policy.AnyAuthor}, Prefixes: []string{""}},
+		},
+		Thresholds: []policy.Threshold{{Name: "prod", Signers: 2, Prefixes: []string{"prod/"}}},
+	}
+	node := newTestNode(t, cfg)
+
+	newIntent := func(key, value string, ttl time.Duration) *vdcspb.Intent {
+		return signedIntent(t, "alice", alicePriv, key, value, key+value, ttl)
+	}
+	submit := func(key, value string, ttl time.Duration) (*vdcspb.Proposal, *Commit, error) {
+		return node.SubmitProposal(newIntent(key, value, ttl))
+	}
+	approval := func(id, author string, priv []byte) *vdcspb.Approval {
+		hash, _ := hex.DecodeString(id)
+		return &vdcspb.Approval{AuthorId: author, Signature: crypto.Sign(priv, hash)}
+	}
+
+	// 1. Unprotected keys commit on submission
+	if p, c, err := submit("dev/db", "v1", time.Minute); err != nil || c == nil || p.Status != vdcspb.ProposalStatus_PROPOSAL_STATUS_COMMITTED {
+		t.Fatalf("expected immediate commit, got %v, %v, %v", p, c, err)
+	}
+
+	// 2. Protected keys wait for approvals, with a diff against the state
+	intent := newIntent("prod/db", "v1", time.Minute)
+	p, c, err := node.SubmitProposal(intent)
+	if err != nil || c != nil || p.Status != vdcspb.ProposalStatus_PROPOSAL_STATUS_PENDING {
+		t.Fatalf("expected pending proposal, got %v, %v, %v", p, c, err)
+	}
+	if p.Current == nil || p.Current.Exists {
+		t.Errorf("expected absent key in diff, got %v", p.Current)
+	}
+	if list := node.ListProposals("prod/", false); len(list) != 1 || list[0].Id != p.Id {
+		t.Fatalf("expected the proposal to be listed, got %v", list)
+	}
+	if _, _, err := node.SubmitProposal(intent); !errors.Is(err, ErrProposalExists) {
+		t.Errorf("expected ErrProposalExists, got %v", err)
+	}
+
+	// 3. Invalid approvals leave the proposal pending
+	for _, a := range []*vdcspb.Approval{
+		approval(p.Id, "alice", alicePriv),
+		approval(p.Id, "bob", alicePriv),
+		approval(p.Id, "mallory", bobPriv),
+	} {
+		if _, _, err := node.ApproveProposal(p.Id, a); !errors.Is(err, log.ErrInvalidApproval) {
+			t.Errorf("approval by %s: expected ErrInvalidApproval, got %v", a.AuthorId, err)
+		}
+	}
+
+	// 4. A valid approval commits it
+	approved, c, err := node.ApproveProposal(p.Id, approval(p.Id, "bob", bobPriv))
+	if err != nil || c == nil || approved.Status != vdcspb.ProposalStatus_PROPOSAL_STATUS_COMMITTED || approved.Index != c.Entry.Index {
+		t.Fatalf("expected commit on approval, got %v, %v, %v", approved, c, err)
+	}
+	if _, value, _, _ := node.GetValue("prod/db"); string(value) != "v1" {
+		t.Errorf("expected value v1, got %q", value)
+	}
+	if _, _, err := node.ApproveProposal(p.Id, approval(p.Id, "bob", bobPriv)); !errors.Is(err, ErrProposalClosed) {
+		t.Errorf("expected ErrProposalClosed, got %v", err)
+	}
+	if len(node.ListProposals("", false)) != 0 || len(node.ListProposals("", true)) != 2 {
+		t.Errorf("unexpected proposal lists after commit")
+	}
+
+	// 5. Reject and withdraw
+	rejected, _, _ := submit("prod/db", "v2", time.Minute)
+	if _, err := node.RejectProposal(rejected.Id, "bob", "no", crypto.Sign(bobPriv, RejectMessage(rejected.Id, "not now"))); !errors.Is(err, log.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for a mismatched reason, got %v", err)
+	}
+	if p, err := node.RejectProposal(rejected.Id, "bob", "no", crypto.Sign(bobPriv, RejectMessage(rejected.Id, "no"))); err != nil || p.Status != vdcspb.ProposalStatus_PROPOSAL_STATUS_REJECTED {
+		t.Errorf("reject failed: %v, %v", p, err)
+	}
+	withdrawn, _, _ := submit("prod/db", "v3", time.Minute)
+	if _, err := node.WithdrawProposal(withdrawn.Id, crypto.Sign(bobPriv, WithdrawMessage(withdrawn.Id))); !errors.Is(err, log.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for a withdrawal by a reviewer, got %v", err)
+	}
+	if p, err := node.WithdrawProposal(withdrawn.Id, crypto.Sign(alicePriv, WithdrawMessage(withdrawn.Id))); err != nil || p.Status != vdcspb.ProposalStatus_PROPOSAL_STATUS_WITHDRAWN {
+		t.Errorf("withdraw failed: %v, %v", p, err)
+	}
+
+	// 6. Proposals expire with their intent
+	expiring, _, _ := submit("prod/db", "v4", 20*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	if p, err := node.GetProposal(expiring.Id); err != nil || p.Status != vdcspb.ProposalStatus_PROPOSAL_STATUS_EXPIRED {
+		t.Errorf("expected expired proposal, got %v, %v", p, err)
+	}
+	if p, err := node.GetProposal(approved.Id); err != nil || p.Current == nil || !p.Current.Exists {
+		t.Errorf("expected committed key in diff, got %v, %v", p, err)
+	}
+}
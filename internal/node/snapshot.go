@@ -0,0 +1,245 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rrb115/vdcs/internal/log"
+	"github.com/rrb115/vdcs/internal/snapshot"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+var (
+	ErrSnapshotsDisabled = errors.New("snapshots are disabled")
+	ErrSnapshotMismatch  = errors.New("snapshot does not match the log")
+)
+
+// Snapshot saves a signed snapshot of the current state to
+// Config.SnapshotDir and returns its version, the index of the last entry it
+// covers. It returns false if the log is empty. If the log has not grown
+// since the last snapshot, it returns that snapshot's version and writes
+// nothing.
+func (n *Node) Snapshot() (uint64, bool, error) {
+	if n.snapshots == nil {
+		return 0, false, ErrSnapshotsDisabled
+	}
+	n.snapMu.Lock()
+	defer n.snapMu.Unlock()
+
+	// 1. Capture the state. Commits only wait for this step.
+	n.mu.RLock()
+	snap, err := n.capture()
+	n.mu.RUnlock()
+	if err != nil || snap == nil {
+		return 0, false, err
+	}
+	if snap.Version+1 == n.snapshotted {
+		return snap.Version, true, nil
+	}
+
+	// 2. Sign and write it
+	if err := n.writeSnapshot(snap); err != nil {
+		return 0, false, err
+	}
+	return snap.Version, true, nil
+}
+
+// writeSnapshot signs snap and saves it. Callers must hold n.snapMu.
+func (n *Node) writeSnapshot(snap *snapshot.Snapshot) error {
+	signed, err := snapshot.Sign(snap, n.signingKey)
+	if err != nil {
+		return err
+	}
+	if err := n.snapshots.Write(snap.Version, signed); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	n.snapshotted = snap.Version + 1
+	return nil
+}
+
+// RestoredSnapshot returns the version of the snapshot NewNode restored, and
+// false if it replayed the whole log.
+func (n *Node) RestoredSnapshot() (uint64, bool) {
+	if n.restored == nil {
+		return 0, false
+	}
+	return n.restored.Version, true
+}
+
+// capture returns a snapshot of the current state, or nil if the log is
+// empty. Callers must hold n.mu, or be replaying.
+func (n *Node) capture() (*snapshot.Snapshot, error) {
+	size, logRoot := n.log.Root()
+	if size == 0 {
+		return nil, nil
+	}
+	head, err := n.log.Get(size - 1)
+	if err != nil {
+		return nil, err
+	}
+	keys, _ := n.state.Keys()
+	return &snapshot.Snapshot{
+		Origin:    n.origin,
+		Genesis:   n.genesis,
+		Version:   size - 1,
+		HeadHash:  head.EntryHash,
+		LogRoot:   logRoot,
+		StateRoot: n.state.Root(),
+		Timestamp: time.Now().UnixNano(),
+		Keys:      keys,
+		Log:       n.log.Validation(),
+		Compacted: n.log.Compacted(),
+	}, nil
+}
+
+// latestSnapshot returns the newest snapshot the node can restore, or nil.
+// Snapshots it cannot use are skipped: the log is then replayed from an
+// older one, or from the start, which checks everything again.
+func (n *Node) latestSnapshot() (*snapshot.Snapshot, error) {
+	if n.snapshots == nil {
+		return nil, nil
+	}
+	versions, err := n.snapshots.Versions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	for _, v := range versions {
+		if snap, err := n.readSnapshot(v); err == nil {
+			return snap, nil
+		}
+	}
+	return nil, nil
+}
+
+// readSnapshot reads the snapshot taken at version, and checks that the node
+// signed it for the log it holds.
+func (n *Node) readSnapshot(version uint64) (*snapshot.Snapshot, error) {
+	signed, err := n.snapshots.Read(version)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := signed.Verify(n.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case snap.Version != version:
+		return nil, fmt.Errorf("%w: file of index %d holds index %d", ErrSnapshotMismatch, version, snap.Version)
+	case snap.Origin != n.origin:
+		return nil, fmt.Errorf("%w: origin %s", ErrSnapshotMismatch, snap.Origin)
+	case !bytes.Equal(snap.Genesis, n.genesis):
+		return nil, fmt.Errorf("%w: taken with other trusted keys or policy", ErrSnapshotMismatch)
+	}
+	entry, err := n.store.Get(version)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(entry.EntryHash, snap.HeadHash) {
+		return nil, fmt.Errorf("%w: entry %d has another hash", ErrSnapshotMismatch, version)
+	}
+	return snap, nil
+}
+
+// restore finishes restoring snap once the log holds the entries it covers.
+func (n *Node) restore(snap *snapshot.Snapshot) error {
+	if _, root := n.log.Root(); !bytes.Equal(root, snap.LogRoot) {
+		return fmt.Errorf("%w: log root at index %d", ErrSnapshotMismatch, snap.Version)
+	}
+	n.log.SetValidation(snap.Log)
+	n.state.Restore(snap.Keys, snap.Version)
+	if !bytes.Equal(n.state.Root(), snap.StateRoot) {
+		return fmt.Errorf("%w: state root at index %d", ErrSnapshotMismatch, snap.Version)
+	}
+	n.restored = snap
+	n.snapshotted = snap.Version + 1
+	return nil
+}
+
+// verifySnapshots replays the whole log, and checks every snapshot against
+// the state replayed up to its index.
+func (n *Node) verifySnapshots() error {
+	first, err := n.store.FirstIndex()
+	if err != nil {
+		return err
+	}
+	if first > 0 {
+		return fmt.Errorf("%w: cannot replay the log from the start, it was compacted up to index %d", log.ErrCompacted, first)
+	}
+	versions, err := n.snapshots.Versions()
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	pending := make(map[uint64]*snapshot.Snapshot, len(versions))
+	for _, v := range versions {
+		signed, err := n.snapshots.Read(v)
+		if err != nil {
+			return fmt.Errorf("snapshot at index %d: %w", v, err)
+		}
+		snap, err := signed.Verify(n.PublicKey())
+		if err != nil {
+			return fmt.Errorf("snapshot at index %d: %w", v, err)
+		}
+		pending[v] = snap
+	}
+
+	err = n.replay(func(entry *vdcspb.ConfigEntry) error {
+		snap, ok := pending[entry.Index]
+		if !ok {
+			return nil
+		}
+		delete(pending, entry.Index)
+		return n.compareSnapshot(snap)
+	})
+	if err != nil {
+		return err
+	}
+	for v := range pending {
+		return fmt.Errorf("%w: snapshot at index %d is past the end of the log", ErrSnapshotMismatch, v)
+	}
+	return nil
+}
+
+// compareSnapshot checks snap against the replayed state.
+func (n *Node) compareSnapshot(snap *snapshot.Snapshot) error {
+	replayed, err := n.capture()
+	if err != nil {
+		return err
+	}
+	switch {
+	case snap.Version != replayed.Version:
+		return fmt.Errorf("%w: file of index %d holds index %d", ErrSnapshotMismatch, replayed.Version, snap.Version)
+	case snap.Origin != replayed.Origin:
+		return fmt.Errorf("%w: origin %s at index %d", ErrSnapshotMismatch, snap.Origin, snap.Version)
+	case !bytes.Equal(snap.Genesis, replayed.Genesis):
+		return fmt.Errorf("%w: genesis at index %d", ErrSnapshotMismatch, snap.Version)
+	case !bytes.Equal(snap.HeadHash, replayed.HeadHash):
+		return fmt.Errorf("%w: head hash at index %d", ErrSnapshotMismatch, snap.Version)
+	case !bytes.Equal(snap.LogRoot, replayed.LogRoot):
+		return fmt.Errorf("%w: log root at index %d", ErrSnapshotMismatch, snap.Version)
+	case !bytes.Equal(snap.StateRoot, replayed.StateRoot):
+		return fmt.Errorf("%w: state root at index %d", ErrSnapshotMismatch, snap.Version)
+	}
+
+	// The roots match; compare the values, authors, policy, nonces and
+	// compacted frontier too.
+	replayed.Timestamp = snap.Timestamp
+	if snap.Compacted != nil {
+		if replayed.Compacted, err = n.log.Compaction(snap.Compacted.Size); err != nil {
+			return fmt.Errorf("%w: compacted entries at index %d: %v", ErrSnapshotMismatch, snap.Version, err)
+		}
+	}
+	want, err := replayed.Marshal()
+	if err != nil {
+		return err
+	}
+	got, err := snap.Marshal()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("%w: keys or validation state at index %d", ErrSnapshotMismatch, snap.Version)
+	}
+	return nil
+}
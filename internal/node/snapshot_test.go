@@ -0,0 +1,140 @@
+package node
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	"github.com/rrb115/vdcs/internal/log"
+	"github.com/rrb115/vdcs/internal/snapshot"
+	"github.com/rrb115/vdcs/internal/storage"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+func TestNodeSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	snapDir := filepath.Join(dir, "snapshots")
+	cfg, priv := newTestConfig(t)
+	cfg.SnapshotDir = snapDir
+	bobPub, bobPriv, _ := crypto.GenerateKey()
+	nodeKey := cfg.SigningKey
+
+	start := func(verify bool) (*Node, error) {
+		cfg.VerifySnapshots = verify
+		return openTestNode(t, filepath.Join(dir, "log.bin"), storage.FileStoreOptions{}, cfg)
+	}
+	propose := func(n *Node, author string, key []byte, op vdcspb.Operation, k string, value []byte) error {
+		_, err := n.ProposeEntry(signedEntry(t, n, author, key, op, k, value))
+		return err
+	}
+	intent := signedIntent(t, "admin", priv, "sequenced", "v", "n", time.Hour)
+
+	// 1. Snapshot a log with a value, a new author and a sequenced intent
+	node, err := start(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := node.Snapshot(); ok || err != nil {
+		t.Fatalf("expected no snapshot of an empty log, got %v, %v", ok, err)
+	}
+	if err := propose(node, "admin", priv, vdcspb.Operation_OPERATION_SET, "db_host", []byte("localhost")); err != nil {
+		t.Fatal(err)
+	}
+	if err := propose(node, "admin", priv, vdcspb.Operation_OPERATION_ADD_AUTHOR, log.AuthorKey("bob"), bobPub); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := node.ProposeIntent(intent); err != nil {
+		t.Fatal(err)
+	}
+	if version, ok, err := node.Snapshot(); !ok || err != nil || version != 2 {
+		t.Fatalf("Snapshot() = %d, %v, %v", version, ok, err)
+	}
+	if err := propose(node, "bob", bobPriv, vdcspb.Operation_OPERATION_SET, "db_port", []byte("5432")); err != nil {
+		t.Fatal(err)
+	}
+	head := node.GetLatestRoot()
+	node.Close()
+
+	// 2. A restart restores the snapshot and replays the last entry
+	node, err = start(false)
+	if err != nil {
+		t.Fatalf("failed to restart: %v", err)
+	}
+	if version, ok := node.RestoredSnapshot(); !ok || version != 2 {
+		t.Errorf("RestoredSnapshot() = %d, %v", version, ok)
+	}
+	restarted := node.GetLatestRoot()
+	if !bytes.Equal(restarted.StateRoot, head.StateRoot) || !bytes.Equal(restarted.LogRoot, head.LogRoot) || restarted.Version != head.Version {
+		t.Errorf("restarted head %+v differs from %+v", restarted, head)
+	}
+	if value, _ := node.state.GetValue("db_host"); string(value) != "localhost" {
+		t.Errorf("expected localhost, got %q", value)
+	}
+	// The snapshot keeps the authors and the used nonces.
+	if err := propose(node, "bob", bobPriv, vdcspb.Operation_OPERATION_DELETE, "db_port", nil); err != nil {
+		t.Errorf("bob cannot write after restore: %v", err)
+	}
+	if _, _, err := node.ProposeIntent(intent); !errors.Is(err, log.ErrNonceReused) {
+		t.Errorf("expected ErrNonceReused after restore, got %v", err)
+	}
+	if version, _, err := node.Snapshot(); err != nil || version != 4 {
+		t.Fatalf("Snapshot() = %d, %v", version, err)
+	}
+	node.Close()
+
+	// 3. Verification replays everything and checks both snapshots
+	node, err = start(true)
+	if err != nil {
+		t.Fatalf("verification failed: %v", err)
+	}
+	if _, ok := node.RestoredSnapshot(); ok {
+		t.Error("verification restored a snapshot")
+	}
+	node.Close()
+
+	// 4. A snapshot whose values differ from the log fails verification,
+	// even though its roots match
+	snaps, _ := snapshot.OpenDir(snapDir)
+	signed, err := snaps.Read(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := signed.Verify(nodeKey.Public().(ed25519.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range snap.Keys {
+		if snap.Keys[i].Key == "db_host" {
+			snap.Keys[i].Value = []byte("evil")
+		}
+	}
+	forged, _ := snapshot.Sign(snap, nodeKey)
+	if err := snaps.Write(4, forged); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := start(true); !errors.Is(err, ErrSnapshotMismatch) {
+		t.Errorf("expected ErrSnapshotMismatch, got %v", err)
+	}
+
+	// 5. Snapshots the node did not sign are skipped
+	_, otherKey, _ := crypto.GenerateKey()
+	unsigned, _ := snapshot.Sign(snap, otherKey)
+	if err := snaps.Write(4, unsigned); err != nil {
+		t.Fatal(err)
+	}
+	node, err = start(false)
+	if err != nil {
+		t.Fatalf("failed to restart: %v", err)
+	}
+	defer node.Close()
+	if version, ok := node.RestoredSnapshot(); !ok || version != 2 {
+		t.Errorf("expected the older snapshot, got %d, %v", version, ok)
+	}
+	if value, _ := node.state.GetValue("db_host"); string(value) != "localhost" {
+		t.Errorf("expected localhost, got %q", value)
+	}
+}
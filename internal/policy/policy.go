@@ -0,0 +1,302 @@
+// Package policy decides which authors may write which keys.
+//
+// A Policy is a list of named rules. Each rule matches authors (directly or
+// through a group), key prefixes and operations, and either allows or denies
+// the writes it matches. A write is allowed if at least one allow rule
+// matches it and no deny rule does, so the order of rules does not matter.
+// Writes no rule matches are denied.
+//
+// Thresholds additionally require a number of distinct authors to sign
+// writes to some keys: the author of the change and the authors of its
+// approvals.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+var (
+	ErrDenied         = errors.New("write denied by policy")
+	ErrNeedsApprovals = errors.New("not enough approvals")
+)
+
+// Effect is what a rule does with the writes it matches.
+type Effect string
+
+const (
+	Allow Effect = "allow"
+	Deny  Effect = "deny"
+)
+
+// AnyAuthor in Rule.Authors matches every author.
+const AnyAuthor = "*"
+
+// Rule matches writes by author, key prefix and operation.
+type Rule struct {
+	Name   string `json:"name"`
+	Effect Effect `json:"effect"`
+	// Authors and Groups select the authors the rule applies to.
+	Authors []string `json:"authors,omitempty"`
+	Groups  []string `json:"groups,omitempty"`
+	// Prefixes select keys. The empty prefix matches every key.
+	Prefixes []string `json:"prefixes"`
+	// Operations names the operations the rule matches: "SET", "DELETE",
+	// or a governance operation such as "ADD_AUTHOR". Empty means all.
+	Operations []string `json:"operations,omitempty"`
+}
+
+// Threshold requires writes to some keys to be signed by at least Signers
+// distinct authors.
+type Threshold struct {
+	Name string `json:"name"`
+	// Signers is the number of distinct authors that must sign.
+	Signers int `json:"signers"`
+	// Authors and Groups select the authors that count towards Signers.
+	// If both are empty, every signer counts.
+	Authors []string `json:"authors,omitempty"`
+	Groups  []string `json:"groups,omitempty"`
+	// Prefixes and Operations select writes like those of a Rule.
+	Prefixes   []string `json:"prefixes"`
+	Operations []string `json:"operations,omitempty"`
+}
+
+// Policy is a set of rules and thresholds, and the groups they refer to.
+type Policy struct {
+	Groups     map[string][]string `json:"groups,omitempty"` // Group -> AuthorIDs
+	Rules      []Rule              `json:"rules"`
+	Thresholds []Threshold         `json:"thresholds,omitempty"`
+}
+
+// DeniedError reports a write the policy does not allow. It wraps ErrDenied.
+type DeniedError struct {
+	Author    string
+	Key       string
+	Operation vdcspb.Operation
+	Rule      string // Name of the deny rule, or empty if no rule allows the write
+}
+
+func (e *DeniedError) Error() string {
+	op := operationName(e.Operation)
+	if e.Rule == "" {
+		return fmt.Sprintf("%v: no rule allows %s to %s key %s", ErrDenied, e.Author, op, e.Key)
+	}
+	return fmt.Sprintf("%v: rule %s denies %s to %s key %s", ErrDenied, e.Rule, e.Author, op, e.Key)
+}
+
+func (e *DeniedError) Unwrap() error {
+	return ErrDenied
+}
+
+// ApprovalError reports a write signed by fewer authors than a threshold
+// requires. It wraps ErrNeedsApprovals.
+type ApprovalError struct {
+	Key       string
+	Operation vdcspb.Operation
+	Threshold string // Name of the threshold
+	Required  int
+	Signers   int // Distinct signers that count towards the threshold
+}
+
+func (e *ApprovalError) Error() string {
+	return fmt.Sprintf("%v: threshold %s requires %d signers to %s key %s, got %d",
+		ErrNeedsApprovals, e.Threshold, e.Required, operationName(e.Operation), e.Key, e.Signers)
+}
+
+func (e *ApprovalError) Unwrap() error {
+	return ErrNeedsApprovals
+}
+
+// Parse decodes and validates a JSON policy.
+func Parse(data []byte) (*Policy, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	p := &Policy{}
+	if err := dec.Decode(p); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Load reads a JSON policy from a file.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Validate checks that every rule is well formed and only refers to groups
+// the policy defines.
+func (p *Policy) Validate() error {
+	names := make(map[string]struct{})
+	for i, r := range p.Rules {
+		if r.Name == "" {
+			return fmt.Errorf("invalid policy: rule %d has no name", i)
+		}
+		if _, ok := names[r.Name]; ok {
+			return fmt.Errorf("invalid policy: duplicate name %s", r.Name)
+		}
+		names[r.Name] = struct{}{}
+
+		if r.Effect != Allow && r.Effect != Deny {
+			return fmt.Errorf("invalid policy: rule %s has effect %q", r.Name, r.Effect)
+		}
+		if len(r.Authors) == 0 && len(r.Groups) == 0 {
+			return fmt.Errorf("invalid policy: rule %s matches no authors", r.Name)
+		}
+		if err := p.validateMatch("rule", r.Name, r.Groups, r.Prefixes, r.Operations); err != nil {
+			return err
+		}
+	}
+	for i, th := range p.Thresholds {
+		if th.Name == "" {
+			return fmt.Errorf("invalid policy: threshold %d has no name", i)
+		}
+		if _, ok := names[th.Name]; ok {
+			return fmt.Errorf("invalid policy: duplicate name %s", th.Name)
+		}
+		names[th.Name] = struct{}{}
+
+		if th.Signers < 1 {
+			return fmt.Errorf("invalid policy: threshold %s requires %d signers", th.Name, th.Signers)
+		}
+		if err := p.validateMatch("threshold", th.Name, th.Groups, th.Prefixes, th.Operations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateMatch checks the groups, prefixes and operations of a rule or
+// threshold.
+func (p *Policy) validateMatch(kind, name string, groups, prefixes, ops []string) error {
+	for _, g := range groups {
+		if _, ok := p.Groups[g]; !ok {
+			return fmt.Errorf("invalid policy: %s %s refers to unknown group %s", kind, name, g)
+		}
+	}
+	if len(prefixes) == 0 {
+		return fmt.Errorf("invalid policy: %s %s matches no keys", kind, name)
+	}
+	for _, op := range ops {
+		if _, ok := operations[op]; !ok {
+			return fmt.Errorf("invalid policy: %s %s has operation %q", kind, name, op)
+		}
+	}
+	return nil
+}
+
+// Check returns a *DeniedError if the policy does not allow author to apply
+// op to key. A nil policy allows every write.
+func (p *Policy) Check(author, key string, op vdcspb.Operation) error {
+	if p == nil {
+		return nil
+	}
+	allowed := false
+	for _, r := range p.Rules {
+		if !p.matches(r, author, key, op) {
+			continue
+		}
+		if r.Effect == Deny {
+			return &DeniedError{Author: author, Key: key, Operation: op, Rule: r.Name}
+		}
+		allowed = true
+	}
+	if !allowed {
+		return &DeniedError{Author: author, Key: key, Operation: op}
+	}
+	return nil
+}
+
+// CheckApprovals returns an *ApprovalError if op on key is signed by fewer
+// distinct authors than a matching threshold requires. signers are the
+// verified, distinct authors that signed the write. A nil policy requires
+// no approvals.
+func (p *Policy) CheckApprovals(key string, op vdcspb.Operation, signers []string) error {
+	if p == nil {
+		return nil
+	}
+	for _, th := range p.Thresholds {
+		if !matchesPrefix(th.Prefixes, key) || !matchesOperation(th.Operations, op) {
+			continue
+		}
+		count := 0
+		for _, signer := range signers {
+			if len(th.Authors) == 0 && len(th.Groups) == 0 || p.matchesAuthor(th.Authors, th.Groups, signer) {
+				count++
+			}
+		}
+		if count < th.Signers {
+			return &ApprovalError{Key: key, Operation: op, Threshold: th.Name, Required: th.Signers, Signers: count}
+		}
+	}
+	return nil
+}
+
+func (p *Policy) matches(r Rule, author, key string, op vdcspb.Operation) bool {
+	return p.matchesAuthor(r.Authors, r.Groups, author) && matchesPrefix(r.Prefixes, key) && matchesOperation(r.Operations, op)
+}
+
+func (p *Policy) matchesAuthor(authors, groups []string, author string) bool {
+	for _, a := range authors {
+		if a == AnyAuthor || a == author {
+			return true
+		}
+	}
+	for _, g := range groups {
+		for _, member := range p.Groups[g] {
+			if member == author {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesPrefix(prefixes []string, key string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesOperation(ops []string, op vdcspb.Operation) bool {
+	if len(ops) == 0 {
+		return true
+	}
+	name := operationName(op)
+	for _, o := range ops {
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
+
+// operations are the operation names rules may refer to.
+var operations = map[string]struct{}{
+	"SET":           {},
+	"DELETE":        {},
+	"ADD_AUTHOR":    {},
+	"REVOKE_AUTHOR": {},
+	"ROTATE_KEY":    {},
+	"UPDATE_POLICY": {},
+}
+
+// operationName returns the name rules use for op, e.g. "SET".
+func operationName(op vdcspb.Operation) string {
+	return strings.TrimPrefix(op.String(), "OPERATION_")
+}
@@ -0,0 +1,137 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+const teamsPolicy = `{
+	"groups": {
+		"payments": ["alice", "bob"],
+		"search": ["carol"]
+	},
+	"rules": [
+		{"name": "payments-write", "effect": "allow", "groups": ["payments"], "prefixes": ["payments/"]},
+		{"name": "search-write", "effect": "allow", "groups": ["search"], "prefixes": ["search/"]},
+		{"name": "ops-all", "effect": "allow", "authors": ["ops"], "prefixes": [""]},
+		{"name": "no-ledger-delete", "effect": "deny", "authors": ["*"], "prefixes": ["payments/ledger"], "operations": ["DELETE"]}
+	]
+}`
+
+func TestPolicyCheck(t *testing.T) {
+	p, err := Parse([]byte(teamsPolicy))
+	if err != nil {
+		t.Fatalf("failed to parse policy: %v", err)
+	}
+
+	set := vdcspb.Operation_OPERATION_SET
+	del := vdcspb.Operation_OPERATION_DELETE
+	tests := []struct {
+		author string
+		key    string
+		op     vdcspb.Operation
+		rule   string // Denying rule; "-" if allowed
+	}{
+		{"alice", "payments/limit", set, "-"},
+		{"bob", "payments/limit", del, "-"},
+		{"carol", "search/index", set, "-"},
+		{"ops", "search/index", del, "-"},
+		{"alice", "search/index", set, ""},
+		{"carol", "payments/limit", set, ""},
+		{"mallory", "payments/limit", set, ""},
+		{"alice", "payments/ledger/2024", set, "-"},
+		{"alice", "payments/ledger/2024", del, "no-ledger-delete"},
+		{"ops", "payments/ledger/2024", del, "no-ledger-delete"},
+	}
+	for _, tt := range tests {
+		err := p.Check(tt.author, tt.key, tt.op)
+		if tt.rule == "-" {
+			if err != nil {
+				t.Errorf("%s %v %s: expected allowed, got %v", tt.author, tt.op, tt.key, err)
+			}
+			continue
+		}
+		var de *DeniedError
+		if !errors.As(err, &de) || !errors.Is(err, ErrDenied) {
+			t.Errorf("%s %v %s: expected denial, got %v", tt.author, tt.op, tt.key, err)
+			continue
+		}
+		if de.Rule != tt.rule {
+			t.Errorf("%s %v %s: expected rule %q, got %q", tt.author, tt.op, tt.key, tt.rule, de.Rule)
+		}
+	}
+
+	// A nil policy allows everything.
+	var none *Policy
+	if err := none.Check("anyone", "any/key", del); err != nil {
+		t.Errorf("nil policy denied a write: %v", err)
+	}
+}
+
+func TestPolicyCheckApprovals(t *testing.T) {
+	p, err := Parse([]byte(`{
+		"groups": {"dba": ["alice", "bob", "dave"]},
+		"rules": [{"name": "all", "effect": "allow", "authors": ["*"], "prefixes": [""]}],
+		"thresholds": [
+			{"name": "prod-db", "signers": 2, "groups": ["dba"], "prefixes": ["prod/db/"]},
+			{"name": "prod-delete", "signers": 3, "prefixes": ["prod/"], "operations": ["DELETE"]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("failed to parse policy: %v", err)
+	}
+
+	set := vdcspb.Operation_OPERATION_SET
+	del := vdcspb.Operation_OPERATION_DELETE
+	tests := []struct {
+		key       string
+		op        vdcspb.Operation
+		signers   []string
+		threshold string // Failing threshold; empty if approved
+	}{
+		{"dev/db/password", set, []string{"carol"}, ""},
+		{"prod/db/password", set, []string{"alice"}, "prod-db"},
+		{"prod/db/password", set, []string{"alice", "carol"}, "prod-db"},
+		{"prod/db/password", set, []string{"alice", "bob"}, ""},
+		{"prod/db/password", del, []string{"alice", "bob"}, "prod-delete"},
+		{"prod/db/password", del, []string{"alice", "bob", "carol"}, ""},
+		{"prod/web/port", del, []string{"carol", "erin", "frank"}, ""},
+	}
+	for _, tt := range tests {
+		err := p.CheckApprovals(tt.key, tt.op, tt.signers)
+		if tt.threshold == "" {
+			if err != nil {
+				t.Errorf("%v %s by %v: expected approved, got %v", tt.op, tt.key, tt.signers, err)
+			}
+			continue
+		}
+		var ae *ApprovalError
+		if !errors.As(err, &ae) || ae.Threshold != tt.threshold {
+			t.Errorf("%v %s by %v: expected threshold %s to fail, got %v", tt.op, tt.key, tt.signers, tt.threshold, err)
+		}
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	invalid := []string{
+		`{"rules": [{"effect": "allow", "authors": ["a"], "prefixes": [""]}]}`,
+		`{"rules": [{"name": "r", "effect": "maybe", "authors": ["a"], "prefixes": [""]}]}`,
+		`{"rules": [{"name": "r", "effect": "allow", "prefixes": [""]}]}`,
+		`{"rules": [{"name": "r", "effect": "allow", "groups": ["g"], "prefixes": [""]}]}`,
+		`{"rules": [{"name": "r", "effect": "allow", "authors": ["a"]}]}`,
+		`{"rules": [{"name": "r", "effect": "allow", "authors": ["a"], "prefixes": [""], "operations": ["TRANSACTION"]}]}`,
+		`{"rules": [{"name": "r", "effect": "allow", "authors": ["a"], "prefixes": [""]}, {"name": "r", "effect": "deny", "authors": ["a"], "prefixes": [""]}]}`,
+		`{"rules": [], "unknown": true}`,
+		`{"rules": [], "thresholds": [{"name": "t", "signers": 0, "prefixes": [""]}]}`,
+		`{"rules": [], "thresholds": [{"name": "t", "signers": 2}]}`,
+		`{"rules": [], "thresholds": [{"name": "t", "signers": 2, "groups": ["g"], "prefixes": [""]}]}`,
+		`{"rules": [{"name": "t", "effect": "allow", "authors": ["a"], "prefixes": [""]}], "thresholds": [{"name": "t", "signers": 2, "prefixes": [""]}]}`,
+	}
+	for _, data := range invalid {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+	}
+}
@@ -2,10 +2,17 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"strings"
 
+	"github.com/rrb115/vdcs/internal/checkpoint"
+	verlog "github.com/rrb115/vdcs/internal/log"
+	"github.com/rrb115/vdcs/internal/merkle"
 	"github.com/rrb115/vdcs/internal/node"
+	"github.com/rrb115/vdcs/internal/policy"
+	"github.com/rrb115/vdcs/internal/state"
 	vdcspb "github.com/rrb115/vdcs/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -24,34 +31,302 @@ func NewServer(n *node.Node) *Server {
 }
 
 func (s *Server) ProposeEntry(ctx context.Context, req *vdcspb.ConfigEntry) (*vdcspb.ProposeResponse, error) {
-	if err := s.node.ProposeEntry(req); err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to propose entry: %v", err)
+	receipt, err := s.node.ProposeEntry(req)
+	if err != nil {
+		return nil, proposeError("failed to propose entry", err)
+	}
+	return proposeResponse(req, receipt), nil
+}
+
+func (s *Server) ProposeIntent(ctx context.Context, req *vdcspb.Intent) (*vdcspb.ProposeResponse, error) {
+	entry, receipt, err := s.node.ProposeIntent(req)
+	if err != nil {
+		return nil, proposeError("failed to propose intent", err)
+	}
+	return proposeResponse(entry, receipt), nil
+}
+
+func proposeResponse(entry *vdcspb.ConfigEntry, receipt *checkpoint.SignedReceipt) *vdcspb.ProposeResponse {
+	return &vdcspb.ProposeResponse{
+		Index:     entry.Index,
+		EntryHash: entry.EntryHash,
+		Receipt: &vdcspb.SignedReceipt{
+			Body:      receipt.Body,
+			Signature: receipt.Signature,
+		},
+	}
+}
+
+// proposeError maps an error from proposing a change to a gRPC status.
+func proposeError(msg string, err error) error {
+	var pe *state.PreconditionError
+	switch {
+	case errors.As(err, &pe):
+		return preconditionStatus(pe)
+	case errors.Is(err, policy.ErrDenied), errors.Is(err, policy.ErrNeedsApprovals):
+		return status.Errorf(codes.PermissionDenied, "%s: %v", msg, err)
+	case errors.Is(err, node.ErrProposalNotFound):
+		return status.Errorf(codes.NotFound, "%s: %v", msg, err)
+	case errors.Is(err, node.ErrProposalExists):
+		return status.Errorf(codes.AlreadyExists, "%s: %v", msg, err)
+	case errors.Is(err, node.ErrProposalClosed), errors.Is(err, verlog.ErrIntentExpired):
+		return status.Errorf(codes.FailedPrecondition, "%s: %v", msg, err)
+	case errors.Is(err, verlog.ErrNonceReused):
+		return status.Errorf(codes.AlreadyExists, "%s: %v", msg, err)
+	case errors.Is(err, verlog.ErrInvalidIntent), errors.Is(err, verlog.ErrInvalidSignature),
+		errors.Is(err, verlog.ErrInvalidGovernance), errors.Is(err, verlog.ErrInvalidApproval),
+		errors.Is(err, verlog.ErrTimestampDecreased), errors.Is(err, verlog.ErrTimestampSkew):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", msg, err)
+	}
+	return status.Errorf(codes.Internal, "%s: %v", msg, err)
+}
+
+// preconditionStatus returns a FailedPrecondition status carrying the
+// current state of the key as a PreconditionFailure detail.
+func preconditionStatus(pe *state.PreconditionError) error {
+	st := status.New(codes.FailedPrecondition, pe.Error())
+	detail := &vdcspb.PreconditionFailure{
+		Key:              pe.Key,
+		Exists:           pe.Exists,
+		CurrentValueHash: pe.ValueHash,
+		ModifiedIndex:    pe.ModifiedIndex,
+	}
+	if withDetail, err := st.WithDetails(detail); err == nil {
+		st = withDetail
+	}
+	return st.Err()
+}
+
+func (s *Server) SubmitProposal(ctx context.Context, req *vdcspb.Intent) (*vdcspb.ProposalResponse, error) {
+	proposal, commit, err := s.node.SubmitProposal(req)
+	if err != nil {
+		return nil, proposeError("failed to submit proposal", err)
+	}
+	return proposalResponse(proposal, commit), nil
+}
+
+func (s *Server) ListProposals(ctx context.Context, req *vdcspb.ListProposalsRequest) (*vdcspb.ListProposalsResponse, error) {
+	return &vdcspb.ListProposalsResponse{
+		Proposals: s.node.ListProposals(req.KeyPrefix, req.IncludeClosed),
+	}, nil
+}
+
+func (s *Server) GetProposal(ctx context.Context, req *vdcspb.GetProposalRequest) (*vdcspb.Proposal, error) {
+	proposal, err := s.node.GetProposal(req.Id)
+	if err != nil {
+		return nil, proposeError("failed to get proposal", err)
+	}
+	return proposal, nil
+}
+
+func (s *Server) ApproveProposal(ctx context.Context, req *vdcspb.ApproveProposalRequest) (*vdcspb.ProposalResponse, error) {
+	if req.Approval == nil {
+		return nil, status.Error(codes.InvalidArgument, "approval is required")
+	}
+	proposal, commit, err := s.node.ApproveProposal(req.Id, req.Approval)
+	if err != nil {
+		return nil, proposeError("failed to approve proposal", err)
+	}
+	return proposalResponse(proposal, commit), nil
+}
+
+func (s *Server) RejectProposal(ctx context.Context, req *vdcspb.RejectProposalRequest) (*vdcspb.ProposalResponse, error) {
+	proposal, err := s.node.RejectProposal(req.Id, req.AuthorId, req.Reason, req.Signature)
+	if err != nil {
+		return nil, proposeError("failed to reject proposal", err)
+	}
+	return proposalResponse(proposal, nil), nil
+}
+
+func (s *Server) WithdrawProposal(ctx context.Context, req *vdcspb.WithdrawProposalRequest) (*vdcspb.ProposalResponse, error) {
+	proposal, err := s.node.WithdrawProposal(req.Id, req.Signature)
+	if err != nil {
+		return nil, proposeError("failed to withdraw proposal", err)
+	}
+	return proposalResponse(proposal, nil), nil
+}
+
+func proposalResponse(proposal *vdcspb.Proposal, commit *node.Commit) *vdcspb.ProposalResponse {
+	resp := &vdcspb.ProposalResponse{Proposal: proposal}
+	if commit != nil {
+		resp.Committed = proposeResponse(commit.Entry, commit.Receipt)
 	}
-	return &vdcspb.ProposeResponse{}, nil
+	return resp
 }
 
 func (s *Server) GetLatestRoot(ctx context.Context, req *vdcspb.Empty) (*vdcspb.ConfigState, error) {
-	ver, root, headHash := s.node.GetLatestRoot()
+	return configState(s.node.GetLatestRoot()), nil
+}
+
+func configState(head node.Head) *vdcspb.ConfigState {
 	return &vdcspb.ConfigState{
-		Version:       ver,
-		StateRoot:     root,
-		LastEntryHash: headHash,
-	}, nil
+		Version:           head.Version,
+		StateRoot:         head.StateRoot,
+		LastEntryHash:     head.LastEntryHash,
+		TreeFormatVersion: merkle.TreeFormatVersion,
+		LogSize:           head.LogSize,
+		LogRoot:           head.LogRoot,
+		Checkpoint: &vdcspb.SignedCheckpoint{
+			Body:      head.Checkpoint.Body,
+			Signature: head.Checkpoint.Signature,
+		},
+	}
 }
 
 func (s *Server) GetProof(ctx context.Context, req *vdcspb.GetProofRequest) (*vdcspb.GetProofResponse, error) {
-	proof, err := s.node.GetProof(req.Key)
+	return proofResponse(s.node.GetProof(req.Key)), nil
+}
+
+func proofResponse(proof *merkle.SparseProof) *vdcspb.GetProofResponse {
+	return &vdcspb.GetProofResponse{
+		Key:           proof.Key,
+		ValueHash:     proof.ValueHash,
+		Siblings:      proof.Siblings,
+		Exists:        proof.Exists,
+		LeafKeyHash:   proof.LeafKeyHash,
+		LeafValueHash: proof.LeafValueHash,
+	}
+}
+
+func (s *Server) GetValue(ctx context.Context, req *vdcspb.GetValueRequest) (*vdcspb.GetValueResponse, error) {
+	head, value, ok, proof := s.node.GetValue(req.Key)
+	return &vdcspb.GetValueResponse{
+		Value:    value,
+		Proof:    proofResponse(proof),
+		State:    configState(head),
+		HasValue: ok,
+	}, nil
+}
+
+func (s *Server) GetInclusionProof(ctx context.Context, req *vdcspb.GetInclusionProofRequest) (*vdcspb.GetInclusionProofResponse, error) {
+	hashes, err := s.node.GetInclusionProof(req.Index, req.TreeSize)
+	if errors.Is(err, verlog.ErrCompacted) {
+		return nil, status.Errorf(codes.NotFound, "failed to build inclusion proof: %v", err)
+	}
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "proof not found: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "failed to build inclusion proof: %v", err)
 	}
-	return &vdcspb.GetProofResponse{
-		Key:       proof.Key,
-		ValueHash: proof.ValueHash,
-		Siblings:  proof.Siblings,
-		IsLeft:    proof.IsLeft,
+	return &vdcspb.GetInclusionProofResponse{
+		Index:    req.Index,
+		TreeSize: req.TreeSize,
+		Hashes:   hashes,
+	}, nil
+}
+
+func (s *Server) GetConsistencyProof(ctx context.Context, req *vdcspb.GetConsistencyProofRequest) (*vdcspb.GetConsistencyProofResponse, error) {
+	hashes, err := s.node.GetConsistencyProof(req.First, req.Second)
+	if errors.Is(err, verlog.ErrCompacted) {
+		return nil, status.Errorf(codes.NotFound, "failed to build consistency proof: %v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to build consistency proof: %v", err)
+	}
+	return &vdcspb.GetConsistencyProofResponse{
+		First:  req.First,
+		Second: req.Second,
+		Hashes: hashes,
 	}, nil
 }
 
+func (s *Server) GetEntries(ctx context.Context, req *vdcspb.GetEntriesRequest) (*vdcspb.GetEntriesResponse, error) {
+	entries, size, err := s.node.GetEntries(req.Start, req.End, int(req.PageSize))
+	switch {
+	case errors.Is(err, verlog.ErrCompacted):
+		return nil, status.Errorf(codes.NotFound, "failed to read entries: %v", err)
+	case errors.Is(err, verlog.ErrInvalidIndex):
+		return nil, status.Errorf(codes.OutOfRange, "failed to read entries: %v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to read entries: %v", err)
+	}
+	return &vdcspb.GetEntriesResponse{
+		Entries:   entries,
+		NextIndex: req.Start + uint64(len(entries)),
+		LogSize:   size,
+	}, nil
+}
+
+// Watch streams matching entries from req.StartIndex on. It reads committed
+// entries from the log at the subscriber's pace: Send blocks while the
+// client's flow-control window is full, and the stream simply falls behind
+// the log instead of buffering events.
+func (s *Server) Watch(req *vdcspb.WatchRequest, stream vdcspb.VDCS_WatchServer) error {
+	ctx := stream.Context()
+	next := req.StartIndex
+	prevSize := req.KnownLogSize
+
+	for {
+		// 1. Take the change channel before the head, so that a commit in
+		// between wakes us up.
+		changed := s.node.Changes()
+		head := s.node.GetLatestRoot()
+		if prevSize > head.LogSize {
+			return status.Errorf(codes.InvalidArgument, "known log size %d is beyond the log size %d", prevSize, head.LogSize)
+		}
+
+		// 2. Send every matching entry up to the head
+		var state *vdcspb.ConfigState
+		for ; next < head.LogSize; next++ {
+			entry, err := s.node.GetEntry(next)
+			if errors.Is(err, verlog.ErrCompacted) {
+				return status.Errorf(codes.NotFound, "failed to read entry %d: %v", next, err)
+			}
+			if err != nil {
+				return status.Errorf(codes.Internal, "failed to read entry %d: %v", next, err)
+			}
+			if !matchesPrefix(entry, req.KeyPrefix) {
+				continue
+			}
+			event, err := s.watchEvent(entry, head, prevSize)
+			if err != nil {
+				return err
+			}
+			if state == nil {
+				state = configState(head)
+			}
+			event.State = state
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+			prevSize = head.LogSize
+		}
+
+		// 3. Wait for the next commit
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// watchEvent builds the event for entry with proofs against head.
+func (s *Server) watchEvent(entry *vdcspb.ConfigEntry, head node.Head, prevSize uint64) (*vdcspb.WatchEvent, error) {
+	inclusion, err := s.node.GetInclusionProof(entry.Index, head.LogSize)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to build inclusion proof: %v", err)
+	}
+	consistency, err := s.node.GetConsistencyProof(prevSize, head.LogSize)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to build consistency proof: %v", err)
+	}
+	return &vdcspb.WatchEvent{
+		Entry:            entry,
+		InclusionProof:   inclusion,
+		PreviousLogSize:  prevSize,
+		ConsistencyProof: consistency,
+	}, nil
+}
+
+// matchesPrefix reports whether entry changes a key starting with prefix.
+func matchesPrefix(entry *vdcspb.ConfigEntry, prefix string) bool {
+	for _, m := range verlog.Mutations(entry) {
+		if strings.HasPrefix(m.Key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // Start starts the gRPC server on the given port.
 func (s *Server) Start(port int) error {
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
@@ -0,0 +1,284 @@
+// Package snapshot saves the state of a node at an index of its log, so that
+// a restart only replays the entries after it.
+//
+// A snapshot body is two '\n'-terminated lines:
+//
+//	vdcs-snapshot/v1
+//	<snapshot, compact JSON>
+//
+// The node signs the body bytes with Ed25519, like a checkpoint, and stores
+// the body followed by a blank line and the standard base64 signature on its
+// own line. A snapshot only vouches for what the node itself validated: it
+// is trusted when the node's own key signed it and the log still holds the
+// entry it was taken at.
+package snapshot
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	"github.com/rrb115/vdcs/internal/log"
+	"github.com/rrb115/vdcs/internal/policy"
+	"github.com/rrb115/vdcs/internal/state"
+)
+
+// Header is the first line of every snapshot body. It versions the encoding
+// and keeps snapshot, checkpoint and receipt signatures apart.
+const Header = "vdcs-snapshot/v1"
+
+var (
+	ErrMalformed        = errors.New("malformed snapshot")
+	ErrInvalidSignature = errors.New("invalid snapshot signature")
+)
+
+// Snapshot is the state of a node after applying the entries [0, Version].
+type Snapshot struct {
+	Origin string `json:"origin"`
+	// Genesis is the digest of the trusted keys and policy the log was
+	// replayed from (see Genesis). A node configured differently must
+	// replay from the start.
+	Genesis   []byte `json:"genesis"`
+	Version   uint64 `json:"version"`    // Index of the last applied entry
+	HeadHash  []byte `json:"head_hash"`  // EntryHash of that entry
+	LogRoot   []byte `json:"log_root"`   // Root of the log tree of size Version+1
+	StateRoot []byte `json:"state_root"` // Root of the state tree
+	Timestamp int64  `json:"timestamp"`  // Unix nanos when the snapshot was taken
+
+	Keys []state.KeyState `json:"keys"`
+	Log  log.Validation   `json:"log"`
+	// Compacted is what the log keeps of the entries the store no longer
+	// holds, or nil if it holds them all. Restoring it and the entries
+	// after it must lead to LogRoot.
+	Compacted *log.Compaction `json:"compacted,omitempty"`
+}
+
+// Marshal returns the body of the snapshot.
+func (s *Snapshot) Marshal() ([]byte, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s\n", Header)
+	b.Write(data)
+	b.WriteString("\n")
+	return b.Bytes(), nil
+}
+
+// Parse decodes a snapshot body.
+func Parse(body []byte) (*Snapshot, error) {
+	header, data, ok := bytes.Cut(body, []byte("\n"))
+	if !ok || string(header) != Header {
+		return nil, fmt.Errorf("%w: unknown header %q", ErrMalformed, header)
+	}
+	if !bytes.HasSuffix(data, []byte("\n")) || bytes.Count(data, []byte("\n")) != 1 {
+		return nil, fmt.Errorf("%w: expected 2 lines", ErrMalformed)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	s := &Snapshot{}
+	if err := dec.Decode(s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("%w: trailing data", ErrMalformed)
+	}
+	return s, nil
+}
+
+// Genesis returns the digest of the trusted keys and the policy a node
+// starts its log with.
+func Genesis(trustedKeys map[string][]byte, p *policy.Policy) ([]byte, error) {
+	ids := make([]string, 0, len(trustedKeys))
+	for id := range trustedKeys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var b bytes.Buffer
+	for _, id := range ids {
+		fmt.Fprintf(&b, "%s %x\n", id, trustedKeys[id])
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	b.Write(data)
+	h := crypto.Hash(b.Bytes())
+	return h[:], nil
+}
+
+// Signed is a snapshot body together with the node's signature over it.
+type Signed struct {
+	Body      []byte
+	Signature []byte
+}
+
+// Sign signs the snapshot with the node's private key.
+func Sign(s *Snapshot, privKey ed25519.PrivateKey) (*Signed, error) {
+	body, err := s.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return &Signed{Body: body, Signature: crypto.Sign(privKey, body)}, nil
+}
+
+// Verify checks the signature against the node's public key and returns the
+// parsed snapshot.
+func (s *Signed) Verify(pubKey ed25519.PublicKey) (*Snapshot, error) {
+	if len(pubKey) != ed25519.PublicKeySize || !crypto.Verify(pubKey, s.Body, s.Signature) {
+		return nil, ErrInvalidSignature
+	}
+	return Parse(s.Body)
+}
+
+// Marshal serializes the signed snapshot for storage: the body, a blank
+// line, then the base64 signature and a newline.
+func (s *Signed) Marshal() []byte {
+	var b bytes.Buffer
+	b.Write(s.Body)
+	b.WriteString("\n")
+	b.WriteString(base64.StdEncoding.EncodeToString(s.Signature))
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+// ParseSigned decodes a signed snapshot produced by Signed.Marshal. It does
+// not verify the signature.
+func ParseSigned(data []byte) (*Signed, error) {
+	i := bytes.LastIndex(data, []byte("\n\n"))
+	if i < 0 || !bytes.HasSuffix(data, []byte("\n")) {
+		return nil, fmt.Errorf("%w: missing signature", ErrMalformed)
+	}
+	sig, err := base64.StdEncoding.DecodeString(string(data[i+2 : len(data)-1]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrMalformed, err)
+	}
+	return &Signed{Body: data[:i+1], Signature: sig}, nil
+}
+
+// snapshotExt is the extension of snapshot files. They are named by
+// version, zero-padded so that names sort by version.
+const snapshotExt = ".snap"
+
+// DefaultKeep is the number of snapshots a Dir keeps when Keep is zero. The
+// older ones are a fallback if the latest cannot be used.
+const DefaultKeep = 2
+
+// Dir is a directory of signed snapshots.
+type Dir struct {
+	path string
+	// Keep is the number of snapshots Write leaves in the directory.
+	// Defaults to DefaultKeep.
+	Keep int
+}
+
+// OpenDir opens a snapshot directory, creating it if needed.
+func OpenDir(path string) (*Dir, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+	return &Dir{path: path}, nil
+}
+
+// Versions returns the versions of the snapshots in the directory, newest
+// first.
+func (d *Dir) Versions() ([]uint64, error) {
+	files, err := os.ReadDir(d.path)
+	if err != nil {
+		return nil, err
+	}
+	var versions []uint64
+	for _, f := range files {
+		digits, ok := strings.CutSuffix(f.Name(), snapshotExt)
+		if !ok || len(digits) != 20 {
+			continue
+		}
+		if v, err := strconv.ParseUint(digits, 10, 64); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+	return versions, nil
+}
+
+// Read reads the snapshot taken at version. It does not verify it.
+func (d *Dir) Read(version uint64) (*Signed, error) {
+	data, err := os.ReadFile(d.file(version))
+	if err != nil {
+		return nil, err
+	}
+	return ParseSigned(data)
+}
+
+// Write saves a snapshot taken at version, then removes all but the newest
+// Keep snapshots. The file is written whole and renamed into place, so a
+// crash never leaves a partial snapshot.
+func (d *Dir) Write(version uint64, s *Signed) error {
+	// 1. Write the snapshot
+	if err := d.writeFile(d.file(version), s.Marshal()); err != nil {
+		return err
+	}
+
+	// 2. Prune
+	keep := d.Keep
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+	versions, err := d.Versions()
+	if err != nil {
+		return err
+	}
+	for i := keep; i < len(versions); i++ {
+		if err := os.Remove(d.file(versions[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Dir) file(version uint64) string {
+	return filepath.Join(d.path, fmt.Sprintf("%020d%s", version, snapshotExt))
+}
+
+// writeFile writes and syncs a temporary file, then renames it to path.
+func (d *Dir) writeFile(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	return syncDir(d.path)
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
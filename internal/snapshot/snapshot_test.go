@@ -0,0 +1,127 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	"github.com/rrb115/vdcs/internal/log"
+	"github.com/rrb115/vdcs/internal/state"
+)
+
+func testSnapshot() *Snapshot {
+	h := crypto.Hash([]byte("value"))
+	return &Snapshot{
+		Origin:    "vdcs.example.com",
+		Version:   7,
+		HeadHash:  h[:],
+		LogRoot:   h[:],
+		StateRoot: h[:],
+		Timestamp: 1700000000000000000,
+		Keys:      []state.KeyState{{Key: "db_host", ValueHash: h[:], Value: []byte("value"), Modified: 3}},
+		Log: log.Validation{
+			Authors:         map[string][]byte{"admin": h[:]},
+			Nonces:          []log.Nonce{{AuthorID: "admin", Nonce: []byte("n"), ExpiresAt: 42}},
+			LatestTimestamp: 1700000000000000000,
+		},
+		Compacted: &log.Compaction{Size: 3, HeadHash: h[:], Frontier: [][]byte{h[:], h[:]}},
+	}
+}
+
+func TestSnapshotSigning(t *testing.T) {
+	pub, priv, _ := crypto.GenerateKey()
+	otherPub, _, _ := crypto.GenerateKey()
+
+	signed, err := Sign(testSnapshot(), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(signed.Body, []byte(Header+"\n{")) || bytes.Count(signed.Body, []byte("\n")) != 2 {
+		t.Fatalf("unexpected body:\n%s", signed.Body)
+	}
+
+	// 1. Storage round trip
+	parsed, err := ParseSigned(signed.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := parsed.Verify(pub)
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	body, _ := snap.Marshal()
+	if !bytes.Equal(body, signed.Body) {
+		t.Errorf("round trip changed the body:\n%s", body)
+	}
+
+	// 2. Wrong key and tampered body
+	if _, err := parsed.Verify(otherPub); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+	parsed.Body = bytes.Replace(parsed.Body, []byte(`"version":7`), []byte(`"version":8`), 1)
+	if _, err := parsed.Verify(pub); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for a tampered body, got %v", err)
+	}
+
+	// 3. Malformed bodies
+	for _, body := range []string{
+		"vdcs-checkpoint/v1\n{}\n",
+		Header + "\n{}",
+		Header + "\n{}\n{}\n",
+		Header + "\n{\"unknown\":1}\n",
+	} {
+		if _, err := Parse([]byte(body)); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%q: expected ErrMalformed, got %v", body, err)
+		}
+	}
+}
+
+func TestGenesis(t *testing.T) {
+	pub1, _, _ := crypto.GenerateKey()
+	pub2, _, _ := crypto.GenerateKey()
+
+	a, _ := Genesis(map[string][]byte{"alice": pub1, "bob": pub2}, nil)
+	b, _ := Genesis(map[string][]byte{"bob": pub2, "alice": pub1}, nil)
+	if !bytes.Equal(a, b) {
+		t.Error("genesis depends on map order")
+	}
+	c, _ := Genesis(map[string][]byte{"alice": pub2, "bob": pub1}, nil)
+	if bytes.Equal(a, c) {
+		t.Error("genesis does not bind IDs to keys")
+	}
+}
+
+func TestDir(t *testing.T) {
+	_, priv, _ := crypto.GenerateKey()
+	d, err := OpenDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Keep = 2
+
+	for _, version := range []uint64{3, 10, 7} {
+		s := testSnapshot()
+		s.Version = version
+		signed, _ := Sign(s, priv)
+		if err := d.Write(version, signed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Write keeps the newest snapshots, not the latest written.
+	versions, err := d.Versions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0] != 10 || versions[1] != 7 {
+		t.Errorf("unexpected versions %v", versions)
+	}
+	signed, err := d.Read(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := Parse(signed.Body); err != nil || s.Version != 10 {
+		t.Errorf("Read(10) = %v, %v", s, err)
+	}
+}
@@ -0,0 +1,68 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/rrb115/vdcs/internal/log"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// PreconditionError reports a precondition that does not hold, together with
+// the current state of its key. It wraps ErrPreconditionFailed.
+type PreconditionError struct {
+	Key           string
+	Exists        bool
+	ValueHash     []byte // Current value hash, if Exists
+	ModifiedIndex uint64 // Index of the entry that last set the key, if Exists
+}
+
+func (e *PreconditionError) Error() string {
+	if !e.Exists {
+		return fmt.Sprintf("%v: key %s does not exist", ErrPreconditionFailed, e.Key)
+	}
+	return fmt.Sprintf("%v: key %s has value hash %x, last modified at index %d",
+		ErrPreconditionFailed, e.Key, e.ValueHash, e.ModifiedIndex)
+}
+
+func (e *PreconditionError) Unwrap() error {
+	return ErrPreconditionFailed
+}
+
+// CheckPreconditions checks the preconditions of every mutation of entry
+// against the current state. It returns a *PreconditionError for the first
+// one that does not hold.
+func (sm *StateMachine) CheckPreconditions(entry *vdcspb.ConfigEntry) error {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	for _, m := range log.Mutations(entry) {
+		if m.Precondition == nil {
+			continue
+		}
+		valueHash, exists := sm.kv[m.Key]
+		modified := sm.updated[m.Key]
+
+		var ok bool
+		switch c := m.Precondition.Condition.(type) {
+		case *vdcspb.Precondition_ExpectedValueHash:
+			ok = exists && bytes.Equal(valueHash, c.ExpectedValueHash)
+		case *vdcspb.Precondition_MustNotExist:
+			ok = !exists
+		case *vdcspb.Precondition_ExpectedModifiedIndex:
+			ok = exists && modified == c.ExpectedModifiedIndex
+		}
+		if !ok {
+			return &PreconditionError{
+				Key:           m.Key,
+				Exists:        exists,
+				ValueHash:     valueHash,
+				ModifiedIndex: modified,
+			}
+		}
+	}
+	return nil
+}
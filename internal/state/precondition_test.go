@@ -0,0 +1,79 @@
+package state
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rrb115/vdcs/internal/crypto"
+	vdcspb "github.com/rrb115/vdcs/proto"
+)
+
+func TestCheckPreconditions(t *testing.T) {
+	sm := NewStateMachine()
+	hash := func(v string) []byte { h := crypto.Hash([]byte(v)); return h[:] }
+
+	sm.Apply(&vdcspb.ConfigEntry{Index: 0, Key: "a", ValueHash: hash("1"), Operation: vdcspb.Operation_OPERATION_SET})
+	sm.Apply(&vdcspb.ConfigEntry{Index: 1, Key: "b", ValueHash: hash("2"), Operation: vdcspb.Operation_OPERATION_SET})
+
+	set := func(key string, p *vdcspb.Precondition) *vdcspb.ConfigEntry {
+		return &vdcspb.ConfigEntry{Key: key, Operation: vdcspb.Operation_OPERATION_SET, Precondition: p}
+	}
+	valueHash := func(v string) *vdcspb.Precondition {
+		return &vdcspb.Precondition{Condition: &vdcspb.Precondition_ExpectedValueHash{ExpectedValueHash: hash(v)}}
+	}
+	modifiedAt := func(i uint64) *vdcspb.Precondition {
+		return &vdcspb.Precondition{Condition: &vdcspb.Precondition_ExpectedModifiedIndex{ExpectedModifiedIndex: i}}
+	}
+	notExist := &vdcspb.Precondition{Condition: &vdcspb.Precondition_MustNotExist{MustNotExist: true}}
+
+	// 1. Single-key entries
+	for name, tc := range map[string]struct {
+		entry *vdcspb.ConfigEntry
+		ok    bool
+	}{
+		"no precondition":          {set("a", nil), true},
+		"value hash matches":       {set("a", valueHash("1")), true},
+		"value hash differs":       {set("a", valueHash("2")), false},
+		"value hash of absent":     {set("c", valueHash("1")), false},
+		"must not exist, absent":   {set("c", notExist), true},
+		"must not exist, present":  {set("a", notExist), false},
+		"modified index matches":   {set("b", modifiedAt(1)), true},
+		"modified index differs":   {set("b", modifiedAt(0)), false},
+		"modified index of absent": {set("c", modifiedAt(0)), false},
+	} {
+		err := sm.CheckPreconditions(tc.entry)
+		if tc.ok && err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrPreconditionFailed) {
+			t.Errorf("%s: expected ErrPreconditionFailed, got %v", name, err)
+		}
+	}
+
+	// 2. The error reports the current state of the key
+	var pe *PreconditionError
+	if err := sm.CheckPreconditions(set("a", valueHash("2"))); !errors.As(err, &pe) {
+		t.Fatalf("expected *PreconditionError, got %v", err)
+	}
+	if pe.Key != "a" || !pe.Exists || string(pe.ValueHash) != string(hash("1")) || pe.ModifiedIndex != 0 {
+		t.Errorf("unexpected precondition error: %+v", pe)
+	}
+
+	// 3. Every mutation of a transaction is checked
+	txn := &vdcspb.ConfigEntry{
+		Operation: vdcspb.Operation_OPERATION_TRANSACTION,
+		Mutations: []*vdcspb.Mutation{
+			{Key: "a", Operation: vdcspb.Operation_OPERATION_DELETE, Precondition: valueHash("1")},
+			{Key: "b", Operation: vdcspb.Operation_OPERATION_DELETE, Precondition: modifiedAt(0)},
+		},
+	}
+	if err := sm.CheckPreconditions(txn); !errors.As(err, &pe) || pe.Key != "b" {
+		t.Errorf("expected precondition failure for b, got %v", err)
+	}
+
+	// 4. Deleting a key clears its modified index
+	sm.Apply(&vdcspb.ConfigEntry{Index: 2, Key: "b", Operation: vdcspb.Operation_OPERATION_DELETE})
+	if err := sm.CheckPreconditions(set("b", notExist)); err != nil {
+		t.Errorf("unexpected error after delete: %v", err)
+	}
+}
@@ -1,8 +1,12 @@
 package state
 
 import (
+	"bytes"
+	"sort"
 	"sync"
 
+	"github.com/rrb115/vdcs/internal/crypto"
+	"github.com/rrb115/vdcs/internal/log"
 	"github.com/rrb115/vdcs/internal/merkle"
 	vdcspb "github.com/rrb115/vdcs/proto"
 )
@@ -10,10 +14,11 @@ import (
 // StateMachine maintains the current in-memory state derived from the log.
 type StateMachine struct {
 	mu      sync.RWMutex
-	kv      map[string][]byte // Current Key -> ValueHash
-	values  map[string][]byte // Optional: Current Key -> Value (if we store values inline)
-	version uint64            // Last applied index
-	tree    *merkle.Tree      // Cached Merkle Tree
+	kv      map[string][]byte  // Current Key -> ValueHash
+	values  map[string][]byte  // Current Key -> Value, for entries that carried the value inline
+	updated map[string]uint64  // Current Key -> Index of the entry that last set it
+	version uint64             // Last applied index
+	tree    *merkle.SparseTree // Merkle Tree, updated on every Apply
 }
 
 // NewStateMachine creates a empty state machine.
@@ -21,7 +26,9 @@ func NewStateMachine() *StateMachine {
 	return &StateMachine{
 		kv:      make(map[string][]byte),
 		values:  make(map[string][]byte),
+		updated: make(map[string]uint64),
 		version: 0,
+		tree:    merkle.NewSparseTree(nil),
 	}
 }
 
@@ -39,33 +46,46 @@ func (sm *StateMachine) Apply(entry *vdcspb.ConfigEntry) {
 	// Let's say initial version is -1 (uint64 wrap) or use a "AppliedCount".
 	// Let's act idempotent or strict.
 
-	switch entry.Operation {
-	case vdcspb.Operation_OPERATION_SET:
-		sm.kv[entry.Key] = entry.ValueHash
-		// If entry.Value is present, store it?
-		// Spec says "ValueHash [32]byte".
-		// But in Section 2.2: "KeyValues map[string][]byte".
-		// We'll store what we have.
-		// If ValueHash is the value, then we are good.
-		// If ValueHash is H(Value), we need Value to support `vdcs get`.
-	case vdcspb.Operation_OPERATION_DELETE:
-		delete(sm.kv, entry.Key)
+	// A transaction changes all of its keys at the same version; readers
+	// hold the read lock, so they never see part of it.
+	for _, m := range log.Mutations(entry) {
+		sm.applyMutation(entry.Index, m)
 	}
 
 	sm.version = entry.Index
-	// Invalidate tree
-	sm.tree = nil
+}
+
+// applyMutation applies a single key change made by the entry at index.
+// Governance entries set or delete their key in the reserved namespace like
+// any other key. Callers must hold sm.mu.
+func (sm *StateMachine) applyMutation(index uint64, m *vdcspb.Mutation) {
+	switch m.Operation {
+	case vdcspb.Operation_OPERATION_SET,
+		vdcspb.Operation_OPERATION_ADD_AUTHOR,
+		vdcspb.Operation_OPERATION_ROTATE_KEY,
+		vdcspb.Operation_OPERATION_UPDATE_POLICY:
+		sm.kv[m.Key] = m.ValueHash
+		sm.updated[m.Key] = index
+		sm.tree.Set(m.Key, m.ValueHash)
+		// The Log has checked that Hash(Value) == ValueHash. Entries without
+		// an inline value only commit to the hash.
+		if inline(m.Value, m.ValueHash) {
+			sm.values[m.Key] = m.Value
+		} else {
+			delete(sm.values, m.Key)
+		}
+	case vdcspb.Operation_OPERATION_DELETE, vdcspb.Operation_OPERATION_REVOKE_AUTHOR:
+		delete(sm.kv, m.Key)
+		delete(sm.values, m.Key)
+		delete(sm.updated, m.Key)
+		sm.tree.Delete(m.Key)
+	}
 }
 
 // Root returns the Merkle root of the current state.
-// Rebuilds key if dirty.
 func (sm *StateMachine) Root() []byte {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-
-	if sm.tree == nil {
-		sm.tree = merkle.NewTree(sm.kv)
-	}
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 	return sm.tree.Root()
 }
 
@@ -77,6 +97,23 @@ func (sm *StateMachine) Get(key string) ([]byte, bool) {
 	return v, ok
 }
 
+// GetValue returns the value for a key, if the entry that set it carried the
+// value inline. The value may be empty.
+func (sm *StateMachine) GetValue(key string) ([]byte, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	v, ok := sm.values[key]
+	return v, ok
+}
+
+// ModifiedIndex returns the index of the entry that last set a key.
+func (sm *StateMachine) ModifiedIndex(key string) (uint64, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	index, ok := sm.updated[key]
+	return index, ok
+}
+
 // Version returns the last applied configuration index.
 func (sm *StateMachine) Version() uint64 {
 	sm.mu.RLock()
@@ -85,12 +122,68 @@ func (sm *StateMachine) Version() uint64 {
 }
 
 // Prove generates a proof for the key against the current root.
-func (sm *StateMachine) Prove(key string) (*merkle.Proof, error) {
+// If the key is not set, the proof shows that it is absent.
+func (sm *StateMachine) Prove(key string) *merkle.SparseProof {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.tree.GenerateProof(key)
+}
+
+// KeyState is the state of one key, as saved in a snapshot.
+type KeyState struct {
+	Key       string `json:"key"`
+	ValueHash []byte `json:"value_hash"`
+	Value     []byte `json:"value,omitempty"` // Nil if the entry that set the key carried no inline value
+	Modified  uint64 `json:"modified_index"`  // Index of the entry that last set the key
+}
+
+// Keys returns the state of every key, sorted by key, and the version they
+// were read at.
+func (sm *StateMachine) Keys() ([]KeyState, uint64) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	keys := make([]KeyState, 0, len(sm.kv))
+	for k, valueHash := range sm.kv {
+		keys = append(keys, KeyState{
+			Key:       k,
+			ValueHash: valueHash,
+			Value:     sm.values[k],
+			Modified:  sm.updated[k],
+		})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+	return keys, sm.version
+}
+
+// Restore replaces the state with keys at version, e.g. those saved in a
+// snapshot, and rebuilds the Merkle tree. Callers check the new Root against
+// the one they expect.
+func (sm *StateMachine) Restore(keys []KeyState, version uint64) {
+	kv := make(map[string][]byte, len(keys))
+	values := make(map[string][]byte)
+	updated := make(map[string]uint64, len(keys))
+	for _, k := range keys {
+		kv[k.Key] = k.ValueHash
+		if inline(k.Value, k.ValueHash) {
+			values[k.Key] = k.Value
+		}
+		updated[k.Key] = k.Modified
+	}
+	tree := merkle.NewSparseTree(kv)
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
+	sm.kv, sm.values, sm.updated = kv, values, updated
+	sm.version = version
+	sm.tree = tree
+}
 
-	if sm.tree == nil {
-		sm.tree = merkle.NewTree(sm.kv)
-	}
-	return sm.tree.GenerateProof(key)
+// emptyValueHash is the hash of the empty value.
+var emptyValueHash = crypto.Hash(nil)
+
+// inline reports whether value is the value valueHash commits to. An empty
+// value is only known to be one when valueHash is the hash of the empty
+// value, as protobuf does not tell a missing value from an empty one.
+func inline(value, valueHash []byte) bool {
+	return len(value) > 0 || bytes.Equal(valueHash, emptyValueHash[:])
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/rrb115/vdcs/internal/crypto"
+	"github.com/rrb115/vdcs/internal/log"
 	vdcspb "github.com/rrb115/vdcs/proto"
 )
 
@@ -21,6 +22,7 @@ func TestStateApply(t *testing.T) {
 		Key:       key,
 		ValueHash: valHashBytes,
 		Operation: vdcspb.Operation_OPERATION_SET,
+		Value:     []byte("value1"),
 	}
 	sm.Apply(entry)
 
@@ -31,6 +33,10 @@ func TestStateApply(t *testing.T) {
 	if !bytes.Equal(got, valHashBytes) {
 		t.Error("value hash mismatch")
 	}
+	value, ok := sm.GetValue(key)
+	if !ok || string(value) != "value1" {
+		t.Errorf("expected value1, got %q", value)
+	}
 
 	// 2. Check Root
 	root := sm.Root()
@@ -39,11 +45,8 @@ func TestStateApply(t *testing.T) {
 	}
 
 	// 3. Verify Proof
-	proof, err := sm.Prove(key)
-	if err != nil {
-		t.Fatal(err)
-	}
-	if !proof.Verify(root) {
+	proof := sm.Prove(key)
+	if !proof.Exists || !proof.Verify(root) {
 		t.Error("proof verification failed")
 	}
 
@@ -59,4 +62,119 @@ func TestStateApply(t *testing.T) {
 	if ok {
 		t.Error("key found after DELETE")
 	}
+	if _, ok := sm.GetValue(key); ok {
+		t.Error("value found after DELETE")
+	}
+
+	// 5. Verify Absence Proof
+	proof = sm.Prove(key)
+	if proof.Exists || !proof.Verify(sm.Root()) {
+		t.Error("absence proof verification failed after DELETE")
+	}
+}
+
+func TestStateApplyTransaction(t *testing.T) {
+	sm := NewStateMachine()
+	hash := func(v string) []byte { h := crypto.Hash([]byte(v)); return h[:] }
+
+	sm.Apply(&vdcspb.ConfigEntry{
+		Index:     0,
+		Key:       "a",
+		ValueHash: hash("old"),
+		Operation: vdcspb.Operation_OPERATION_SET,
+	})
+
+	// 1. One entry sets one key and deletes another
+	sm.Apply(&vdcspb.ConfigEntry{
+		Index:     1,
+		Operation: vdcspb.Operation_OPERATION_TRANSACTION,
+		Mutations: []*vdcspb.Mutation{
+			{Key: "a", Operation: vdcspb.Operation_OPERATION_DELETE},
+			{Key: "b", ValueHash: hash("new"), Operation: vdcspb.Operation_OPERATION_SET, Value: []byte("new")},
+		},
+	})
+
+	if _, ok := sm.Get("a"); ok {
+		t.Error("key a found after transaction deleted it")
+	}
+	if value, ok := sm.GetValue("b"); !ok || string(value) != "new" {
+		t.Errorf("expected value new for key b, got %q", value)
+	}
+	if sm.Version() != 1 {
+		t.Errorf("expected version 1, got %d", sm.Version())
+	}
+
+	// 2. Both changes are reflected in the same root
+	root := sm.Root()
+	if p := sm.Prove("a"); p.Exists || !p.Verify(root) {
+		t.Error("absence proof for a failed")
+	}
+	if p := sm.Prove("b"); !p.Exists || !p.Verify(root) {
+		t.Error("inclusion proof for b failed")
+	}
+}
+
+func TestStateApplyGovernance(t *testing.T) {
+	sm := NewStateMachine()
+	pub, _, _ := crypto.GenerateKey()
+	pubHash := crypto.Hash(pub)
+	key := log.AuthorKey("bob")
+
+	// Governance entries are provable like any other key.
+	sm.Apply(&vdcspb.ConfigEntry{
+		Index:     0,
+		Key:       key,
+		ValueHash: pubHash[:],
+		Operation: vdcspb.Operation_OPERATION_ADD_AUTHOR,
+		Value:     pub,
+	})
+	if value, ok := sm.GetValue(key); !ok || !bytes.Equal(value, pub) {
+		t.Errorf("expected public key for %s, got %x", key, value)
+	}
+	if p := sm.Prove(key); !p.Exists || !p.Verify(sm.Root()) {
+		t.Error("inclusion proof for added author failed")
+	}
+
+	sm.Apply(&vdcspb.ConfigEntry{
+		Index:     1,
+		Key:       key,
+		Operation: vdcspb.Operation_OPERATION_REVOKE_AUTHOR,
+	})
+	if p := sm.Prove(key); p.Exists || !p.Verify(sm.Root()) {
+		t.Error("absence proof for revoked author failed")
+	}
+}
+
+func TestStateRestore(t *testing.T) {
+	sm := NewStateMachine()
+	hash := func(v string) []byte { h := crypto.Hash([]byte(v)); return h[:] }
+	sm.Apply(&vdcspb.ConfigEntry{Index: 0, Key: "b", ValueHash: hash("b"), Operation: vdcspb.Operation_OPERATION_SET, Value: []byte("b")})
+	sm.Apply(&vdcspb.ConfigEntry{Index: 1, Key: "a", ValueHash: hash("a"), Operation: vdcspb.Operation_OPERATION_SET})
+	sm.Apply(&vdcspb.ConfigEntry{Index: 2, Key: "c", ValueHash: hash(""), Operation: vdcspb.Operation_OPERATION_SET})
+
+	keys, version := sm.Keys()
+	if len(keys) != 3 || keys[0].Key != "a" || keys[1].Key != "b" || version != 2 {
+		t.Fatalf("unexpected keys %+v at version %d", keys, version)
+	}
+
+	restored := NewStateMachine()
+	restored.Restore(keys, version)
+	if !bytes.Equal(restored.Root(), sm.Root()) || restored.Version() != 2 {
+		t.Error("restored state differs")
+	}
+	if value, ok := restored.GetValue("b"); !ok || string(value) != "b" {
+		t.Errorf("expected value b, got %q", value)
+	}
+	if _, ok := restored.GetValue("a"); ok {
+		t.Error("key a was set without a value")
+	}
+	// The hash of the empty value is the empty value.
+	for _, s := range []*StateMachine{sm, restored} {
+		if value, ok := s.GetValue("c"); !ok || len(value) != 0 {
+			t.Errorf("expected empty value for c, got %q, %v", value, ok)
+		}
+	}
+	if index, ok := restored.ModifiedIndex("a"); !ok || index != 1 {
+		t.Errorf("expected a modified at 1, got %d", index)
+	}
 }
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+// Index file format. The file starts with indexMagic and a big-endian uint32
+// format version, followed by one entry per log entry, in order:
+//
+//	[Segment (8 bytes)][Offset (8 bytes)]
+//
+// the first index of the segment holding the entry and the offset of its
+// record. The first entry describes the first entry of the first segment.
+// The index is derived data: it is appended to after the record itself is
+// synced, and rebuilt from the segments when it does not match them.
+const (
+	indexName       = "index"
+	indexMagic      = "VDCSIDX\x00"
+	indexVersion    = 1
+	indexHeaderSize = len(indexMagic) + 4
+	indexEntrySize  = 16
+)
+
+// position locates the record of an entry.
+type position struct {
+	segment uint64 // First index of the segment
+	offset  int64
+}
+
+// loadIndex reads an index file. A partial entry at the end, left by a
+// crash, is ignored. It returns an error wrapping os.ErrNotExist if there
+// is no index.
+func loadIndex(path string) ([]position, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < indexHeaderSize || !bytes.HasPrefix(data, []byte(indexMagic)) {
+		return nil, fmt.Errorf("%w: %s: bad header", ErrCorrupt, indexName)
+	}
+	if v := binary.BigEndian.Uint32(data[len(indexMagic):]); v != indexVersion {
+		return nil, fmt.Errorf("%w: %s: unsupported format version %d", ErrCorrupt, indexName, v)
+	}
+	data = data[indexHeaderSize:]
+	positions := make([]position, 0, len(data)/indexEntrySize)
+	for len(data) >= indexEntrySize {
+		positions = append(positions, position{
+			segment: binary.BigEndian.Uint64(data[0:8]),
+			offset:  int64(binary.BigEndian.Uint64(data[8:16])),
+		})
+		data = data[indexEntrySize:]
+	}
+	return positions, nil
+}
+
+// writeIndex replaces the index file with positions, and returns it opened
+// for appending.
+func writeIndex(path string, positions []position) (*os.File, error) {
+	buf := make([]byte, indexHeaderSize, indexHeaderSize+len(positions)*indexEntrySize)
+	copy(buf, indexMagic)
+	binary.BigEndian.PutUint32(buf[len(indexMagic):], indexVersion)
+	for _, p := range positions {
+		buf = appendPosition(buf, p)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf, 0644); err != nil {
+		return nil, err
+	}
+	if err := syncFile(tmpPath); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return nil, err
+	}
+	return openIndex(path)
+}
+
+// openIndex opens an index file for appending, dropping a partial entry at
+// the end.
+func openIndex(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if extra := (info.Size() - int64(indexHeaderSize)) % indexEntrySize; extra != 0 {
+		if err := f.Truncate(info.Size() - extra); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func appendPosition(buf []byte, p position) []byte {
+	buf = binary.BigEndian.AppendUint64(buf, p.segment)
+	return binary.BigEndian.AppendUint64(buf, uint64(p.offset))
+}
+
+func syncFile(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
@@ -0,0 +1,282 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Segment format. A segment starts with fileMagic and a big-endian uint32
+// format version. Each record is framed as
+//
+//	[Length (4 bytes)][CRC32C (4 bytes)][Protobuf Data]
+//
+// where the CRC32C covers the length and the data. Before segmentation the
+// whole log was one file in this format. Files without the magic were
+// written by version 0, which framed records as [Length (8 bytes)][Data]
+// with no checksum.
+const (
+	fileMagic        = "VDCSLOG\x00"
+	fileVersion      = 1
+	fileHeaderSize   = len(fileMagic) + 4
+	recordHeaderSize = 8
+	legacyHeaderSize = 8
+)
+
+// segmentExt is the extension of segment files. They are named by the
+// index of their first entry, zero-padded so that names sort by index.
+const segmentExt = ".seg"
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+func segmentName(first uint64) string {
+	return fmt.Sprintf("%020d%s", first, segmentExt)
+}
+
+// parseSegmentName returns the first index of a segment file name.
+func parseSegmentName(name string) (uint64, bool) {
+	digits, ok := strings.CutSuffix(name, segmentExt)
+	if !ok || len(digits) != 20 {
+		return 0, false
+	}
+	first, err := strconv.ParseUint(digits, 10, 64)
+	return first, err == nil
+}
+
+// segment is one file of a FileStore. It holds consecutive entries, from
+// first on. Only the last segment of a store is written to; the others are
+// sealed: read-only on disk and opened read-only.
+type segment struct {
+	first  uint64
+	path   string
+	file   *os.File
+	size   int64 // End of the last complete record
+	sealed bool
+}
+
+// createSegment creates an empty segment file and writes its header.
+func createSegment(path string, first uint64) (*segment, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	s := &segment{first: first, path: path, file: f}
+	if err := s.writeHeader(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// openSegment opens an existing segment file. Sealed segments are opened
+// read-only, and made read-only on disk if they are not yet.
+func openSegment(path string, first uint64, sealed bool) (*segment, error) {
+	flag := os.O_RDWR | os.O_APPEND
+	if sealed {
+		flag = os.O_RDONLY
+	}
+	f, err := os.OpenFile(path, flag, 0)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if sealed && info.Mode().Perm()&0222 != 0 {
+		if err := os.Chmod(path, info.Mode().Perm()&^0222); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return &segment{first: first, path: path, file: f, size: info.Size(), sealed: sealed}, nil
+}
+
+func (s *segment) writeHeader() error {
+	header := make([]byte, fileHeaderSize)
+	copy(header, fileMagic)
+	binary.BigEndian.PutUint32(header[len(fileMagic):], fileVersion)
+	if _, err := s.file.Write(header); err != nil {
+		return err
+	}
+	s.size = int64(fileHeaderSize)
+	return s.file.Sync()
+}
+
+// append writes a record and returns its offset. The record is written in
+// one call, so that a crash leaves at most one incomplete record at the end
+// of the segment.
+func (s *segment) append(data []byte) (int64, error) {
+	record := make([]byte, recordHeaderSize+len(data))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(data)))
+	copy(record[recordHeaderSize:], data)
+	binary.BigEndian.PutUint32(record[4:8], recordChecksum(record[0:4], data))
+
+	offset := s.size
+	if _, err := s.file.Write(record); err != nil {
+		// Drop a partial record, so the next append does not follow it.
+		s.file.Truncate(s.size)
+		return 0, err
+	}
+	// Ensure durability
+	if err := s.file.Sync(); err != nil {
+		return 0, err
+	}
+	s.size += int64(len(record))
+	return offset, nil
+}
+
+// read returns the data of the record at offset.
+func (s *segment) read(offset int64, maxRecordSize int) ([]byte, error) {
+	name := filepath.Base(s.path)
+	header := make([]byte, recordHeaderSize)
+	if _, err := s.file.ReadAt(header, offset); err != nil {
+		return nil, fmt.Errorf("%w: %s: record at offset %d: %v", ErrCorrupt, name, offset, err)
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	if int64(length) > int64(maxRecordSize) {
+		return nil, fmt.Errorf("%w: %s: record at offset %d is %d bytes (max %d)", ErrCorrupt, name, offset, length, maxRecordSize)
+	}
+	data := make([]byte, length)
+	if _, err := s.file.ReadAt(data, offset+recordHeaderSize); err != nil {
+		return nil, fmt.Errorf("%w: %s: record at offset %d: %v", ErrCorrupt, name, offset, err)
+	}
+	if recordChecksum(header[0:4], data) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, fmt.Errorf("%w: %s: record at offset %d: checksum mismatch", ErrCorrupt, name, offset)
+	}
+	return data, nil
+}
+
+// scan reads the segment from the record at offset from, or from the header
+// if from is zero, checks every record, and calls fn with the offset of
+// each. It returns the end of the last complete record. In the last segment
+// of a store a record that runs past the end of the file is reported as
+// ErrTornRecord; anything else, including a complete final record whose
+// checksum does not match, and anything in a sealed segment, as ErrCorrupt.
+func (s *segment) scan(from int64, maxRecordSize int, fn func(offset int64, data []byte) error) (int64, error) {
+	name := filepath.Base(s.path)
+	torn := ErrTornRecord
+	if s.sealed {
+		torn = ErrCorrupt
+	}
+	info, err := s.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	fileSize := info.Size()
+	r := bufio.NewReader(io.NewSectionReader(s.file, from, fileSize-from))
+
+	// 1. Header
+	if from == 0 {
+		header := make([]byte, fileHeaderSize)
+		if n, err := io.ReadFull(r, header); err != nil {
+			if bytes.HasPrefix([]byte(fileMagic), header[:min(n, len(fileMagic))]) {
+				return 0, fmt.Errorf("%w: %s: header is %d bytes", torn, name, n)
+			}
+			return 0, fmt.Errorf("%w: %s: bad header", ErrCorrupt, name)
+		}
+		if string(header[:len(fileMagic)]) != fileMagic {
+			return 0, fmt.Errorf("%w: %s: bad magic", ErrCorrupt, name)
+		}
+		if v := binary.BigEndian.Uint32(header[len(fileMagic):]); v != fileVersion {
+			return 0, fmt.Errorf("%w: %s: unsupported format version %d", ErrCorrupt, name, v)
+		}
+		from = int64(fileHeaderSize)
+	}
+
+	// 2. Records
+	offset := from
+	recordHeader := make([]byte, recordHeaderSize)
+	for {
+		_, err := io.ReadFull(r, recordHeader)
+		if err == io.EOF {
+			return offset, nil
+		}
+		if err == io.ErrUnexpectedEOF {
+			return offset, fmt.Errorf("%w: %s: record at offset %d: header is cut short", torn, name, offset)
+		}
+		if err != nil {
+			return offset, err
+		}
+
+		// A length no record can have is corruption, wherever it is; only a
+		// plausible length that runs past the end of the file is torn.
+		length := int64(binary.BigEndian.Uint32(recordHeader[0:4]))
+		if length > int64(maxRecordSize) {
+			return offset, fmt.Errorf("%w: %s: record at offset %d is %d bytes (max %d)", ErrCorrupt, name, offset, length, maxRecordSize)
+		}
+		end := offset + recordHeaderSize + length
+		if end > fileSize {
+			return offset, fmt.Errorf("%w: %s: record at offset %d: %d of %d bytes", torn, name, offset, fileSize-offset-recordHeaderSize, length)
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return offset, err
+		}
+		if recordChecksum(recordHeader[0:4], data) != binary.BigEndian.Uint32(recordHeader[4:8]) {
+			return offset, fmt.Errorf("%w: %s: record at offset %d: checksum mismatch", ErrCorrupt, name, offset)
+		}
+
+		if fn != nil {
+			if err := fn(offset, data); err != nil {
+				return offset, err
+			}
+		}
+		offset = end
+	}
+}
+
+// truncate drops everything after end.
+func (s *segment) truncate(end int64) error {
+	if err := s.file.Truncate(end); err != nil {
+		return err
+	}
+	s.size = end
+	return s.file.Sync()
+}
+
+// seal makes the segment read-only.
+func (s *segment) seal() error {
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(s.path, 0444); err != nil {
+		return err
+	}
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	s.file = f
+	s.sealed = true
+	return nil
+}
+
+func recordChecksum(length, data []byte) uint32 {
+	crc := crc32.Update(0, castagnoli, length)
+	return crc32.Update(crc, castagnoli, data)
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -67,12 +69,102 @@ func (s *SQLiteStore) Append(entry *vdcspb.ConfigEntry) error {
 	return nil
 }
 
-// LoadAll reads all entries from the database in order.
-func (s *SQLiteStore) LoadAll() ([]*vdcspb.ConfigEntry, error) {
+// iterateBatch is the number of rows Iterate reads per query.
+const iterateBatch = 1000
+
+// Get returns the entry at the given index.
+func (s *SQLiteStore) Get(index uint64) (*vdcspb.ConfigEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var data []byte
+	err := s.db.QueryRow("SELECT data FROM entries WHERE idx = ?", index).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %d", ErrNotFound, index)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entry: %w", err)
+	}
+	return unmarshalEntry(data)
+}
+
+// FirstIndex returns the smallest index in the database.
+func (s *SQLiteStore) FirstIndex() (uint64, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	rows, err := s.db.Query("SELECT data FROM entries ORDER BY idx ASC")
+	var first sql.NullInt64
+	if err := s.db.QueryRow("SELECT MIN(idx) FROM entries").Scan(&first); err != nil {
+		return 0, fmt.Errorf("failed to query first index: %w", err)
+	}
+	return uint64(first.Int64), nil
+}
+
+// LastIndex returns the index of the last entry.
+func (s *SQLiteStore) LastIndex() (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var last sql.NullInt64
+	if err := s.db.QueryRow("SELECT MAX(idx) FROM entries").Scan(&last); err != nil {
+		return 0, false, fmt.Errorf("failed to query last index: %w", err)
+	}
+	return uint64(last.Int64), last.Valid, nil
+}
+
+// ReadRange returns the entries with indexes in [start, end).
+func (s *SQLiteStore) ReadRange(start, end uint64) ([]*vdcspb.ConfigEntry, error) {
+	if start > end {
+		return nil, fmt.Errorf("%w: range [%d, %d)", ErrNotFound, start, end)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.query(start, end-start)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		if entry.Index != start+uint64(i) {
+			return nil, fmt.Errorf("%w: %d", ErrNotFound, start+uint64(i))
+		}
+	}
+	if uint64(len(entries)) != end-start {
+		return nil, fmt.Errorf("%w: %d", ErrNotFound, start+uint64(len(entries)))
+	}
+	return entries, nil
+}
+
+// Iterate calls fn with every entry from index from on, reading
+// iterateBatch rows at a time. It returns an error wrapping ErrNotFound at
+// the first missing index.
+func (s *SQLiteStore) Iterate(from uint64, fn func(*vdcspb.ConfigEntry) error) error {
+	for {
+		s.mu.Lock()
+		entries, err := s.query(from, iterateBatch)
+		s.mu.Unlock()
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.Index != from {
+				return fmt.Errorf("%w: %d", ErrNotFound, from)
+			}
+			if err := fn(entry); err != nil {
+				return err
+			}
+			from++
+		}
+		if len(entries) < iterateBatch {
+			return nil
+		}
+	}
+}
+
+// query reads up to limit entries, in order, from index start on. Callers
+// must hold s.mu.
+func (s *SQLiteStore) query(start, limit uint64) ([]*vdcspb.ConfigEntry, error) {
+	rows, err := s.db.Query("SELECT data FROM entries WHERE idx >= ? ORDER BY idx ASC LIMIT ?", start, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query entries: %w", err)
 	}
@@ -84,21 +176,88 @@ func (s *SQLiteStore) LoadAll() ([]*vdcspb.ConfigEntry, error) {
 		if err := rows.Scan(&data); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
-
-		entry := &vdcspb.ConfigEntry{}
-		if err := proto.Unmarshal(data, entry); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal entry: %w", err)
+		entry, err := unmarshalEntry(data)
+		if err != nil {
+			return nil, err
 		}
 		entries = append(entries, entry)
 	}
-
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-
 	return entries, nil
 }
 
+// archiveName is the database SQLiteStore.Compact moves entries to in its
+// archive directory.
+const archiveName = "vdcs-archive.db"
+
+// Compact deletes the rows with indexes below before, keeping the last one.
+// With an archiveDir, they are first copied to the entries table of
+// vdcs-archive.db in it, in one transaction.
+func (s *SQLiteStore) Compact(before uint64, archiveDir string) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var last sql.NullInt64
+	if err := s.db.QueryRow("SELECT MAX(idx) FROM entries").Scan(&last); err != nil {
+		return 0, fmt.Errorf("failed to query last index: %w", err)
+	}
+	if last.Valid {
+		before = min(before, uint64(last.Int64))
+	}
+
+	// ATTACH applies to a single connection, so run everything on one.
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if archiveDir != "" {
+		if err := os.MkdirAll(archiveDir, 0755); err != nil {
+			return 0, err
+		}
+		if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS archive", filepath.Join(archiveDir, archiveName)); err != nil {
+			return 0, fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer conn.ExecContext(ctx, "DETACH DATABASE archive")
+		if _, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS archive.entries (idx INTEGER PRIMARY KEY, data BLOB)"); err != nil {
+			return 0, fmt.Errorf("failed to create archive table: %w", err)
+		}
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if archiveDir != "" {
+		if _, err := tx.Exec("INSERT OR REPLACE INTO archive.entries SELECT idx, data FROM main.entries WHERE idx < ?", before); err != nil {
+			return 0, fmt.Errorf("failed to archive entries: %w", err)
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM main.entries WHERE idx < ?", before); err != nil {
+		return 0, fmt.Errorf("failed to delete entries: %w", err)
+	}
+	var first sql.NullInt64
+	if err := tx.QueryRow("SELECT MIN(idx) FROM main.entries").Scan(&first); err != nil {
+		return 0, fmt.Errorf("failed to query first index: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return uint64(first.Int64), nil
+}
+
+func unmarshalEntry(data []byte) (*vdcspb.ConfigEntry, error) {
+	entry := &vdcspb.ConfigEntry{}
+	if err := proto.Unmarshal(data, entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal entry: %w", err)
+	}
+	return entry, nil
+}
+
 // Close closes the database connection.
 func (s *SQLiteStore) Close() error {
 	return s.db.Close()
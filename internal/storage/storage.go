@@ -1,10 +1,14 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/binary"
-	"io"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"sync"
 
 	vdcspb "github.com/rrb115/vdcs/proto"
@@ -14,37 +18,300 @@ import (
 // Store defines the interface for persisting log entries.
 type Store interface {
 	Append(entry *vdcspb.ConfigEntry) error
-	// LoadAll returns all entries in order.
-	LoadAll() ([]*vdcspb.ConfigEntry, error)
+	// Get returns the entry at the given index, or an error wrapping
+	// ErrNotFound.
+	Get(index uint64) (*vdcspb.ConfigEntry, error)
+	// FirstIndex returns the index of the first entry the store holds, or
+	// of the next entry if it is empty. It is above 0 once entries were
+	// compacted.
+	FirstIndex() (uint64, error)
+	// LastIndex returns the index of the last entry, and false if the store
+	// is empty.
+	LastIndex() (uint64, bool, error)
+	// ReadRange returns the entries with indexes in [start, end), or an
+	// error wrapping ErrNotFound if the store does not hold all of them.
+	ReadRange(start, end uint64) ([]*vdcspb.ConfigEntry, error)
+	// Iterate calls fn with every entry from index from on, in order, until
+	// fn returns an error, which Iterate returns. It reads entries in
+	// bounded memory, and does not hold locks while fn runs. Entries
+	// appended during the iteration may or may not be visited.
+	Iterate(from uint64, fn func(*vdcspb.ConfigEntry) error) error
+	// Compact removes entries with indexes below before, and returns the new
+	// first index, which may be lower than before. The last entry is always
+	// kept. If archiveDir is not empty, the entries are moved to it instead
+	// of deleted.
+	Compact(before uint64, archiveDir string) (uint64, error)
 	Close() error
 }
 
-// FileStore implements a simple append-only file storage.
+var (
+	ErrCorrupt        = errors.New("corrupt log file")
+	ErrTornRecord     = errors.New("incomplete final record")
+	ErrRecordTooLarge = errors.New("record too large")
+	ErrOutOfOrder     = errors.New("entry out of order")
+	ErrNotFound       = errors.New("entry not found")
+)
+
+// DefaultMaxRecordSize is the largest record a FileStore writes or reads
+// when FileStoreOptions.MaxRecordSize is zero.
+const DefaultMaxRecordSize = 16 << 20
+
+// DefaultSegmentSize is the size at which a FileStore starts a new segment
+// when FileStoreOptions.SegmentSize is zero.
+const DefaultSegmentSize = 64 << 20
+
+// FileStoreOptions configures a FileStore.
+type FileStoreOptions struct {
+	// MaxRecordSize bounds the encoded size of an entry, on write and on
+	// read, so that a corrupted length cannot make the store allocate
+	// gigabytes. Defaults to DefaultMaxRecordSize.
+	MaxRecordSize int
+	// SegmentSize is the size in bytes at which the current segment is
+	// sealed and a new one started. Defaults to DefaultSegmentSize.
+	SegmentSize int64
+	// Recover truncates an incomplete final record, left behind by a crash
+	// in the middle of Append, instead of refusing to open the store.
+	// Corruption, even in the final record, is never repaired.
+	Recover bool
+}
+
+// FileStore implements an append-only storage in a directory of segment
+// files, each named by the index of its first entry. Once a segment reaches
+// the segment size it is sealed read-only and a new one is started, so old
+// segments can be backed up or archived as they are. An index file maps
+// every entry index to its segment and offset, for random access.
 type FileStore struct {
-	mu   sync.Mutex
-	file *os.File
-	path string
+	mu        sync.Mutex
+	dir       string
+	opts      FileStoreOptions
+	segments  []*segment // Ordered by first index; the last one is active
+	byFirst   map[uint64]*segment
+	index     []position // Position of entry segments[0].first + i
+	indexFile *os.File
+	recovered int64 // Bytes truncated when the store was opened
 }
 
-// NewFileStore opens or creates a file at the given path.
+// NewFileStore opens or creates a store in the directory at the given path
+// with the default options.
 func NewFileStore(path string) (*FileStore, error) {
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+	return OpenFileStore(path, FileStoreOptions{})
+}
+
+// OpenFileStore opens or creates a store in the directory at the given
+// path. A single log file at path, written before segmentation, becomes its
+// first segment. With opts.Recover, an incomplete final record is truncated.
+func OpenFileStore(path string, opts FileStoreOptions) (*FileStore, error) {
+	if opts.MaxRecordSize <= 0 {
+		opts.MaxRecordSize = DefaultMaxRecordSize
+	}
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = DefaultSegmentSize
+	}
+
+	// 1. Convert a single log file
+	if err := migrateSingleFile(path, opts); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
 		return nil, err
 	}
 
-	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
-	if err != nil {
+	// 2. Open the segments
+	fs := &FileStore{dir: path, opts: opts, byFirst: make(map[uint64]*segment)}
+	if err := fs.openSegments(); err != nil {
+		fs.Close()
+		return nil, err
+	}
+
+	// 3. Load the index, and check the active segment
+	if err := fs.openIndex(); err != nil {
+		fs.Close()
 		return nil, err
 	}
+	return fs, nil
+}
+
+// openSegments opens the segment files in the directory, or creates the
+// first one.
+func (fs *FileStore) openSegments() error {
+	files, err := os.ReadDir(fs.dir)
+	if err != nil {
+		return err
+	}
+	var firsts []uint64
+	for _, f := range files {
+		if first, ok := parseSegmentName(f.Name()); ok {
+			firsts = append(firsts, first)
+		}
+	}
+	sort.Slice(firsts, func(i, j int) bool { return firsts[i] < firsts[j] })
+
+	if len(firsts) == 0 {
+		s, err := createSegment(filepath.Join(fs.dir, segmentName(0)), 0)
+		if err != nil {
+			return err
+		}
+		fs.addSegment(s)
+		return nil
+	}
+	for i, first := range firsts {
+		s, err := openSegment(filepath.Join(fs.dir, segmentName(first)), first, i < len(firsts)-1)
+		if err != nil {
+			return err
+		}
+		fs.addSegment(s)
+	}
+	return nil
+}
+
+func (fs *FileStore) addSegment(s *segment) {
+	fs.segments = append(fs.segments, s)
+	fs.byFirst[s.first] = s
+}
+
+func (fs *FileStore) active() *segment {
+	return fs.segments[len(fs.segments)-1]
+}
+
+// openIndex loads the index file, and rebuilds it from the segments if it
+// is missing or does not match them. Records appended to the active segment
+// after the index was last written are added to it.
+func (fs *FileStore) openIndex() error {
+	indexPath := filepath.Join(fs.dir, indexName)
+	positions, err := loadIndex(indexPath)
+	if err == nil && fs.indexMatches(positions) {
+		fs.index = positions
+		if fs.indexFile, err = openIndex(indexPath); err != nil {
+			return err
+		}
+		return fs.scanActive()
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) && !errors.Is(err, ErrCorrupt) {
+		return err
+	}
+
+	// Rebuild: every sealed segment must hold exactly the entries up to the
+	// first entry of the next one.
+	fs.index = nil
+	for i, s := range fs.segments[:len(fs.segments)-1] {
+		count := uint64(0)
+		_, err := s.scan(0, fs.opts.MaxRecordSize, func(offset int64, _ []byte) error {
+			fs.index = append(fs.index, position{segment: s.first, offset: offset})
+			count++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if next := fs.segments[i+1].first; s.first+count != next {
+			return fmt.Errorf("%w: %s holds %d entries, but the next segment starts at %d", ErrCorrupt, filepath.Base(s.path), count, next)
+		}
+	}
+	if fs.indexFile, err = writeIndex(indexPath, fs.index); err != nil {
+		return err
+	}
+	return fs.scanActive()
+}
+
+// indexMatches reports whether every position in the index lies in the
+// segment that should hold it, and the index covers every sealed segment.
+func (fs *FileStore) indexMatches(positions []position) bool {
+	first := fs.segments[0].first
+	k := 0
+	var prev int64
+	for i, p := range positions {
+		index := first + uint64(i)
+		for k+1 < len(fs.segments) && index >= fs.segments[k+1].first {
+			k++
+			prev = 0
+		}
+		s := fs.segments[k]
+		if p.segment != s.first || p.offset <= prev || p.offset < int64(fileHeaderSize) || p.offset >= s.size {
+			return false
+		}
+		prev = p.offset
+	}
+	return first+uint64(len(positions)) >= fs.active().first
+}
+
+// scanActive checks every record of the active segment, and adds those the
+// index does not cover yet. An incomplete final record is truncated if the
+// store was opened with Recover. Sealed segments are checked as they are
+// read.
+func (fs *FileStore) scanActive() error {
+	active := fs.active()
+	var scanned []position
+	size := active.size
+	end, err := active.scan(0, fs.opts.MaxRecordSize, func(offset int64, _ []byte) error {
+		scanned = append(scanned, position{segment: active.first, offset: offset})
+		return nil
+	})
+	if errors.Is(err, ErrTornRecord) {
+		// Positions are indexed only once their record is synced, so an
+		// indexed record at or past the cut means it is not a torn tail but
+		// a corrupted record in the middle of the segment.
+		if last := len(fs.index) - 1; last >= 0 && fs.index[last].segment == active.first && fs.index[last].offset >= end {
+			return fmt.Errorf("%w: %s: record at offset %d is cut short, but the index holds a record at offset %d", ErrCorrupt, filepath.Base(active.path), end, fs.index[last].offset)
+		}
+	}
+	if errors.Is(err, ErrTornRecord) && fs.opts.Recover {
+		fs.recovered = size - end
+		if end < int64(fileHeaderSize) {
+			// The crash happened while the header was written.
+			if err := active.truncate(0); err != nil {
+				return err
+			}
+			err = active.writeHeader()
+			end = int64(fileHeaderSize)
+		} else {
+			err = active.truncate(end)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	active.size = end
+
+	// The index may lag behind the segment, but must not disagree with it.
+	indexed := len(fs.index) - int(active.first-fs.segments[0].first)
+	if indexed > len(scanned) || !slices.Equal(fs.index[len(fs.index)-indexed:], scanned[:indexed]) {
+		fs.index = fs.index[:len(fs.index)-indexed]
+		indexed = 0
+		fs.indexFile.Close()
+		if fs.indexFile, err = writeIndex(filepath.Join(fs.dir, indexName), fs.index); err != nil {
+			return err
+		}
+	}
+	for _, p := range scanned[indexed:] {
+		if err := fs.addPosition(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addPosition records the position of the next entry in the index.
+func (fs *FileStore) addPosition(p position) error {
+	if _, err := fs.indexFile.Write(appendPosition(nil, p)); err != nil {
+		return err
+	}
+	fs.index = append(fs.index, p)
+	return nil
+}
 
-	return &FileStore{
-		file: f,
-		path: path,
-	}, nil
+// next returns the index of the next entry to append.
+func (fs *FileStore) next() uint64 {
+	return fs.segments[0].first + uint64(len(fs.index))
 }
 
-// Append writes an entry to the file.
-// Format: [Length (8 bytes)][Protobuf Data]
+// Recovered returns the number of bytes of an incomplete final record that
+// were truncated when the store was opened.
+func (fs *FileStore) Recovered() int64 {
+	return fs.recovered
+}
+
+// Append writes an entry to the active segment, starting a new segment
+// first if the active one is full. Entries must be appended in index order.
 func (fs *FileStore) Append(entry *vdcspb.ConfigEntry) error {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
@@ -53,64 +320,295 @@ func (fs *FileStore) Append(entry *vdcspb.ConfigEntry) error {
 	if err != nil {
 		return err
 	}
+	if len(data) > fs.opts.MaxRecordSize {
+		return fmt.Errorf("%w: entry %d is %d bytes (max %d)", ErrRecordTooLarge, entry.Index, len(data), fs.opts.MaxRecordSize)
+	}
+	next := fs.next()
+	if entry.Index != next {
+		return fmt.Errorf("%w: expected entry %d, got %d", ErrOutOfOrder, next, entry.Index)
+	}
 
-	// Write length prefix
-	lenBuf := make([]byte, 8)
-	binary.BigEndian.PutUint64(lenBuf, uint64(len(data)))
-
-	if _, err := fs.file.Write(lenBuf); err != nil {
-		return err
+	// 1. Rotate
+	active := fs.active()
+	if active.size >= fs.opts.SegmentSize && active.size > int64(fileHeaderSize) {
+		if err := active.seal(); err != nil {
+			return err
+		}
+		s, err := createSegment(filepath.Join(fs.dir, segmentName(next)), next)
+		if err != nil {
+			return err
+		}
+		fs.addSegment(s)
+		active = s
 	}
-	if _, err := fs.file.Write(data); err != nil {
+
+	// 2. Write the record, then index it
+	offset, err := active.append(data)
+	if err != nil {
 		return err
 	}
-
-	// Ensure durability
-	return fs.file.Sync()
+	return fs.addPosition(position{segment: active.first, offset: offset})
 }
 
-// LoadAll reads all entries from the file.
-func (fs *FileStore) LoadAll() ([]*vdcspb.ConfigEntry, error) {
+// Get returns the entry at the given index.
+func (fs *FileStore) Get(index uint64) (*vdcspb.ConfigEntry, error) {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
+	return fs.get(index)
+}
 
-	// Seek to beginning
-	if _, err := fs.file.Seek(0, 0); err != nil {
+func (fs *FileStore) get(index uint64) (*vdcspb.ConfigEntry, error) {
+	first := fs.segments[0].first
+	if index < first || index >= fs.next() {
+		return nil, fmt.Errorf("%w: %d", ErrNotFound, index)
+	}
+	p := fs.index[index-first]
+	s := fs.byFirst[p.segment]
+	data, err := s.read(p.offset, fs.opts.MaxRecordSize)
+	if err != nil {
 		return nil, err
 	}
+	entry := &vdcspb.ConfigEntry{}
+	if err := proto.Unmarshal(data, entry); err != nil {
+		return nil, fmt.Errorf("%w: %s: record at offset %d: %v", ErrCorrupt, filepath.Base(s.path), p.offset, err)
+	}
+	if entry.Index != index {
+		return nil, fmt.Errorf("%w: %s: record at offset %d holds entry %d, expected %d", ErrCorrupt, filepath.Base(s.path), p.offset, entry.Index, index)
+	}
+	return entry, nil
+}
 
-	var entries []*vdcspb.ConfigEntry
-	lenBuf := make([]byte, 8)
+// FirstIndex returns the first index of the first segment.
+func (fs *FileStore) FirstIndex() (uint64, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.segments[0].first, nil
+}
 
-	for {
-		// Read Length
-		_, err := io.ReadFull(fs.file, lenBuf)
-		if err == io.EOF {
-			break
-		}
+// LastIndex returns the index of the last entry.
+func (fs *FileStore) LastIndex() (uint64, bool, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	next := fs.next()
+	if next == 0 {
+		return 0, false, nil
+	}
+	return next - 1, true, nil
+}
+
+// ReadRange returns the entries with indexes in [start, end).
+func (fs *FileStore) ReadRange(start, end uint64) ([]*vdcspb.ConfigEntry, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if start > end || start < fs.segments[0].first || end > fs.next() {
+		return nil, fmt.Errorf("%w: range [%d, %d) of store [%d, %d)", ErrNotFound, start, end, fs.segments[0].first, fs.next())
+	}
+	entries := make([]*vdcspb.ConfigEntry, 0, end-start)
+	for i := start; i < end; i++ {
+		entry, err := fs.get(i)
 		if err != nil {
 			return nil, err
 		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
 
-		length := binary.BigEndian.Uint64(lenBuf)
-		data := make([]byte, length)
-
-		// Read Data
-		_, err = io.ReadFull(fs.file, data)
+// Iterate calls fn with every entry from index from on, reading one record
+// at a time.
+func (fs *FileStore) Iterate(from uint64, fn func(*vdcspb.ConfigEntry) error) error {
+	for i := from; ; i++ {
+		fs.mu.Lock()
+		if i >= fs.next() {
+			fs.mu.Unlock()
+			return nil
+		}
+		entry, err := fs.get(i)
+		fs.mu.Unlock()
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
 		}
+	}
+}
 
-		entry := &vdcspb.ConfigEntry{}
-		if err := proto.Unmarshal(data, entry); err != nil {
-			return nil, err
+// Compact removes the sealed segments whose entries all have indexes below
+// before. The active segment is never removed. With an archiveDir, the
+// segment files are moved there as they are.
+func (fs *FileStore) Compact(before uint64, archiveDir string) (uint64, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	// 1. Find the segments to remove
+	n := 0
+	for n+1 < len(fs.segments) && fs.segments[n+1].first <= before {
+		n++
+	}
+	if n == 0 {
+		return fs.segments[0].first, nil
+	}
+	if archiveDir != "" {
+		if err := os.MkdirAll(archiveDir, 0755); err != nil {
+			return 0, err
 		}
-		entries = append(entries, entry)
 	}
 
-	return entries, nil
+	// 2. Remove them, oldest first, so that a crash leaves the remaining
+	// segments contiguous. The index is rebuilt on open if it does not
+	// match them.
+	removed := 0
+	var err error
+	for _, s := range fs.segments[:n] {
+		if archiveDir != "" {
+			err = os.Rename(s.path, filepath.Join(archiveDir, filepath.Base(s.path)))
+		} else {
+			err = os.Remove(s.path)
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to compact %s: %w", filepath.Base(s.path), err)
+			break
+		}
+		s.file.Close()
+		delete(fs.byFirst, s.first)
+		removed++
+	}
+	if removed == 0 {
+		return fs.segments[0].first, err
+	}
+	entries := fs.segments[removed].first - fs.segments[0].first
+	fs.segments = append([]*segment(nil), fs.segments[removed:]...)
+	fs.index = append([]position(nil), fs.index[entries:]...)
+	if err != nil {
+		return fs.segments[0].first, err
+	}
+	if err := syncDir(fs.dir); err != nil {
+		return 0, err
+	}
+	if archiveDir != "" {
+		if err := syncDir(archiveDir); err != nil {
+			return 0, err
+		}
+	}
+
+	// 3. Rewrite the index
+	fs.indexFile.Close()
+	if fs.indexFile, err = writeIndex(filepath.Join(fs.dir, indexName), fs.index); err != nil {
+		return 0, err
+	}
+	return fs.segments[0].first, nil
 }
 
 func (fs *FileStore) Close() error {
-	return fs.file.Close()
+	var err error
+	for _, s := range fs.segments {
+		if cerr := s.file.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if fs.indexFile != nil {
+		if cerr := fs.indexFile.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// migrateSingleFile turns a log written before segmentation, a single file
+// at path, into a directory at path holding it as the first segment. Files
+// in the version 0 format are rewritten in the current format first. The
+// file is moved aside before the directory is created, so a crash at any
+// point leaves something the next call can finish.
+func migrateSingleFile(path string, opts FileStoreOptions) error {
+	movedPath := path + ".migrate"
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && info.Mode().IsRegular():
+		if err := upgradeLegacyFile(path, opts); err != nil {
+			return err
+		}
+		if err := os.Rename(path, movedPath); err != nil {
+			return err
+		}
+	case err != nil && !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	if _, err := os.Stat(movedPath); errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(movedPath, filepath.Join(path, segmentName(0))); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// upgradeLegacyFile rewrites a file in the version 0 format, if path is
+// one, in the current format. The new file is written next to the old one
+// and renamed over it, so a crash leaves one of the two intact.
+func upgradeLegacyFile(path string, opts FileStoreOptions) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 || bytes.HasPrefix(data, []byte(fileMagic)) || bytes.HasPrefix([]byte(fileMagic), data) {
+		return nil
+	}
+
+	// 1. Read the version 0 records
+	var records [][]byte
+	offset := 0
+	for offset < len(data) {
+		if len(data)-offset < legacyHeaderSize {
+			if !opts.Recover {
+				return fmt.Errorf("%w at offset %d of version 0 file", ErrTornRecord, offset)
+			}
+			break
+		}
+		length := binary.BigEndian.Uint64(data[offset : offset+legacyHeaderSize])
+		if length > uint64(len(data)-offset-legacyHeaderSize) {
+			if !opts.Recover {
+				return fmt.Errorf("%w at offset %d of version 0 file", ErrTornRecord, offset)
+			}
+			break
+		}
+		if length > uint64(opts.MaxRecordSize) {
+			return fmt.Errorf("%w: record at offset %d of version 0 file is %d bytes (max %d)", ErrCorrupt, offset, length, opts.MaxRecordSize)
+		}
+		start := offset + legacyHeaderSize
+		record := data[start : start+int(length)]
+		if err := proto.Unmarshal(record, &vdcspb.ConfigEntry{}); err != nil {
+			return fmt.Errorf("%w: record at offset %d of version 0 file: %v", ErrCorrupt, offset, err)
+		}
+		records = append(records, record)
+		offset = start + int(length)
+	}
+
+	// 2. Write them in the current format and swap the files
+	tmpPath := path + ".upgrade"
+	os.Remove(tmpPath)
+	tmp, err := createSegment(tmpPath, 0)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if _, err := tmp.append(record); err != nil {
+			tmp.file.Close()
+			return fmt.Errorf("failed to upgrade version 0 file: %w", err)
+		}
+	}
+	if err := tmp.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
 }
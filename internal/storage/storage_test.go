@@ -1,11 +1,17 @@
 package storage
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	vdcspb "github.com/rrb115/vdcs/proto"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestFileStore(t *testing.T) {
@@ -46,7 +52,7 @@ func TestFileStore(t *testing.T) {
 	}
 	defer store2.Close()
 
-	loaded, err := store2.LoadAll()
+	loaded, err := loadAll(store2)
 	if err != nil {
 		t.Fatalf("failed to load: %v", err)
 	}
@@ -61,3 +67,432 @@ func TestFileStore(t *testing.T) {
 		}
 	}
 }
+
+func TestFileStoreRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	segPath := filepath.Join(path, segmentName(0))
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(0); i < 3; i++ {
+		if err := store.Append(&vdcspb.ConfigEntry{Index: i, Key: "k"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.Close()
+	good, err := os.ReadFile(segPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 1. A crash in the middle of Append leaves an incomplete final record
+	if err := os.WriteFile(segPath, append(good, 0, 0, 0, 9, 1, 2), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileStore(path); !errors.Is(err, ErrTornRecord) {
+		t.Fatalf("expected ErrTornRecord, got %v", err)
+	}
+	store, err = OpenFileStore(path, FileStoreOptions{Recover: true})
+	if err != nil {
+		t.Fatalf("recovery failed: %v", err)
+	}
+	if store.Recovered() != 6 {
+		t.Errorf("expected 6 bytes recovered, got %d", store.Recovered())
+	}
+	if err := store.Append(&vdcspb.ConfigEntry{Index: 3, Key: "k"}); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := loadAll(store); err != nil || len(entries) != 4 {
+		t.Errorf("expected 4 entries after recovery, got %d, %v", len(entries), err)
+	}
+	store.Close()
+
+	// 2. Corruption before the final record is never repaired, and the error
+	// names the offset.
+	corrupt := append([]byte(nil), good...)
+	corrupt[fileHeaderSize+recordHeaderSize]++
+	if err := os.WriteFile(segPath, corrupt, 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = OpenFileStore(path, FileStoreOptions{Recover: true})
+	if !errors.Is(err, ErrCorrupt) || !strings.Contains(err.Error(), fmt.Sprintf("offset %d", fileHeaderSize)) {
+		t.Errorf("expected ErrCorrupt at offset %d, got %v", fileHeaderSize, err)
+	}
+
+	// 3. A corrupted length does not allocate beyond the max record size
+	corrupt = append([]byte(nil), good...)
+	corrupt = append(corrupt, 0, 1, 0, 0, 0, 0, 0, 0)
+	corrupt = append(corrupt, make([]byte, 1<<16)...)
+	if err := os.WriteFile(segPath, corrupt, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFileStore(path, FileStoreOptions{MaxRecordSize: 1024, Recover: true}); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt for an oversized record, got %v", err)
+	}
+	if err := os.WriteFile(segPath, good, 0644); err != nil {
+		t.Fatal(err)
+	}
+	store, err = OpenFileStore(path, FileStoreOptions{MaxRecordSize: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := store.Append(&vdcspb.ConfigEntry{Index: 3, Key: "too long"}); !errors.Is(err, ErrRecordTooLarge) {
+		t.Errorf("expected ErrRecordTooLarge, got %v", err)
+	}
+}
+
+func TestFileStoreCorruptLength(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	segPath := filepath.Join(path, segmentName(0))
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(0); i < 5; i++ {
+		if err := store.Append(&vdcspb.ConfigEntry{Index: i, Key: "k"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.Close()
+	good, err := os.ReadFile(segPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := proto.Marshal(&vdcspb.ConfigEntry{Index: 0, Key: "k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := fileHeaderSize + recordHeaderSize + len(first)
+
+	// 1. A corrupted length in the middle of the segment runs past its end,
+	// like a torn record, but the index holds the records after it.
+	corrupt := append([]byte(nil), good...)
+	binary.BigEndian.PutUint32(corrupt[second:], 0x00100000)
+	if err := os.WriteFile(segPath, corrupt, 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = OpenFileStore(path, FileStoreOptions{Recover: true})
+	if !errors.Is(err, ErrCorrupt) || !strings.Contains(err.Error(), fmt.Sprintf("offset %d", second)) {
+		t.Errorf("expected ErrCorrupt at offset %d, got %v", second, err)
+	}
+	if data, err := os.ReadFile(segPath); err != nil || !bytes.Equal(data, corrupt) {
+		t.Errorf("segment was modified: %d bytes, %v", len(data), err)
+	}
+
+	// 2. A length above the max record size is never torn
+	binary.BigEndian.PutUint32(corrupt[second:], 0xffffffff)
+	if err := os.WriteFile(segPath, corrupt, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFileStore(path, FileStoreOptions{Recover: true}); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt, got %v", err)
+	}
+
+	// 3. A complete final record with a bad checksum is corrupt, not torn
+	corrupt = append([]byte(nil), good...)
+	corrupt[len(corrupt)-1]++
+	if err := os.WriteFile(segPath, corrupt, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFileStore(path, FileStoreOptions{Recover: true}); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestFileStoreUpgrade(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.bin")
+
+	// Write a file in the version 0 format: [Length (8 bytes)][Data]
+	var legacy []byte
+	for i := uint64(0); i < 2; i++ {
+		data, _ := proto.Marshal(&vdcspb.ConfigEntry{Index: i, Key: "k"})
+		legacy = binary.BigEndian.AppendUint64(legacy, uint64(len(data)))
+		legacy = append(legacy, data...)
+	}
+	if err := os.WriteFile(path, legacy, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("failed to open version 0 file: %v", err)
+	}
+	if err := store.Append(&vdcspb.ConfigEntry{Index: 2, Key: "k"}); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// The file became the first segment, in the current format
+	data, _ := os.ReadFile(filepath.Join(path, segmentName(0)))
+	if !bytes.HasPrefix(data, []byte(fileMagic)) {
+		t.Error("file was not upgraded")
+	}
+	store, err = NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	entries, err := loadAll(store)
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d, %v", len(entries), err)
+	}
+	for i, e := range entries {
+		if e.Index != uint64(i) {
+			t.Errorf("entry %d has index %d", i, e.Index)
+		}
+	}
+}
+
+func TestFileStoreSegments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	opts := FileStoreOptions{SegmentSize: 64}
+	store, err := OpenFileStore(path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(0); i < 20; i++ {
+		if err := store.Append(&vdcspb.ConfigEntry{Index: i, Key: fmt.Sprintf("key-%d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Append(&vdcspb.ConfigEntry{Index: 42}); !errors.Is(err, ErrOutOfOrder) {
+		t.Errorf("expected ErrOutOfOrder, got %v", err)
+	}
+	store.Close()
+
+	// 1. Segments are named by first index, and all but the last are sealed
+	var names []string
+	files, _ := os.ReadDir(path)
+	for _, f := range files {
+		if _, ok := parseSegmentName(f.Name()); ok {
+			names = append(names, f.Name())
+		}
+	}
+	if len(names) < 3 || names[0] != segmentName(0) {
+		t.Fatalf("expected several segments, got %v", names)
+	}
+	for i, name := range names {
+		info, _ := os.Stat(filepath.Join(path, name))
+		if sealed := info.Mode().Perm()&0222 == 0; sealed != (i < len(names)-1) {
+			t.Errorf("%s: sealed = %v", name, sealed)
+		}
+	}
+
+	// 2. Random access, with and without the index file
+	for _, damage := range []func(){
+		func() {},
+		func() { os.Remove(filepath.Join(path, indexName)) },
+		func() { os.WriteFile(filepath.Join(path, indexName), []byte("garbage"), 0644) },
+	} {
+		damage()
+		store, err := OpenFileStore(path, opts)
+		if err != nil {
+			t.Fatalf("failed to reopen: %v", err)
+		}
+		for _, i := range []uint64{19, 0, 7} {
+			e, err := store.Get(i)
+			if err != nil || e.Key != fmt.Sprintf("key-%d", i) {
+				t.Errorf("Get(%d) = %v, %v", i, e, err)
+			}
+		}
+		if _, err := store.Get(20); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		store.Close()
+	}
+
+	// 3. Corruption in a sealed segment is reported when it is read
+	segPath := filepath.Join(path, names[0])
+	data, _ := os.ReadFile(segPath)
+	data[len(data)-1]++
+	os.Chmod(segPath, 0644)
+	os.WriteFile(segPath, data, 0444)
+	store, err = OpenFileStore(path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if _, err := loadAll(store); !errors.Is(err, ErrCorrupt) || !strings.Contains(err.Error(), names[0]) {
+		t.Errorf("expected ErrCorrupt in %s, got %v", names[0], err)
+	}
+}
+
+func TestStores(t *testing.T) {
+	dir := t.TempDir()
+	stores := map[string]func() (Store, error){
+		"file": func() (Store, error) {
+			return OpenFileStore(filepath.Join(dir, "log"), FileStoreOptions{SegmentSize: 64})
+		},
+		"sqlite": func() (Store, error) { return NewSQLiteStore(filepath.Join(dir, "vdcs.db")) },
+	}
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			store, err := open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+
+			// 1. Empty store
+			if _, ok, err := store.LastIndex(); ok || err != nil {
+				t.Errorf("expected empty store, got %v, %v", ok, err)
+			}
+			if _, err := store.Get(0); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+
+			for i := uint64(0); i < 10; i++ {
+				if err := store.Append(&vdcspb.ConfigEntry{Index: i, Key: fmt.Sprintf("key-%d", i)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// 2. Random access
+			if last, ok, err := store.LastIndex(); last != 9 || !ok || err != nil {
+				t.Errorf("expected last index 9, got %d, %v, %v", last, ok, err)
+			}
+			if e, err := store.Get(4); err != nil || e.Key != "key-4" {
+				t.Errorf("Get(4) = %v, %v", e, err)
+			}
+			entries, err := store.ReadRange(3, 7)
+			if err != nil || len(entries) != 4 || entries[0].Index != 3 || entries[3].Index != 6 {
+				t.Errorf("unexpected range: %v, %v", entries, err)
+			}
+			if entries, err := store.ReadRange(10, 10); err != nil || len(entries) != 0 {
+				t.Errorf("expected empty range at end, got %v, %v", entries, err)
+			}
+			if _, err := store.ReadRange(8, 11); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound past the end, got %v", err)
+			}
+
+			// 3. Iteration from an index, stopped by fn
+			stop := errors.New("stop")
+			var visited []uint64
+			err = store.Iterate(5, func(e *vdcspb.ConfigEntry) error {
+				visited = append(visited, e.Index)
+				if e.Index == 8 {
+					return stop
+				}
+				return nil
+			})
+			if !errors.Is(err, stop) || len(visited) != 4 || visited[0] != 5 {
+				t.Errorf("unexpected iteration: %v, %v", visited, err)
+			}
+		})
+	}
+}
+
+func TestStoreCompact(t *testing.T) {
+	dir := t.TempDir()
+	stores := map[string]func() (Store, error){
+		"file": func() (Store, error) {
+			return OpenFileStore(filepath.Join(dir, "log"), FileStoreOptions{SegmentSize: 64})
+		},
+		"sqlite": func() (Store, error) { return NewSQLiteStore(filepath.Join(dir, "vdcs.db")) },
+	}
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			archive := filepath.Join(dir, name+"-archive")
+			store, err := open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first, err := store.FirstIndex(); first != 0 || err != nil {
+				t.Errorf("expected first index 0, got %d, %v", first, err)
+			}
+			for i := uint64(0); i < 10; i++ {
+				if err := store.Append(&vdcspb.ConfigEntry{Index: i, Key: fmt.Sprintf("key-%d", i)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// 1. Compact moves the entries to the archive
+			first, err := store.Compact(6, archive)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first == 0 || first > 6 {
+				t.Fatalf("unexpected first index %d", first)
+			}
+			if files, _ := os.ReadDir(archive); len(files) == 0 {
+				t.Error("nothing was archived")
+			}
+			store.Close()
+
+			// 2. The entries before the first index are gone after a reopen
+			store, err = open()
+			if err != nil {
+				t.Fatalf("failed to reopen: %v", err)
+			}
+			defer store.Close()
+			if got, err := store.FirstIndex(); got != first || err != nil {
+				t.Errorf("expected first index %d, got %d, %v", first, got, err)
+			}
+			if _, err := store.Get(first - 1); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+			if err := store.Iterate(0, func(*vdcspb.ConfigEntry) error { return nil }); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound iterating from 0, got %v", err)
+			}
+			entries, err := store.ReadRange(first, 10)
+			if err != nil || uint64(len(entries)) != 10-first || entries[0].Index != first {
+				t.Errorf("unexpected range: %v, %v", entries, err)
+			}
+
+			// 3. The last entry is always kept, and appends continue
+			if _, err := store.Compact(100, ""); err != nil {
+				t.Fatal(err)
+			}
+			if last, ok, err := store.LastIndex(); last != 9 || !ok || err != nil {
+				t.Errorf("expected last index 9, got %d, %v, %v", last, ok, err)
+			}
+			if e, err := store.Get(9); err != nil || e.Key != "key-9" {
+				t.Errorf("Get(9) = %v, %v", e, err)
+			}
+			if err := store.Append(&vdcspb.ConfigEntry{Index: 10, Key: "key-10"}); err != nil {
+				t.Errorf("append after compaction failed: %v", err)
+			}
+		})
+	}
+}
+
+// loadAll reads every entry of a store.
+func loadAll(store Store) ([]*vdcspb.ConfigEntry, error) {
+	var entries []*vdcspb.ConfigEntry
+	err := store.Iterate(0, func(e *vdcspb.ConfigEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, err
+}
+
+func TestSQLiteStoreGap(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "vdcs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	for i := uint64(0); i < 5; i++ {
+		if err := store.Append(&vdcspb.ConfigEntry{Index: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.db.Exec("DELETE FROM entries WHERE idx = 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Iterate stops at the missing row, before visiting later ones
+	var visited []uint64
+	err = store.Iterate(0, func(e *vdcspb.ConfigEntry) error {
+		visited = append(visited, e.Index)
+		return nil
+	})
+	if !errors.Is(err, ErrNotFound) || len(visited) != 2 {
+		t.Errorf("expected ErrNotFound after entries 0 and 1, visited %v, got %v", visited, err)
+	}
+	if _, err := store.ReadRange(0, 5); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a range over the gap, got %v", err)
+	}
+}
@@ -28,6 +28,21 @@ const (
 	Operation_OPERATION_UNSPECIFIED Operation = 0
 	Operation_OPERATION_SET         Operation = 1
 	Operation_OPERATION_DELETE      Operation = 2
+	// OPERATION_TRANSACTION applies every entry in ConfigEntry.mutations
+	// atomically. Key, ValueHash and Value of the entry itself are empty.
+	Operation_OPERATION_TRANSACTION Operation = 3
+	// OPERATION_ADD_AUTHOR trusts a new author. Key is "_vdcs/authors/<id>"
+	// and Value is the author's Ed25519 public key.
+	Operation_OPERATION_ADD_AUTHOR Operation = 4
+	// OPERATION_REVOKE_AUTHOR removes a trusted author. Key is
+	// "_vdcs/authors/<id>"; ValueHash and Value are empty.
+	Operation_OPERATION_REVOKE_AUTHOR Operation = 5
+	// OPERATION_ROTATE_KEY replaces the public key of a trusted author. Key is
+	// "_vdcs/authors/<id>" and Value is the new public key.
+	Operation_OPERATION_ROTATE_KEY Operation = 6
+	// OPERATION_UPDATE_POLICY replaces the write policy. Key is "_vdcs/policy"
+	// and Value is the policy as JSON.
+	Operation_OPERATION_UPDATE_POLICY Operation = 7
 )
 
 // Enum value maps for Operation.
@@ -36,11 +51,21 @@ var (
 		0: "OPERATION_UNSPECIFIED",
 		1: "OPERATION_SET",
 		2: "OPERATION_DELETE",
+		3: "OPERATION_TRANSACTION",
+		4: "OPERATION_ADD_AUTHOR",
+		5: "OPERATION_REVOKE_AUTHOR",
+		6: "OPERATION_ROTATE_KEY",
+		7: "OPERATION_UPDATE_POLICY",
 	}
 	Operation_value = map[string]int32{
-		"OPERATION_UNSPECIFIED": 0,
-		"OPERATION_SET":         1,
-		"OPERATION_DELETE":      2,
+		"OPERATION_UNSPECIFIED":   0,
+		"OPERATION_SET":           1,
+		"OPERATION_DELETE":        2,
+		"OPERATION_TRANSACTION":   3,
+		"OPERATION_ADD_AUTHOR":    4,
+		"OPERATION_REVOKE_AUTHOR": 5,
+		"OPERATION_ROTATE_KEY":    6,
+		"OPERATION_UPDATE_POLICY": 7,
 	}
 )
 
@@ -71,62 +96,1787 @@ func (Operation) EnumDescriptor() ([]byte, []int) {
 	return file_proto_vdcs_proto_rawDescGZIP(), []int{0}
 }
 
+type ProposalStatus int32
+
+const (
+	ProposalStatus_PROPOSAL_STATUS_UNSPECIFIED ProposalStatus = 0
+	ProposalStatus_PROPOSAL_STATUS_PENDING     ProposalStatus = 1
+	ProposalStatus_PROPOSAL_STATUS_COMMITTED   ProposalStatus = 2
+	ProposalStatus_PROPOSAL_STATUS_REJECTED    ProposalStatus = 3
+	ProposalStatus_PROPOSAL_STATUS_WITHDRAWN   ProposalStatus = 4
+	ProposalStatus_PROPOSAL_STATUS_EXPIRED     ProposalStatus = 5
+	// PROPOSAL_STATUS_FAILED means the log refused the proposal for a reason
+	// other than missing approvals, e.g. a precondition that no longer holds.
+	ProposalStatus_PROPOSAL_STATUS_FAILED ProposalStatus = 6
+)
+
+// Enum value maps for ProposalStatus.
+var (
+	ProposalStatus_name = map[int32]string{
+		0: "PROPOSAL_STATUS_UNSPECIFIED",
+		1: "PROPOSAL_STATUS_PENDING",
+		2: "PROPOSAL_STATUS_COMMITTED",
+		3: "PROPOSAL_STATUS_REJECTED",
+		4: "PROPOSAL_STATUS_WITHDRAWN",
+		5: "PROPOSAL_STATUS_EXPIRED",
+		6: "PROPOSAL_STATUS_FAILED",
+	}
+	ProposalStatus_value = map[string]int32{
+		"PROPOSAL_STATUS_UNSPECIFIED": 0,
+		"PROPOSAL_STATUS_PENDING":     1,
+		"PROPOSAL_STATUS_COMMITTED":   2,
+		"PROPOSAL_STATUS_REJECTED":    3,
+		"PROPOSAL_STATUS_WITHDRAWN":   4,
+		"PROPOSAL_STATUS_EXPIRED":     5,
+		"PROPOSAL_STATUS_FAILED":      6,
+	}
+)
+
+func (x ProposalStatus) Enum() *ProposalStatus {
+	p := new(ProposalStatus)
+	*p = x
+	return p
+}
+
+func (x ProposalStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ProposalStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_vdcs_proto_enumTypes[1].Descriptor()
+}
+
+func (ProposalStatus) Type() protoreflect.EnumType {
+	return &file_proto_vdcs_proto_enumTypes[1]
+}
+
+func (x ProposalStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ProposalStatus.Descriptor instead.
+func (ProposalStatus) EnumDescriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{1}
+}
+
+// Mutation is a single key change inside a transaction entry.
+type Mutation struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Key       string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	ValueHash []byte                 `protobuf:"bytes,2,opt,name=value_hash,json=valueHash,proto3" json:"value_hash,omitempty"`
+	// operation is OPERATION_SET or OPERATION_DELETE.
+	Operation Operation `protobuf:"varint,3,opt,name=operation,proto3,enum=vdcs.v1.Operation" json:"operation,omitempty"`
+	// value is transported like ConfigEntry.value and bound by value_hash.
+	Value []byte `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	// precondition must hold for key before the transaction is applied.
+	Precondition  *Precondition `protobuf:"bytes,5,opt,name=precondition,proto3" json:"precondition,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Mutation) Reset() {
+	*x = Mutation{}
+	mi := &file_proto_vdcs_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Mutation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mutation) ProtoMessage() {}
+
+func (x *Mutation) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mutation.ProtoReflect.Descriptor instead.
+func (*Mutation) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Mutation) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Mutation) GetValueHash() []byte {
+	if x != nil {
+		return x.ValueHash
+	}
+	return nil
+}
+
+func (x *Mutation) GetOperation() Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return Operation_OPERATION_UNSPECIFIED
+}
+
+func (x *Mutation) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *Mutation) GetPrecondition() *Precondition {
+	if x != nil {
+		return x.Precondition
+	}
+	return nil
+}
+
+// Precondition is a compare-and-swap condition on the current state of a key.
+// It is signed as part of the entry and checked against the state the entry
+// is applied to.
+type Precondition struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Condition:
+	//
+	//	*Precondition_ExpectedValueHash
+	//	*Precondition_MustNotExist
+	//	*Precondition_ExpectedModifiedIndex
+	Condition     isPrecondition_Condition `protobuf_oneof:"condition"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Precondition) Reset() {
+	*x = Precondition{}
+	mi := &file_proto_vdcs_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Precondition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Precondition) ProtoMessage() {}
+
+func (x *Precondition) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Precondition.ProtoReflect.Descriptor instead.
+func (*Precondition) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Precondition) GetCondition() isPrecondition_Condition {
+	if x != nil {
+		return x.Condition
+	}
+	return nil
+}
+
+func (x *Precondition) GetExpectedValueHash() []byte {
+	if x != nil {
+		if x, ok := x.Condition.(*Precondition_ExpectedValueHash); ok {
+			return x.ExpectedValueHash
+		}
+	}
+	return nil
+}
+
+func (x *Precondition) GetMustNotExist() bool {
+	if x != nil {
+		if x, ok := x.Condition.(*Precondition_MustNotExist); ok {
+			return x.MustNotExist
+		}
+	}
+	return false
+}
+
+func (x *Precondition) GetExpectedModifiedIndex() uint64 {
+	if x != nil {
+		if x, ok := x.Condition.(*Precondition_ExpectedModifiedIndex); ok {
+			return x.ExpectedModifiedIndex
+		}
+	}
+	return 0
+}
+
+type isPrecondition_Condition interface {
+	isPrecondition_Condition()
+}
+
+type Precondition_ExpectedValueHash struct {
+	// expected_value_hash requires the key to be set to this value hash.
+	ExpectedValueHash []byte `protobuf:"bytes,1,opt,name=expected_value_hash,json=expectedValueHash,proto3,oneof"`
+}
+
+type Precondition_MustNotExist struct {
+	// must_not_exist requires the key to be absent.
+	MustNotExist bool `protobuf:"varint,2,opt,name=must_not_exist,json=mustNotExist,proto3,oneof"`
+}
+
+type Precondition_ExpectedModifiedIndex struct {
+	// expected_modified_index requires the key to be set, and last set by
+	// the entry at this index.
+	ExpectedModifiedIndex uint64 `protobuf:"varint,3,opt,name=expected_modified_index,json=expectedModifiedIndex,proto3,oneof"`
+}
+
+func (*Precondition_ExpectedValueHash) isPrecondition_Condition() {}
+
+func (*Precondition_MustNotExist) isPrecondition_Condition() {}
+
+func (*Precondition_ExpectedModifiedIndex) isPrecondition_Condition() {}
+
+// PreconditionFailure is attached to a FailedPrecondition status when a
+// precondition does not hold. It describes the current state of the key.
+type PreconditionFailure struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Key    string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Exists bool                   `protobuf:"varint,2,opt,name=exists,proto3" json:"exists,omitempty"`
+	// current_value_hash and modified_index are only set when exists is true.
+	CurrentValueHash []byte `protobuf:"bytes,3,opt,name=current_value_hash,json=currentValueHash,proto3" json:"current_value_hash,omitempty"`
+	ModifiedIndex    uint64 `protobuf:"varint,4,opt,name=modified_index,json=modifiedIndex,proto3" json:"modified_index,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PreconditionFailure) Reset() {
+	*x = PreconditionFailure{}
+	mi := &file_proto_vdcs_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PreconditionFailure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreconditionFailure) ProtoMessage() {}
+
+func (x *PreconditionFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreconditionFailure.ProtoReflect.Descriptor instead.
+func (*PreconditionFailure) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PreconditionFailure) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *PreconditionFailure) GetExists() bool {
+	if x != nil {
+		return x.Exists
+	}
+	return false
+}
+
+func (x *PreconditionFailure) GetCurrentValueHash() []byte {
+	if x != nil {
+		return x.CurrentValueHash
+	}
+	return nil
+}
+
+func (x *PreconditionFailure) GetModifiedIndex() uint64 {
+	if x != nil {
+		return x.ModifiedIndex
+	}
+	return 0
+}
+
+// Intent is a change signed by an author without an index or previous hash.
+// The node sequences it into a ConfigEntry that it signs itself, and carries
+// the intent in ConfigEntry.intent so readers can verify the author's
+// signature.
+type Intent struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	AuthorId string                 `protobuf:"bytes,1,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Key      string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	// operation is OPERATION_SET or OPERATION_DELETE.
+	Operation Operation `protobuf:"varint,3,opt,name=operation,proto3,enum=vdcs.v1.Operation" json:"operation,omitempty"`
+	ValueHash []byte    `protobuf:"bytes,4,opt,name=value_hash,json=valueHash,proto3" json:"value_hash,omitempty"`
+	// nonce makes each intent unique. An intent is sequenced at most once.
+	Nonce []byte `protobuf:"bytes,5,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	// expires_at is the Unix nanos after which the intent may not be sequenced.
+	ExpiresAt    int64         `protobuf:"varint,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Precondition *Precondition `protobuf:"bytes,7,opt,name=precondition,proto3" json:"precondition,omitempty"`
+	// signature is the Ed25519 signature of the intent hash (see
+	// log.EncodeIntent) by author_id.
+	Signature []byte `protobuf:"bytes,8,opt,name=signature,proto3" json:"signature,omitempty"`
+	// value is transported like ConfigEntry.value and bound by value_hash.
+	Value []byte `protobuf:"bytes,9,opt,name=value,proto3" json:"value,omitempty"`
+	// approvals are co-signatures of the intent hash by other authors.
+	Approvals     []*Approval `protobuf:"bytes,10,rep,name=approvals,proto3" json:"approvals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Intent) Reset() {
+	*x = Intent{}
+	mi := &file_proto_vdcs_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Intent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Intent) ProtoMessage() {}
+
+func (x *Intent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Intent.ProtoReflect.Descriptor instead.
+func (*Intent) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Intent) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *Intent) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Intent) GetOperation() Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return Operation_OPERATION_UNSPECIFIED
+}
+
+func (x *Intent) GetValueHash() []byte {
+	if x != nil {
+		return x.ValueHash
+	}
+	return nil
+}
+
+func (x *Intent) GetNonce() []byte {
+	if x != nil {
+		return x.Nonce
+	}
+	return nil
+}
+
+func (x *Intent) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *Intent) GetPrecondition() *Precondition {
+	if x != nil {
+		return x.Precondition
+	}
+	return nil
+}
+
+func (x *Intent) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *Intent) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *Intent) GetApprovals() []*Approval {
+	if x != nil {
+		return x.Approvals
+	}
+	return nil
+}
+
+// Approval is a co-signature by a trusted author other than the one who
+// proposed the change. It signs the same hash as the proposer: the EntryHash,
+// or the intent hash of a sequenced entry.
+type Approval struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AuthorId      string                 `protobuf:"bytes,1,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Signature     []byte                 `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Approval) Reset() {
+	*x = Approval{}
+	mi := &file_proto_vdcs_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Approval) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Approval) ProtoMessage() {}
+
+func (x *Approval) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Approval.ProtoReflect.Descriptor instead.
+func (*Approval) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Approval) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *Approval) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
 // ConfigEntry is an immutable record of a configuration change.
 // This is the fundamental unit of the append-only log.
 type ConfigEntry struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Index is the monotonic sequence number of the entry in the log.
-	Index uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
-	// Timestamp is the Unix nanos when the entry was created.
-	Timestamp int64 `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	// AuthorID is the identifier of the entity making the change (e.g., public key or user ID).
-	AuthorId string `protobuf:"bytes,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
-	// Key is the configuration key being modified.
-	Key string `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
-	// ValueHash is the SHA-256 hash of the value.
-	// We store the hash in the log entry to keep the log lightweight and
-	// to allow separate heavy value storage if needed (though v1 may inline values).
-	// For v1, we might want to include the Value itself if small, but the spec says "ValueHash".
-	// Let's stick to the spec strictly: "ValueHash".
-	// Note: If we only store ValueHash, we need a way to transport the actual Value.
-	// The spec's "Materialized State" implies we have the values.
-	// Let's add `bytes value` as an optional field for transport, but the signature
-	// should arguably cover the semantic content.
-	// The User Spec said: "ValueHash [32]byte".
-	// We will adhere to that for the signed envelope.
-	ValueHash []byte `protobuf:"bytes,5,opt,name=value_hash,json=valueHash,proto3" json:"value_hash,omitempty"`
-	// Operation is the type of change (SET or DELETE).
-	Operation Operation `protobuf:"varint,6,opt,name=operation,proto3,enum=vdcs.v1.Operation" json:"operation,omitempty"`
-	// PrevHash is the SHA-256 hash of the previous log entry.
-	// This creates the hash chain.
-	PrevHash []byte `protobuf:"bytes,7,opt,name=prev_hash,json=prevHash,proto3" json:"prev_hash,omitempty"`
-	// EntryHash is the SHA-256 hash of this entry (excluding Signature?).
-	// Usually this is computed, not stored, or stored for convenience.
-	// The signature covers this hash.
-	EntryHash []byte `protobuf:"bytes,8,opt,name=entry_hash,json=entryHash,proto3" json:"entry_hash,omitempty"`
-	// Signature is the Ed25519 signature of EntryHash by AuthorID.
-	Signature []byte `protobuf:"bytes,9,opt,name=signature,proto3" json:"signature,omitempty"`
-	// ... (previous fields)
-	Value         []byte `protobuf:"bytes,10,opt,name=value,proto3" json:"value,omitempty"`
+	// Index is the monotonic sequence number of the entry in the log.
+	Index uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// Timestamp is the Unix nanos when the entry was created.
+	Timestamp int64 `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// AuthorID is the identifier of the entity making the change (e.g., public key or user ID).
+	AuthorId string `protobuf:"bytes,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	// Key is the configuration key being modified.
+	Key string `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
+	// ValueHash is the SHA-256 hash of the value.
+	// We store the hash in the log entry to keep the log lightweight and
+	// to allow separate heavy value storage if needed (though v1 may inline values).
+	// For v1, we might want to include the Value itself if small, but the spec says "ValueHash".
+	// Let's stick to the spec strictly: "ValueHash".
+	// Note: If we only store ValueHash, we need a way to transport the actual Value.
+	// The spec's "Materialized State" implies we have the values.
+	// Let's add `bytes value` as an optional field for transport, but the signature
+	// should arguably cover the semantic content.
+	// The User Spec said: "ValueHash [32]byte".
+	// We will adhere to that for the signed envelope.
+	ValueHash []byte `protobuf:"bytes,5,opt,name=value_hash,json=valueHash,proto3" json:"value_hash,omitempty"`
+	// Operation is the type of change (SET or DELETE).
+	Operation Operation `protobuf:"varint,6,opt,name=operation,proto3,enum=vdcs.v1.Operation" json:"operation,omitempty"`
+	// PrevHash is the SHA-256 hash of the previous log entry.
+	// This creates the hash chain.
+	PrevHash []byte `protobuf:"bytes,7,opt,name=prev_hash,json=prevHash,proto3" json:"prev_hash,omitempty"`
+	// EntryHash is the SHA-256 hash of the canonical encoding of this entry
+	// (see log.EncodeEntry). It excludes EntryHash, Signature, Value and
+	// Approvals.
+	// The signature covers this hash.
+	EntryHash []byte `protobuf:"bytes,8,opt,name=entry_hash,json=entryHash,proto3" json:"entry_hash,omitempty"`
+	// Signature is the Ed25519 signature of EntryHash by AuthorID.
+	Signature []byte `protobuf:"bytes,9,opt,name=signature,proto3" json:"signature,omitempty"`
+	// Value is the raw value, transported alongside the entry. It is not part
+	// of EntryHash; it is bound to the entry by ValueHash.
+	Value []byte `protobuf:"bytes,10,opt,name=value,proto3" json:"value,omitempty"`
+	// Mutations are the key changes of an OPERATION_TRANSACTION entry.
+	// They are signed once, as part of EntryHash, and applied atomically.
+	Mutations []*Mutation `protobuf:"bytes,11,rep,name=mutations,proto3" json:"mutations,omitempty"`
+	// Precondition must hold for Key before the entry is applied. Transactions
+	// set preconditions on their mutations instead.
+	Precondition *Precondition `protobuf:"bytes,12,opt,name=precondition,proto3" json:"precondition,omitempty"`
+	// Intent is set on sequenced entries. AuthorID is then the node that
+	// sequenced the intent, and Key, Operation, ValueHash, Value and
+	// Precondition repeat those of the intent.
+	Intent *Intent `protobuf:"bytes,13,opt,name=intent,proto3" json:"intent,omitempty"`
+	// Approvals are co-signatures of EntryHash by other authors, for keys that
+	// require more than one signer. Like Signature, they are not part of
+	// EntryHash. Sequenced entries carry approvals in the intent instead.
+	Approvals     []*Approval `protobuf:"bytes,14,rep,name=approvals,proto3" json:"approvals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigEntry) Reset() {
+	*x = ConfigEntry{}
+	mi := &file_proto_vdcs_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigEntry) ProtoMessage() {}
+
+func (x *ConfigEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigEntry.ProtoReflect.Descriptor instead.
+func (*ConfigEntry) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ConfigEntry) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ConfigEntry) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *ConfigEntry) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *ConfigEntry) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ConfigEntry) GetValueHash() []byte {
+	if x != nil {
+		return x.ValueHash
+	}
+	return nil
+}
+
+func (x *ConfigEntry) GetOperation() Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return Operation_OPERATION_UNSPECIFIED
+}
+
+func (x *ConfigEntry) GetPrevHash() []byte {
+	if x != nil {
+		return x.PrevHash
+	}
+	return nil
+}
+
+func (x *ConfigEntry) GetEntryHash() []byte {
+	if x != nil {
+		return x.EntryHash
+	}
+	return nil
+}
+
+func (x *ConfigEntry) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *ConfigEntry) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *ConfigEntry) GetMutations() []*Mutation {
+	if x != nil {
+		return x.Mutations
+	}
+	return nil
+}
+
+func (x *ConfigEntry) GetPrecondition() *Precondition {
+	if x != nil {
+		return x.Precondition
+	}
+	return nil
+}
+
+func (x *ConfigEntry) GetIntent() *Intent {
+	if x != nil {
+		return x.Intent
+	}
+	return nil
+}
+
+func (x *ConfigEntry) GetApprovals() []*Approval {
+	if x != nil {
+		return x.Approvals
+	}
+	return nil
+}
+
+type ConfigState struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       uint64                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	StateRoot     []byte                 `protobuf:"bytes,2,opt,name=state_root,json=stateRoot,proto3" json:"state_root,omitempty"`
+	LastEntryHash []byte                 `protobuf:"bytes,3,opt,name=last_entry_hash,json=lastEntryHash,proto3" json:"last_entry_hash,omitempty"`
+	// tree_format_version identifies the hashing scheme that produced
+	// state_root. 0 means the unversioned scheme without domain separation.
+	TreeFormatVersion uint32 `protobuf:"varint,4,opt,name=tree_format_version,json=treeFormatVersion,proto3" json:"tree_format_version,omitempty"`
+	// log_size is the number of entries in the log, i.e. the next index.
+	LogSize uint64 `protobuf:"varint,5,opt,name=log_size,json=logSize,proto3" json:"log_size,omitempty"`
+	// log_root is the RFC 6962 Merkle root over the EntryHash of every entry.
+	LogRoot []byte `protobuf:"bytes,6,opt,name=log_root,json=logRoot,proto3" json:"log_root,omitempty"`
+	// checkpoint is the node's signature over log_size, log_root and state_root.
+	Checkpoint    *SignedCheckpoint `protobuf:"bytes,7,opt,name=checkpoint,proto3" json:"checkpoint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigState) Reset() {
+	*x = ConfigState{}
+	mi := &file_proto_vdcs_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigState) ProtoMessage() {}
+
+func (x *ConfigState) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigState.ProtoReflect.Descriptor instead.
+func (*ConfigState) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ConfigState) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ConfigState) GetStateRoot() []byte {
+	if x != nil {
+		return x.StateRoot
+	}
+	return nil
+}
+
+func (x *ConfigState) GetLastEntryHash() []byte {
+	if x != nil {
+		return x.LastEntryHash
+	}
+	return nil
+}
+
+func (x *ConfigState) GetTreeFormatVersion() uint32 {
+	if x != nil {
+		return x.TreeFormatVersion
+	}
+	return 0
+}
+
+func (x *ConfigState) GetLogSize() uint64 {
+	if x != nil {
+		return x.LogSize
+	}
+	return 0
+}
+
+func (x *ConfigState) GetLogRoot() []byte {
+	if x != nil {
+		return x.LogRoot
+	}
+	return nil
+}
+
+func (x *ConfigState) GetCheckpoint() *SignedCheckpoint {
+	if x != nil {
+		return x.Checkpoint
+	}
+	return nil
+}
+
+// SignedCheckpoint is a tree head signed by the node's Ed25519 key.
+// The body encoding is documented in internal/checkpoint.
+type SignedCheckpoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Body          []byte                 `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+	Signature     []byte                 `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignedCheckpoint) Reset() {
+	*x = SignedCheckpoint{}
+	mi := &file_proto_vdcs_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignedCheckpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignedCheckpoint) ProtoMessage() {}
+
+func (x *SignedCheckpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignedCheckpoint.ProtoReflect.Descriptor instead.
+func (*SignedCheckpoint) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SignedCheckpoint) GetBody() []byte {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+func (x *SignedCheckpoint) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_proto_vdcs_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{8}
+}
+
+type ProposeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Success or error details are in gRPC status.
+	// index and entry_hash identify the appended entry.
+	Index     uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	EntryHash []byte `protobuf:"bytes,2,opt,name=entry_hash,json=entryHash,proto3" json:"entry_hash,omitempty"`
+	// receipt is the node's signed promise to include the entry in its
+	// checkpoints within the receipt's maximum merge delay.
+	Receipt       *SignedReceipt `protobuf:"bytes,3,opt,name=receipt,proto3" json:"receipt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProposeResponse) Reset() {
+	*x = ProposeResponse{}
+	mi := &file_proto_vdcs_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProposeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProposeResponse) ProtoMessage() {}
+
+func (x *ProposeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProposeResponse.ProtoReflect.Descriptor instead.
+func (*ProposeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ProposeResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ProposeResponse) GetEntryHash() []byte {
+	if x != nil {
+		return x.EntryHash
+	}
+	return nil
+}
+
+func (x *ProposeResponse) GetReceipt() *SignedReceipt {
+	if x != nil {
+		return x.Receipt
+	}
+	return nil
+}
+
+// SignedReceipt is a receipt signed by the node's Ed25519 key.
+// The body encoding is documented in internal/checkpoint.
+type SignedReceipt struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Body          []byte                 `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+	Signature     []byte                 `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignedReceipt) Reset() {
+	*x = SignedReceipt{}
+	mi := &file_proto_vdcs_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignedReceipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignedReceipt) ProtoMessage() {}
+
+func (x *SignedReceipt) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignedReceipt.ProtoReflect.Descriptor instead.
+func (*SignedReceipt) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SignedReceipt) GetBody() []byte {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+func (x *SignedReceipt) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+type GetProofRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProofRequest) Reset() {
+	*x = GetProofRequest{}
+	mi := &file_proto_vdcs_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProofRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProofRequest) ProtoMessage() {}
+
+func (x *GetProofRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProofRequest.ProtoReflect.Descriptor instead.
+func (*GetProofRequest) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetProofRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+// GetProofResponse is a sparse Merkle tree proof against ConfigState.state_root.
+type GetProofResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// value_hash is only set when exists is true.
+	ValueHash []byte `protobuf:"bytes,2,opt,name=value_hash,json=valueHash,proto3" json:"value_hash,omitempty"`
+	// siblings along the path of SHA-256(key), deepest first.
+	Siblings [][]byte `protobuf:"bytes,3,rep,name=siblings,proto3" json:"siblings,omitempty"`
+	Exists   bool     `protobuf:"varint,5,opt,name=exists,proto3" json:"exists,omitempty"`
+	// leaf_key_hash and leaf_value_hash describe another key's leaf occupying
+	// the path of key. Only set for non-inclusion proofs.
+	LeafKeyHash   []byte `protobuf:"bytes,6,opt,name=leaf_key_hash,json=leafKeyHash,proto3" json:"leaf_key_hash,omitempty"`
+	LeafValueHash []byte `protobuf:"bytes,7,opt,name=leaf_value_hash,json=leafValueHash,proto3" json:"leaf_value_hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProofResponse) Reset() {
+	*x = GetProofResponse{}
+	mi := &file_proto_vdcs_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProofResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProofResponse) ProtoMessage() {}
+
+func (x *GetProofResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProofResponse.ProtoReflect.Descriptor instead.
+func (*GetProofResponse) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetProofResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *GetProofResponse) GetValueHash() []byte {
+	if x != nil {
+		return x.ValueHash
+	}
+	return nil
+}
+
+func (x *GetProofResponse) GetSiblings() [][]byte {
+	if x != nil {
+		return x.Siblings
+	}
+	return nil
+}
+
+func (x *GetProofResponse) GetExists() bool {
+	if x != nil {
+		return x.Exists
+	}
+	return false
+}
+
+func (x *GetProofResponse) GetLeafKeyHash() []byte {
+	if x != nil {
+		return x.LeafKeyHash
+	}
+	return nil
+}
+
+func (x *GetProofResponse) GetLeafValueHash() []byte {
+	if x != nil {
+		return x.LeafValueHash
+	}
+	return nil
+}
+
+type GetValueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetValueRequest) Reset() {
+	*x = GetValueRequest{}
+	mi := &file_proto_vdcs_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetValueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetValueRequest) ProtoMessage() {}
+
+func (x *GetValueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetValueRequest.ProtoReflect.Descriptor instead.
+func (*GetValueRequest) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetValueRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type GetValueResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// value is set if has_value is. Clients must check SHA-256(value) ==
+	// proof.value_hash.
+	Value []byte            `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Proof *GetProofResponse `protobuf:"bytes,2,opt,name=proof,proto3" json:"proof,omitempty"`
+	// state is the head the proof verifies against.
+	State *ConfigState `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	// has_value is false if the key is absent or was set without an inline
+	// value, and true if value holds it, even when it is empty.
+	HasValue      bool `protobuf:"varint,4,opt,name=has_value,json=hasValue,proto3" json:"has_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetValueResponse) Reset() {
+	*x = GetValueResponse{}
+	mi := &file_proto_vdcs_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetValueResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetValueResponse) ProtoMessage() {}
+
+func (x *GetValueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetValueResponse.ProtoReflect.Descriptor instead.
+func (*GetValueResponse) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetValueResponse) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *GetValueResponse) GetProof() *GetProofResponse {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+func (x *GetValueResponse) GetState() *ConfigState {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+func (x *GetValueResponse) GetHasValue() bool {
+	if x != nil {
+		return x.HasValue
+	}
+	return false
+}
+
+type GetInclusionProofRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         uint64                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	TreeSize      uint64                 `protobuf:"varint,2,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInclusionProofRequest) Reset() {
+	*x = GetInclusionProofRequest{}
+	mi := &file_proto_vdcs_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInclusionProofRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInclusionProofRequest) ProtoMessage() {}
+
+func (x *GetInclusionProofRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInclusionProofRequest.ProtoReflect.Descriptor instead.
+func (*GetInclusionProofRequest) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetInclusionProofRequest) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *GetInclusionProofRequest) GetTreeSize() uint64 {
+	if x != nil {
+		return x.TreeSize
+	}
+	return 0
+}
+
+type GetInclusionProofResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Index    uint64                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	TreeSize uint64                 `protobuf:"varint,2,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
+	// hashes is the audit path from the leaf up to the root (RFC 9162 2.1.3).
+	Hashes        [][]byte `protobuf:"bytes,3,rep,name=hashes,proto3" json:"hashes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInclusionProofResponse) Reset() {
+	*x = GetInclusionProofResponse{}
+	mi := &file_proto_vdcs_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInclusionProofResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInclusionProofResponse) ProtoMessage() {}
+
+func (x *GetInclusionProofResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInclusionProofResponse.ProtoReflect.Descriptor instead.
+func (*GetInclusionProofResponse) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetInclusionProofResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *GetInclusionProofResponse) GetTreeSize() uint64 {
+	if x != nil {
+		return x.TreeSize
+	}
+	return 0
+}
+
+func (x *GetInclusionProofResponse) GetHashes() [][]byte {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+type GetConsistencyProofRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	First         uint64                 `protobuf:"varint,1,opt,name=first,proto3" json:"first,omitempty"`
+	Second        uint64                 `protobuf:"varint,2,opt,name=second,proto3" json:"second,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConsistencyProofRequest) Reset() {
+	*x = GetConsistencyProofRequest{}
+	mi := &file_proto_vdcs_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConsistencyProofRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConsistencyProofRequest) ProtoMessage() {}
+
+func (x *GetConsistencyProofRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConsistencyProofRequest.ProtoReflect.Descriptor instead.
+func (*GetConsistencyProofRequest) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetConsistencyProofRequest) GetFirst() uint64 {
+	if x != nil {
+		return x.First
+	}
+	return 0
+}
+
+func (x *GetConsistencyProofRequest) GetSecond() uint64 {
+	if x != nil {
+		return x.Second
+	}
+	return 0
+}
+
+type GetConsistencyProofResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	First  uint64                 `protobuf:"varint,1,opt,name=first,proto3" json:"first,omitempty"`
+	Second uint64                 `protobuf:"varint,2,opt,name=second,proto3" json:"second,omitempty"`
+	// hashes is the consistency proof (RFC 9162 2.1.4).
+	Hashes        [][]byte `protobuf:"bytes,3,rep,name=hashes,proto3" json:"hashes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConsistencyProofResponse) Reset() {
+	*x = GetConsistencyProofResponse{}
+	mi := &file_proto_vdcs_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConsistencyProofResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConsistencyProofResponse) ProtoMessage() {}
+
+func (x *GetConsistencyProofResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConsistencyProofResponse.ProtoReflect.Descriptor instead.
+func (*GetConsistencyProofResponse) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetConsistencyProofResponse) GetFirst() uint64 {
+	if x != nil {
+		return x.First
+	}
+	return 0
+}
+
+func (x *GetConsistencyProofResponse) GetSecond() uint64 {
+	if x != nil {
+		return x.Second
+	}
+	return 0
+}
+
+func (x *GetConsistencyProofResponse) GetHashes() [][]byte {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// key_prefix selects entries that change at least one matching key.
+	// An empty prefix matches every entry.
+	KeyPrefix string `protobuf:"bytes,1,opt,name=key_prefix,json=keyPrefix,proto3" json:"key_prefix,omitempty"`
+	// start_index is the first index to send. To resume, pass the index after
+	// the last event received.
+	StartIndex uint64 `protobuf:"varint,2,opt,name=start_index,json=startIndex,proto3" json:"start_index,omitempty"`
+	// known_log_size is the log size the client has already verified. The
+	// first event carries a consistency proof from it.
+	KnownLogSize  uint64 `protobuf:"varint,3,opt,name=known_log_size,json=knownLogSize,proto3" json:"known_log_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_proto_vdcs_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *WatchRequest) GetKeyPrefix() string {
+	if x != nil {
+		return x.KeyPrefix
+	}
+	return ""
+}
+
+func (x *WatchRequest) GetStartIndex() uint64 {
+	if x != nil {
+		return x.StartIndex
+	}
+	return 0
+}
+
+func (x *WatchRequest) GetKnownLogSize() uint64 {
+	if x != nil {
+		return x.KnownLogSize
+	}
+	return 0
+}
+
+// WatchEvent is a committed entry with the proofs needed to verify it
+// against a signed head, and that head against the previous event's.
+type WatchEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Entry *ConfigEntry           `protobuf:"bytes,1,opt,name=entry,proto3" json:"entry,omitempty"`
+	// state is the head the proofs verify against. state.checkpoint is signed.
+	State *ConfigState `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	// inclusion_proof proves entry is in the log tree of size state.log_size.
+	InclusionProof [][]byte `protobuf:"bytes,3,rep,name=inclusion_proof,json=inclusionProof,proto3" json:"inclusion_proof,omitempty"`
+	// consistency_proof proves the log tree of size state.log_size extends the
+	// one of size previous_log_size: the previous event's log size, or
+	// known_log_size for the first event.
+	PreviousLogSize  uint64   `protobuf:"varint,4,opt,name=previous_log_size,json=previousLogSize,proto3" json:"previous_log_size,omitempty"`
+	ConsistencyProof [][]byte `protobuf:"bytes,5,rep,name=consistency_proof,json=consistencyProof,proto3" json:"consistency_proof,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *WatchEvent) Reset() {
+	*x = WatchEvent{}
+	mi := &file_proto_vdcs_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEvent) ProtoMessage() {}
+
+func (x *WatchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEvent.ProtoReflect.Descriptor instead.
+func (*WatchEvent) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *WatchEvent) GetEntry() *ConfigEntry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+func (x *WatchEvent) GetState() *ConfigState {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+func (x *WatchEvent) GetInclusionProof() [][]byte {
+	if x != nil {
+		return x.InclusionProof
+	}
+	return nil
+}
+
+func (x *WatchEvent) GetPreviousLogSize() uint64 {
+	if x != nil {
+		return x.PreviousLogSize
+	}
+	return 0
+}
+
+func (x *WatchEvent) GetConsistencyProof() [][]byte {
+	if x != nil {
+		return x.ConsistencyProof
+	}
+	return nil
+}
+
+type GetEntriesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// start is the first index to return.
+	Start uint64 `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	// end is one past the last index to return. 0 means the end of the log.
+	End uint64 `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	// page_size is the maximum number of entries to return. The node caps it,
+	// and uses its default if it is 0.
+	PageSize      uint32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEntriesRequest) Reset() {
+	*x = GetEntriesRequest{}
+	mi := &file_proto_vdcs_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEntriesRequest) ProtoMessage() {}
+
+func (x *GetEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEntriesRequest.ProtoReflect.Descriptor instead.
+func (*GetEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetEntriesRequest) GetStart() uint64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *GetEntriesRequest) GetEnd() uint64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *GetEntriesRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type GetEntriesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// entries are in index order, starting at the requested start.
+	Entries []*ConfigEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	// next_index is the start of the next page. It equals the requested end
+	// (or log_size) when the range is complete.
+	NextIndex uint64 `protobuf:"varint,2,opt,name=next_index,json=nextIndex,proto3" json:"next_index,omitempty"`
+	// log_size is the number of entries in the log when the page was read.
+	LogSize       uint64 `protobuf:"varint,3,opt,name=log_size,json=logSize,proto3" json:"log_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEntriesResponse) Reset() {
+	*x = GetEntriesResponse{}
+	mi := &file_proto_vdcs_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEntriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEntriesResponse) ProtoMessage() {}
+
+func (x *GetEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEntriesResponse.ProtoReflect.Descriptor instead.
+func (*GetEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetEntriesResponse) GetEntries() []*ConfigEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *GetEntriesResponse) GetNextIndex() uint64 {
+	if x != nil {
+		return x.NextIndex
+	}
+	return 0
+}
+
+func (x *GetEntriesResponse) GetLogSize() uint64 {
+	if x != nil {
+		return x.LogSize
+	}
+	return 0
+}
+
+// Proposal is a signed intent waiting for approvals. Its intent carries the
+// approvals collected so far.
+type Proposal struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id is the hex intent hash.
+	Id     string         `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Intent *Intent        `protobuf:"bytes,2,opt,name=intent,proto3" json:"intent,omitempty"`
+	Status ProposalStatus `protobuf:"varint,3,opt,name=status,proto3,enum=vdcs.v1.ProposalStatus" json:"status,omitempty"`
+	// submitted_at and closed_at are Unix nanos. closed_at is 0 while pending.
+	SubmittedAt int64 `protobuf:"varint,4,opt,name=submitted_at,json=submittedAt,proto3" json:"submitted_at,omitempty"`
+	ClosedAt    int64 `protobuf:"varint,5,opt,name=closed_at,json=closedAt,proto3" json:"closed_at,omitempty"`
+	// index is the log index of the committed entry.
+	Index uint64 `protobuf:"varint,6,opt,name=index,proto3" json:"index,omitempty"`
+	// reason explains a rejection or failure.
+	Reason string `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+	// current is the state of the key when the proposal was read, to diff
+	// against the intent.
+	Current       *KeyState `protobuf:"bytes,8,opt,name=current,proto3" json:"current,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ConfigEntry) Reset() {
-	*x = ConfigEntry{}
-	mi := &file_proto_vdcs_proto_msgTypes[0]
+func (x *Proposal) Reset() {
+	*x = Proposal{}
+	mi := &file_proto_vdcs_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ConfigEntry) String() string {
+func (x *Proposal) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ConfigEntry) ProtoMessage() {}
+func (*Proposal) ProtoMessage() {}
 
-func (x *ConfigEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_vdcs_proto_msgTypes[0]
+func (x *Proposal) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -137,105 +1887,162 @@ func (x *ConfigEntry) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ConfigEntry.ProtoReflect.Descriptor instead.
-func (*ConfigEntry) Descriptor() ([]byte, []int) {
-	return file_proto_vdcs_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use Proposal.ProtoReflect.Descriptor instead.
+func (*Proposal) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *ConfigEntry) GetIndex() uint64 {
+func (x *Proposal) GetId() string {
 	if x != nil {
-		return x.Index
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Proposal) GetIntent() *Intent {
+	if x != nil {
+		return x.Intent
+	}
+	return nil
+}
+
+func (x *Proposal) GetStatus() ProposalStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ProposalStatus_PROPOSAL_STATUS_UNSPECIFIED
+}
+
+func (x *Proposal) GetSubmittedAt() int64 {
+	if x != nil {
+		return x.SubmittedAt
 	}
 	return 0
 }
 
-func (x *ConfigEntry) GetTimestamp() int64 {
+func (x *Proposal) GetClosedAt() int64 {
 	if x != nil {
-		return x.Timestamp
+		return x.ClosedAt
 	}
 	return 0
 }
 
-func (x *ConfigEntry) GetAuthorId() string {
+func (x *Proposal) GetIndex() uint64 {
 	if x != nil {
-		return x.AuthorId
+		return x.Index
 	}
-	return ""
+	return 0
 }
 
-func (x *ConfigEntry) GetKey() string {
+func (x *Proposal) GetReason() string {
 	if x != nil {
-		return x.Key
+		return x.Reason
 	}
 	return ""
 }
 
-func (x *ConfigEntry) GetValueHash() []byte {
+func (x *Proposal) GetCurrent() *KeyState {
 	if x != nil {
-		return x.ValueHash
+		return x.Current
 	}
 	return nil
 }
 
-func (x *ConfigEntry) GetOperation() Operation {
+// KeyState is the committed state of a key.
+type KeyState struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Exists bool                   `protobuf:"varint,1,opt,name=exists,proto3" json:"exists,omitempty"`
+	// value_hash, value and modified_index are only set when exists is true.
+	// value is empty if the key was set without an inline value.
+	ValueHash     []byte `protobuf:"bytes,2,opt,name=value_hash,json=valueHash,proto3" json:"value_hash,omitempty"`
+	Value         []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	ModifiedIndex uint64 `protobuf:"varint,4,opt,name=modified_index,json=modifiedIndex,proto3" json:"modified_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeyState) Reset() {
+	*x = KeyState{}
+	mi := &file_proto_vdcs_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyState) ProtoMessage() {}
+
+func (x *KeyState) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[24]
 	if x != nil {
-		return x.Operation
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return Operation_OPERATION_UNSPECIFIED
+	return mi.MessageOf(x)
 }
 
-func (x *ConfigEntry) GetPrevHash() []byte {
+// Deprecated: Use KeyState.ProtoReflect.Descriptor instead.
+func (*KeyState) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *KeyState) GetExists() bool {
 	if x != nil {
-		return x.PrevHash
+		return x.Exists
 	}
-	return nil
+	return false
 }
 
-func (x *ConfigEntry) GetEntryHash() []byte {
+func (x *KeyState) GetValueHash() []byte {
 	if x != nil {
-		return x.EntryHash
+		return x.ValueHash
 	}
 	return nil
 }
 
-func (x *ConfigEntry) GetSignature() []byte {
+func (x *KeyState) GetValue() []byte {
 	if x != nil {
-		return x.Signature
+		return x.Value
 	}
 	return nil
 }
 
-func (x *ConfigEntry) GetValue() []byte {
+func (x *KeyState) GetModifiedIndex() uint64 {
 	if x != nil {
-		return x.Value
+		return x.ModifiedIndex
 	}
-	return nil
+	return 0
 }
 
-type ConfigState struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Version       uint64                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
-	StateRoot     []byte                 `protobuf:"bytes,2,opt,name=state_root,json=stateRoot,proto3" json:"state_root,omitempty"`
-	LastEntryHash []byte                 `protobuf:"bytes,3,opt,name=last_entry_hash,json=lastEntryHash,proto3" json:"last_entry_hash,omitempty"`
+type ProposalResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Proposal *Proposal              `protobuf:"bytes,1,opt,name=proposal,proto3" json:"proposal,omitempty"`
+	// committed is set if this call committed the proposal.
+	Committed     *ProposeResponse `protobuf:"bytes,2,opt,name=committed,proto3" json:"committed,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ConfigState) Reset() {
-	*x = ConfigState{}
-	mi := &file_proto_vdcs_proto_msgTypes[1]
+func (x *ProposalResponse) Reset() {
+	*x = ProposalResponse{}
+	mi := &file_proto_vdcs_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ConfigState) String() string {
+func (x *ProposalResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ConfigState) ProtoMessage() {}
+func (*ProposalResponse) ProtoMessage() {}
 
-func (x *ConfigState) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_vdcs_proto_msgTypes[1]
+func (x *ProposalResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -246,53 +2053,101 @@ func (x *ConfigState) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ConfigState.ProtoReflect.Descriptor instead.
-func (*ConfigState) Descriptor() ([]byte, []int) {
-	return file_proto_vdcs_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use ProposalResponse.ProtoReflect.Descriptor instead.
+func (*ProposalResponse) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *ConfigState) GetVersion() uint64 {
+func (x *ProposalResponse) GetProposal() *Proposal {
 	if x != nil {
-		return x.Version
+		return x.Proposal
 	}
-	return 0
+	return nil
 }
 
-func (x *ConfigState) GetStateRoot() []byte {
+func (x *ProposalResponse) GetCommitted() *ProposeResponse {
 	if x != nil {
-		return x.StateRoot
+		return x.Committed
 	}
 	return nil
 }
 
-func (x *ConfigState) GetLastEntryHash() []byte {
+type ListProposalsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// key_prefix limits the list to proposals for matching keys.
+	KeyPrefix string `protobuf:"bytes,1,opt,name=key_prefix,json=keyPrefix,proto3" json:"key_prefix,omitempty"`
+	// include_closed also returns recently closed proposals.
+	IncludeClosed bool `protobuf:"varint,2,opt,name=include_closed,json=includeClosed,proto3" json:"include_closed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProposalsRequest) Reset() {
+	*x = ListProposalsRequest{}
+	mi := &file_proto_vdcs_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProposalsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProposalsRequest) ProtoMessage() {}
+
+func (x *ListProposalsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[26]
 	if x != nil {
-		return x.LastEntryHash
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-type Empty struct {
+// Deprecated: Use ListProposalsRequest.ProtoReflect.Descriptor instead.
+func (*ListProposalsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListProposalsRequest) GetKeyPrefix() string {
+	if x != nil {
+		return x.KeyPrefix
+	}
+	return ""
+}
+
+func (x *ListProposalsRequest) GetIncludeClosed() bool {
+	if x != nil {
+		return x.IncludeClosed
+	}
+	return false
+}
+
+type ListProposalsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Proposals     []*Proposal            `protobuf:"bytes,1,rep,name=proposals,proto3" json:"proposals,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Empty) Reset() {
-	*x = Empty{}
-	mi := &file_proto_vdcs_proto_msgTypes[2]
+func (x *ListProposalsResponse) Reset() {
+	*x = ListProposalsResponse{}
+	mi := &file_proto_vdcs_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Empty) String() string {
+func (x *ListProposalsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Empty) ProtoMessage() {}
+func (*ListProposalsResponse) ProtoMessage() {}
 
-func (x *Empty) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_vdcs_proto_msgTypes[2]
+func (x *ListProposalsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -303,32 +2158,40 @@ func (x *Empty) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
-func (*Empty) Descriptor() ([]byte, []int) {
-	return file_proto_vdcs_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use ListProposalsResponse.ProtoReflect.Descriptor instead.
+func (*ListProposalsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{27}
 }
 
-type ProposeResponse struct {
+func (x *ListProposalsResponse) GetProposals() []*Proposal {
+	if x != nil {
+		return x.Proposals
+	}
+	return nil
+}
+
+type GetProposalRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ProposeResponse) Reset() {
-	*x = ProposeResponse{}
-	mi := &file_proto_vdcs_proto_msgTypes[3]
+func (x *GetProposalRequest) Reset() {
+	*x = GetProposalRequest{}
+	mi := &file_proto_vdcs_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProposeResponse) String() string {
+func (x *GetProposalRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProposeResponse) ProtoMessage() {}
+func (*GetProposalRequest) ProtoMessage() {}
 
-func (x *ProposeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_vdcs_proto_msgTypes[3]
+func (x *GetProposalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -339,33 +2202,42 @@ func (x *ProposeResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProposeResponse.ProtoReflect.Descriptor instead.
-func (*ProposeResponse) Descriptor() ([]byte, []int) {
-	return file_proto_vdcs_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use GetProposalRequest.ProtoReflect.Descriptor instead.
+func (*GetProposalRequest) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{28}
 }
 
-type GetProofRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+func (x *GetProposalRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ApproveProposalRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// approval signs the intent hash.
+	Approval      *Approval `protobuf:"bytes,2,opt,name=approval,proto3" json:"approval,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetProofRequest) Reset() {
-	*x = GetProofRequest{}
-	mi := &file_proto_vdcs_proto_msgTypes[4]
+func (x *ApproveProposalRequest) Reset() {
+	*x = ApproveProposalRequest{}
+	mi := &file_proto_vdcs_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetProofRequest) String() string {
+func (x *ApproveProposalRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetProofRequest) ProtoMessage() {}
+func (*ApproveProposalRequest) ProtoMessage() {}
 
-func (x *GetProofRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_vdcs_proto_msgTypes[4]
+func (x *ApproveProposalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -376,43 +2248,51 @@ func (x *GetProofRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetProofRequest.ProtoReflect.Descriptor instead.
-func (*GetProofRequest) Descriptor() ([]byte, []int) {
-	return file_proto_vdcs_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use ApproveProposalRequest.ProtoReflect.Descriptor instead.
+func (*ApproveProposalRequest) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *GetProofRequest) GetKey() string {
+func (x *ApproveProposalRequest) GetId() string {
 	if x != nil {
-		return x.Key
+		return x.Id
 	}
 	return ""
 }
 
-type GetProofResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	ValueHash     []byte                 `protobuf:"bytes,2,opt,name=value_hash,json=valueHash,proto3" json:"value_hash,omitempty"`
-	Siblings      [][]byte               `protobuf:"bytes,3,rep,name=siblings,proto3" json:"siblings,omitempty"`
-	IsLeft        []bool                 `protobuf:"varint,4,rep,packed,name=is_left,json=isLeft,proto3" json:"is_left,omitempty"`
+func (x *ApproveProposalRequest) GetApproval() *Approval {
+	if x != nil {
+		return x.Approval
+	}
+	return nil
+}
+
+type RejectProposalRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AuthorId string                 `protobuf:"bytes,2,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Reason   string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// signature by author_id over node.RejectMessage(id, reason).
+	Signature     []byte `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetProofResponse) Reset() {
-	*x = GetProofResponse{}
-	mi := &file_proto_vdcs_proto_msgTypes[5]
+func (x *RejectProposalRequest) Reset() {
+	*x = RejectProposalRequest{}
+	mi := &file_proto_vdcs_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetProofResponse) String() string {
+func (x *RejectProposalRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetProofResponse) ProtoMessage() {}
+func (*RejectProposalRequest) ProtoMessage() {}
 
-func (x *GetProofResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_vdcs_proto_msgTypes[5]
+func (x *RejectProposalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -423,35 +2303,88 @@ func (x *GetProofResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetProofResponse.ProtoReflect.Descriptor instead.
-func (*GetProofResponse) Descriptor() ([]byte, []int) {
-	return file_proto_vdcs_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use RejectProposalRequest.ProtoReflect.Descriptor instead.
+func (*RejectProposalRequest) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *GetProofResponse) GetKey() string {
+func (x *RejectProposalRequest) GetId() string {
 	if x != nil {
-		return x.Key
+		return x.Id
 	}
 	return ""
 }
 
-func (x *GetProofResponse) GetValueHash() []byte {
+func (x *RejectProposalRequest) GetAuthorId() string {
 	if x != nil {
-		return x.ValueHash
+		return x.AuthorId
 	}
-	return nil
+	return ""
 }
 
-func (x *GetProofResponse) GetSiblings() [][]byte {
+func (x *RejectProposalRequest) GetReason() string {
 	if x != nil {
-		return x.Siblings
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RejectProposalRequest) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
 	}
 	return nil
 }
 
-func (x *GetProofResponse) GetIsLeft() []bool {
+type WithdrawProposalRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// signature by the intent's author over node.WithdrawMessage(id).
+	Signature     []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WithdrawProposalRequest) Reset() {
+	*x = WithdrawProposalRequest{}
+	mi := &file_proto_vdcs_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WithdrawProposalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithdrawProposalRequest) ProtoMessage() {}
+
+func (x *WithdrawProposalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_vdcs_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithdrawProposalRequest.ProtoReflect.Descriptor instead.
+func (*WithdrawProposalRequest) Descriptor() ([]byte, []int) {
+	return file_proto_vdcs_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *WithdrawProposalRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *WithdrawProposalRequest) GetSignature() []byte {
 	if x != nil {
-		return x.IsLeft
+		return x.Signature
 	}
 	return nil
 }
@@ -460,7 +2393,41 @@ var File_proto_vdcs_proto protoreflect.FileDescriptor
 
 const file_proto_vdcs_proto_rawDesc = "" +
 	"\n" +
-	"\x10proto/vdcs.proto\x12\avdcs.v1\"\xb1\x02\n" +
+	"\x10proto/vdcs.proto\x12\avdcs.v1\"\xbe\x01\n" +
+	"\bMutation\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1d\n" +
+	"\n" +
+	"value_hash\x18\x02 \x01(\fR\tvalueHash\x120\n" +
+	"\toperation\x18\x03 \x01(\x0e2\x12.vdcs.v1.OperationR\toperation\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\fR\x05value\x129\n" +
+	"\fprecondition\x18\x05 \x01(\v2\x15.vdcs.v1.PreconditionR\fprecondition\"\xaf\x01\n" +
+	"\fPrecondition\x120\n" +
+	"\x13expected_value_hash\x18\x01 \x01(\fH\x00R\x11expectedValueHash\x12&\n" +
+	"\x0emust_not_exist\x18\x02 \x01(\bH\x00R\fmustNotExist\x128\n" +
+	"\x17expected_modified_index\x18\x03 \x01(\x04H\x00R\x15expectedModifiedIndexB\v\n" +
+	"\tcondition\"\x94\x01\n" +
+	"\x13PreconditionFailure\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x16\n" +
+	"\x06exists\x18\x02 \x01(\bR\x06exists\x12,\n" +
+	"\x12current_value_hash\x18\x03 \x01(\fR\x10currentValueHash\x12%\n" +
+	"\x0emodified_index\x18\x04 \x01(\x04R\rmodifiedIndex\"\xdd\x02\n" +
+	"\x06Intent\x12\x1b\n" +
+	"\tauthor_id\x18\x01 \x01(\tR\bauthorId\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x120\n" +
+	"\toperation\x18\x03 \x01(\x0e2\x12.vdcs.v1.OperationR\toperation\x12\x1d\n" +
+	"\n" +
+	"value_hash\x18\x04 \x01(\fR\tvalueHash\x12\x14\n" +
+	"\x05nonce\x18\x05 \x01(\fR\x05nonce\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\x03R\texpiresAt\x129\n" +
+	"\fprecondition\x18\a \x01(\v2\x15.vdcs.v1.PreconditionR\fprecondition\x12\x1c\n" +
+	"\tsignature\x18\b \x01(\fR\tsignature\x12\x14\n" +
+	"\x05value\x18\t \x01(\fR\x05value\x12/\n" +
+	"\tapprovals\x18\n" +
+	" \x03(\v2\x11.vdcs.v1.ApprovalR\tapprovals\"E\n" +
+	"\bApproval\x12\x1b\n" +
+	"\tauthor_id\x18\x01 \x01(\tR\bauthorId\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\fR\tsignature\"\xf7\x03\n" +
 	"\vConfigEntry\x12\x14\n" +
 	"\x05index\x18\x01 \x01(\x04R\x05index\x12\x1c\n" +
 	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12\x1b\n" +
@@ -474,30 +2441,158 @@ const file_proto_vdcs_proto_rawDesc = "" +
 	"entry_hash\x18\b \x01(\fR\tentryHash\x12\x1c\n" +
 	"\tsignature\x18\t \x01(\fR\tsignature\x12\x14\n" +
 	"\x05value\x18\n" +
-	" \x01(\fR\x05value\"n\n" +
+	" \x01(\fR\x05value\x12/\n" +
+	"\tmutations\x18\v \x03(\v2\x11.vdcs.v1.MutationR\tmutations\x129\n" +
+	"\fprecondition\x18\f \x01(\v2\x15.vdcs.v1.PreconditionR\fprecondition\x12'\n" +
+	"\x06intent\x18\r \x01(\v2\x0f.vdcs.v1.IntentR\x06intent\x12/\n" +
+	"\tapprovals\x18\x0e \x03(\v2\x11.vdcs.v1.ApprovalR\tapprovals\"\x8f\x02\n" +
 	"\vConfigState\x12\x18\n" +
 	"\aversion\x18\x01 \x01(\x04R\aversion\x12\x1d\n" +
 	"\n" +
 	"state_root\x18\x02 \x01(\fR\tstateRoot\x12&\n" +
-	"\x0flast_entry_hash\x18\x03 \x01(\fR\rlastEntryHash\"\a\n" +
-	"\x05Empty\"\x11\n" +
-	"\x0fProposeResponse\"#\n" +
+	"\x0flast_entry_hash\x18\x03 \x01(\fR\rlastEntryHash\x12.\n" +
+	"\x13tree_format_version\x18\x04 \x01(\rR\x11treeFormatVersion\x12\x19\n" +
+	"\blog_size\x18\x05 \x01(\x04R\alogSize\x12\x19\n" +
+	"\blog_root\x18\x06 \x01(\fR\alogRoot\x129\n" +
+	"\n" +
+	"checkpoint\x18\a \x01(\v2\x19.vdcs.v1.SignedCheckpointR\n" +
+	"checkpoint\"D\n" +
+	"\x10SignedCheckpoint\x12\x12\n" +
+	"\x04body\x18\x01 \x01(\fR\x04body\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\fR\tsignature\"\a\n" +
+	"\x05Empty\"x\n" +
+	"\x0fProposeResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\x12\x1d\n" +
+	"\n" +
+	"entry_hash\x18\x02 \x01(\fR\tentryHash\x120\n" +
+	"\areceipt\x18\x03 \x01(\v2\x16.vdcs.v1.SignedReceiptR\areceipt\"A\n" +
+	"\rSignedReceipt\x12\x12\n" +
+	"\x04body\x18\x01 \x01(\fR\x04body\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\fR\tsignature\"#\n" +
 	"\x0fGetProofRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\"x\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"\xd2\x01\n" +
 	"\x10GetProofResponse\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1d\n" +
 	"\n" +
 	"value_hash\x18\x02 \x01(\fR\tvalueHash\x12\x1a\n" +
-	"\bsiblings\x18\x03 \x03(\fR\bsiblings\x12\x17\n" +
-	"\ais_left\x18\x04 \x03(\bR\x06isLeft*O\n" +
+	"\bsiblings\x18\x03 \x03(\fR\bsiblings\x12\x16\n" +
+	"\x06exists\x18\x05 \x01(\bR\x06exists\x12\"\n" +
+	"\rleaf_key_hash\x18\x06 \x01(\fR\vleafKeyHash\x12&\n" +
+	"\x0fleaf_value_hash\x18\a \x01(\fR\rleafValueHashJ\x04\b\x04\x10\x05R\ais_left\"#\n" +
+	"\x0fGetValueRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"\xa2\x01\n" +
+	"\x10GetValueResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\fR\x05value\x12/\n" +
+	"\x05proof\x18\x02 \x01(\v2\x19.vdcs.v1.GetProofResponseR\x05proof\x12*\n" +
+	"\x05state\x18\x03 \x01(\v2\x14.vdcs.v1.ConfigStateR\x05state\x12\x1b\n" +
+	"\thas_value\x18\x04 \x01(\bR\bhasValue\"M\n" +
+	"\x18GetInclusionProofRequest\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\x12\x1b\n" +
+	"\ttree_size\x18\x02 \x01(\x04R\btreeSize\"f\n" +
+	"\x19GetInclusionProofResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\x12\x1b\n" +
+	"\ttree_size\x18\x02 \x01(\x04R\btreeSize\x12\x16\n" +
+	"\x06hashes\x18\x03 \x03(\fR\x06hashes\"J\n" +
+	"\x1aGetConsistencyProofRequest\x12\x14\n" +
+	"\x05first\x18\x01 \x01(\x04R\x05first\x12\x16\n" +
+	"\x06second\x18\x02 \x01(\x04R\x06second\"c\n" +
+	"\x1bGetConsistencyProofResponse\x12\x14\n" +
+	"\x05first\x18\x01 \x01(\x04R\x05first\x12\x16\n" +
+	"\x06second\x18\x02 \x01(\x04R\x06second\x12\x16\n" +
+	"\x06hashes\x18\x03 \x03(\fR\x06hashes\"t\n" +
+	"\fWatchRequest\x12\x1d\n" +
+	"\n" +
+	"key_prefix\x18\x01 \x01(\tR\tkeyPrefix\x12\x1f\n" +
+	"\vstart_index\x18\x02 \x01(\x04R\n" +
+	"startIndex\x12$\n" +
+	"\x0eknown_log_size\x18\x03 \x01(\x04R\fknownLogSize\"\xe6\x01\n" +
+	"\n" +
+	"WatchEvent\x12*\n" +
+	"\x05entry\x18\x01 \x01(\v2\x14.vdcs.v1.ConfigEntryR\x05entry\x12*\n" +
+	"\x05state\x18\x02 \x01(\v2\x14.vdcs.v1.ConfigStateR\x05state\x12'\n" +
+	"\x0finclusion_proof\x18\x03 \x03(\fR\x0einclusionProof\x12*\n" +
+	"\x11previous_log_size\x18\x04 \x01(\x04R\x0fpreviousLogSize\x12+\n" +
+	"\x11consistency_proof\x18\x05 \x03(\fR\x10consistencyProof\"X\n" +
+	"\x11GetEntriesRequest\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x04R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x04R\x03end\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\rR\bpageSize\"~\n" +
+	"\x12GetEntriesResponse\x12.\n" +
+	"\aentries\x18\x01 \x03(\v2\x14.vdcs.v1.ConfigEntryR\aentries\x12\x1d\n" +
+	"\n" +
+	"next_index\x18\x02 \x01(\x04R\tnextIndex\x12\x19\n" +
+	"\blog_size\x18\x03 \x01(\x04R\alogSize\"\x8f\x02\n" +
+	"\bProposal\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
+	"\x06intent\x18\x02 \x01(\v2\x0f.vdcs.v1.IntentR\x06intent\x12/\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x17.vdcs.v1.ProposalStatusR\x06status\x12!\n" +
+	"\fsubmitted_at\x18\x04 \x01(\x03R\vsubmittedAt\x12\x1b\n" +
+	"\tclosed_at\x18\x05 \x01(\x03R\bclosedAt\x12\x14\n" +
+	"\x05index\x18\x06 \x01(\x04R\x05index\x12\x16\n" +
+	"\x06reason\x18\a \x01(\tR\x06reason\x12+\n" +
+	"\acurrent\x18\b \x01(\v2\x11.vdcs.v1.KeyStateR\acurrent\"~\n" +
+	"\bKeyState\x12\x16\n" +
+	"\x06exists\x18\x01 \x01(\bR\x06exists\x12\x1d\n" +
+	"\n" +
+	"value_hash\x18\x02 \x01(\fR\tvalueHash\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\fR\x05value\x12%\n" +
+	"\x0emodified_index\x18\x04 \x01(\x04R\rmodifiedIndex\"y\n" +
+	"\x10ProposalResponse\x12-\n" +
+	"\bproposal\x18\x01 \x01(\v2\x11.vdcs.v1.ProposalR\bproposal\x126\n" +
+	"\tcommitted\x18\x02 \x01(\v2\x18.vdcs.v1.ProposeResponseR\tcommitted\"\\\n" +
+	"\x14ListProposalsRequest\x12\x1d\n" +
+	"\n" +
+	"key_prefix\x18\x01 \x01(\tR\tkeyPrefix\x12%\n" +
+	"\x0einclude_closed\x18\x02 \x01(\bR\rincludeClosed\"H\n" +
+	"\x15ListProposalsResponse\x12/\n" +
+	"\tproposals\x18\x01 \x03(\v2\x11.vdcs.v1.ProposalR\tproposals\"$\n" +
+	"\x12GetProposalRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"W\n" +
+	"\x16ApproveProposalRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12-\n" +
+	"\bapproval\x18\x02 \x01(\v2\x11.vdcs.v1.ApprovalR\bapproval\"z\n" +
+	"\x15RejectProposalRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tauthor_id\x18\x02 \x01(\tR\bauthorId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x1c\n" +
+	"\tsignature\x18\x04 \x01(\fR\tsignature\"G\n" +
+	"\x17WithdrawProposalRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\fR\tsignature*\xd8\x01\n" +
 	"\tOperation\x12\x19\n" +
 	"\x15OPERATION_UNSPECIFIED\x10\x00\x12\x11\n" +
 	"\rOPERATION_SET\x10\x01\x12\x14\n" +
-	"\x10OPERATION_DELETE\x10\x022\xbe\x01\n" +
+	"\x10OPERATION_DELETE\x10\x02\x12\x19\n" +
+	"\x15OPERATION_TRANSACTION\x10\x03\x12\x18\n" +
+	"\x14OPERATION_ADD_AUTHOR\x10\x04\x12\x1b\n" +
+	"\x17OPERATION_REVOKE_AUTHOR\x10\x05\x12\x18\n" +
+	"\x14OPERATION_ROTATE_KEY\x10\x06\x12\x1b\n" +
+	"\x17OPERATION_UPDATE_POLICY\x10\a*\xe3\x01\n" +
+	"\x0eProposalStatus\x12\x1f\n" +
+	"\x1bPROPOSAL_STATUS_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17PROPOSAL_STATUS_PENDING\x10\x01\x12\x1d\n" +
+	"\x19PROPOSAL_STATUS_COMMITTED\x10\x02\x12\x1c\n" +
+	"\x18PROPOSAL_STATUS_REJECTED\x10\x03\x12\x1d\n" +
+	"\x19PROPOSAL_STATUS_WITHDRAWN\x10\x04\x12\x1b\n" +
+	"\x17PROPOSAL_STATUS_EXPIRED\x10\x05\x12\x1a\n" +
+	"\x16PROPOSAL_STATUS_FAILED\x10\x062\xb1\b\n" +
 	"\x04VDCS\x12>\n" +
-	"\fProposeEntry\x12\x14.vdcs.v1.ConfigEntry\x1a\x18.vdcs.v1.ProposeResponse\x125\n" +
+	"\fProposeEntry\x12\x14.vdcs.v1.ConfigEntry\x1a\x18.vdcs.v1.ProposeResponse\x12:\n" +
+	"\rProposeIntent\x12\x0f.vdcs.v1.Intent\x1a\x18.vdcs.v1.ProposeResponse\x125\n" +
 	"\rGetLatestRoot\x12\x0e.vdcs.v1.Empty\x1a\x14.vdcs.v1.ConfigState\x12?\n" +
-	"\bGetProof\x12\x18.vdcs.v1.GetProofRequest\x1a\x19.vdcs.v1.GetProofResponseB%Z#github.com/rrb115/vdcs/proto;vdcspbb\x06proto3"
+	"\bGetProof\x12\x18.vdcs.v1.GetProofRequest\x1a\x19.vdcs.v1.GetProofResponse\x12?\n" +
+	"\bGetValue\x12\x18.vdcs.v1.GetValueRequest\x1a\x19.vdcs.v1.GetValueResponse\x12Z\n" +
+	"\x11GetInclusionProof\x12!.vdcs.v1.GetInclusionProofRequest\x1a\".vdcs.v1.GetInclusionProofResponse\x12`\n" +
+	"\x13GetConsistencyProof\x12#.vdcs.v1.GetConsistencyProofRequest\x1a$.vdcs.v1.GetConsistencyProofResponse\x125\n" +
+	"\x05Watch\x12\x15.vdcs.v1.WatchRequest\x1a\x13.vdcs.v1.WatchEvent0\x01\x12E\n" +
+	"\n" +
+	"GetEntries\x12\x1a.vdcs.v1.GetEntriesRequest\x1a\x1b.vdcs.v1.GetEntriesResponse\x12<\n" +
+	"\x0eSubmitProposal\x12\x0f.vdcs.v1.Intent\x1a\x19.vdcs.v1.ProposalResponse\x12N\n" +
+	"\rListProposals\x12\x1d.vdcs.v1.ListProposalsRequest\x1a\x1e.vdcs.v1.ListProposalsResponse\x12=\n" +
+	"\vGetProposal\x12\x1b.vdcs.v1.GetProposalRequest\x1a\x11.vdcs.v1.Proposal\x12M\n" +
+	"\x0fApproveProposal\x12\x1f.vdcs.v1.ApproveProposalRequest\x1a\x19.vdcs.v1.ProposalResponse\x12K\n" +
+	"\x0eRejectProposal\x12\x1e.vdcs.v1.RejectProposalRequest\x1a\x19.vdcs.v1.ProposalResponse\x12O\n" +
+	"\x10WithdrawProposal\x12 .vdcs.v1.WithdrawProposalRequest\x1a\x19.vdcs.v1.ProposalResponseB%Z#github.com/rrb115/vdcs/proto;vdcspbb\x06proto3"
 
 var (
 	file_proto_vdcs_proto_rawDescOnce sync.Once
@@ -511,30 +2606,104 @@ func file_proto_vdcs_proto_rawDescGZIP() []byte {
 	return file_proto_vdcs_proto_rawDescData
 }
 
-var file_proto_vdcs_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_vdcs_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_vdcs_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_vdcs_proto_msgTypes = make([]protoimpl.MessageInfo, 32)
 var file_proto_vdcs_proto_goTypes = []any{
-	(Operation)(0),           // 0: vdcs.v1.Operation
-	(*ConfigEntry)(nil),      // 1: vdcs.v1.ConfigEntry
-	(*ConfigState)(nil),      // 2: vdcs.v1.ConfigState
-	(*Empty)(nil),            // 3: vdcs.v1.Empty
-	(*ProposeResponse)(nil),  // 4: vdcs.v1.ProposeResponse
-	(*GetProofRequest)(nil),  // 5: vdcs.v1.GetProofRequest
-	(*GetProofResponse)(nil), // 6: vdcs.v1.GetProofResponse
+	(Operation)(0),                      // 0: vdcs.v1.Operation
+	(ProposalStatus)(0),                 // 1: vdcs.v1.ProposalStatus
+	(*Mutation)(nil),                    // 2: vdcs.v1.Mutation
+	(*Precondition)(nil),                // 3: vdcs.v1.Precondition
+	(*PreconditionFailure)(nil),         // 4: vdcs.v1.PreconditionFailure
+	(*Intent)(nil),                      // 5: vdcs.v1.Intent
+	(*Approval)(nil),                    // 6: vdcs.v1.Approval
+	(*ConfigEntry)(nil),                 // 7: vdcs.v1.ConfigEntry
+	(*ConfigState)(nil),                 // 8: vdcs.v1.ConfigState
+	(*SignedCheckpoint)(nil),            // 9: vdcs.v1.SignedCheckpoint
+	(*Empty)(nil),                       // 10: vdcs.v1.Empty
+	(*ProposeResponse)(nil),             // 11: vdcs.v1.ProposeResponse
+	(*SignedReceipt)(nil),               // 12: vdcs.v1.SignedReceipt
+	(*GetProofRequest)(nil),             // 13: vdcs.v1.GetProofRequest
+	(*GetProofResponse)(nil),            // 14: vdcs.v1.GetProofResponse
+	(*GetValueRequest)(nil),             // 15: vdcs.v1.GetValueRequest
+	(*GetValueResponse)(nil),            // 16: vdcs.v1.GetValueResponse
+	(*GetInclusionProofRequest)(nil),    // 17: vdcs.v1.GetInclusionProofRequest
+	(*GetInclusionProofResponse)(nil),   // 18: vdcs.v1.GetInclusionProofResponse
+	(*GetConsistencyProofRequest)(nil),  // 19: vdcs.v1.GetConsistencyProofRequest
+	(*GetConsistencyProofResponse)(nil), // 20: vdcs.v1.GetConsistencyProofResponse
+	(*WatchRequest)(nil),                // 21: vdcs.v1.WatchRequest
+	(*WatchEvent)(nil),                  // 22: vdcs.v1.WatchEvent
+	(*GetEntriesRequest)(nil),           // 23: vdcs.v1.GetEntriesRequest
+	(*GetEntriesResponse)(nil),          // 24: vdcs.v1.GetEntriesResponse
+	(*Proposal)(nil),                    // 25: vdcs.v1.Proposal
+	(*KeyState)(nil),                    // 26: vdcs.v1.KeyState
+	(*ProposalResponse)(nil),            // 27: vdcs.v1.ProposalResponse
+	(*ListProposalsRequest)(nil),        // 28: vdcs.v1.ListProposalsRequest
+	(*ListProposalsResponse)(nil),       // 29: vdcs.v1.ListProposalsResponse
+	(*GetProposalRequest)(nil),          // 30: vdcs.v1.GetProposalRequest
+	(*ApproveProposalRequest)(nil),      // 31: vdcs.v1.ApproveProposalRequest
+	(*RejectProposalRequest)(nil),       // 32: vdcs.v1.RejectProposalRequest
+	(*WithdrawProposalRequest)(nil),     // 33: vdcs.v1.WithdrawProposalRequest
 }
 var file_proto_vdcs_proto_depIdxs = []int32{
-	0, // 0: vdcs.v1.ConfigEntry.operation:type_name -> vdcs.v1.Operation
-	1, // 1: vdcs.v1.VDCS.ProposeEntry:input_type -> vdcs.v1.ConfigEntry
-	3, // 2: vdcs.v1.VDCS.GetLatestRoot:input_type -> vdcs.v1.Empty
-	5, // 3: vdcs.v1.VDCS.GetProof:input_type -> vdcs.v1.GetProofRequest
-	4, // 4: vdcs.v1.VDCS.ProposeEntry:output_type -> vdcs.v1.ProposeResponse
-	2, // 5: vdcs.v1.VDCS.GetLatestRoot:output_type -> vdcs.v1.ConfigState
-	6, // 6: vdcs.v1.VDCS.GetProof:output_type -> vdcs.v1.GetProofResponse
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0,  // 0: vdcs.v1.Mutation.operation:type_name -> vdcs.v1.Operation
+	3,  // 1: vdcs.v1.Mutation.precondition:type_name -> vdcs.v1.Precondition
+	0,  // 2: vdcs.v1.Intent.operation:type_name -> vdcs.v1.Operation
+	3,  // 3: vdcs.v1.Intent.precondition:type_name -> vdcs.v1.Precondition
+	6,  // 4: vdcs.v1.Intent.approvals:type_name -> vdcs.v1.Approval
+	0,  // 5: vdcs.v1.ConfigEntry.operation:type_name -> vdcs.v1.Operation
+	2,  // 6: vdcs.v1.ConfigEntry.mutations:type_name -> vdcs.v1.Mutation
+	3,  // 7: vdcs.v1.ConfigEntry.precondition:type_name -> vdcs.v1.Precondition
+	5,  // 8: vdcs.v1.ConfigEntry.intent:type_name -> vdcs.v1.Intent
+	6,  // 9: vdcs.v1.ConfigEntry.approvals:type_name -> vdcs.v1.Approval
+	9,  // 10: vdcs.v1.ConfigState.checkpoint:type_name -> vdcs.v1.SignedCheckpoint
+	12, // 11: vdcs.v1.ProposeResponse.receipt:type_name -> vdcs.v1.SignedReceipt
+	14, // 12: vdcs.v1.GetValueResponse.proof:type_name -> vdcs.v1.GetProofResponse
+	8,  // 13: vdcs.v1.GetValueResponse.state:type_name -> vdcs.v1.ConfigState
+	7,  // 14: vdcs.v1.WatchEvent.entry:type_name -> vdcs.v1.ConfigEntry
+	8,  // 15: vdcs.v1.WatchEvent.state:type_name -> vdcs.v1.ConfigState
+	7,  // 16: vdcs.v1.GetEntriesResponse.entries:type_name -> vdcs.v1.ConfigEntry
+	5,  // 17: vdcs.v1.Proposal.intent:type_name -> vdcs.v1.Intent
+	1,  // 18: vdcs.v1.Proposal.status:type_name -> vdcs.v1.ProposalStatus
+	26, // 19: vdcs.v1.Proposal.current:type_name -> vdcs.v1.KeyState
+	25, // 20: vdcs.v1.ProposalResponse.proposal:type_name -> vdcs.v1.Proposal
+	11, // 21: vdcs.v1.ProposalResponse.committed:type_name -> vdcs.v1.ProposeResponse
+	25, // 22: vdcs.v1.ListProposalsResponse.proposals:type_name -> vdcs.v1.Proposal
+	6,  // 23: vdcs.v1.ApproveProposalRequest.approval:type_name -> vdcs.v1.Approval
+	7,  // 24: vdcs.v1.VDCS.ProposeEntry:input_type -> vdcs.v1.ConfigEntry
+	5,  // 25: vdcs.v1.VDCS.ProposeIntent:input_type -> vdcs.v1.Intent
+	10, // 26: vdcs.v1.VDCS.GetLatestRoot:input_type -> vdcs.v1.Empty
+	13, // 27: vdcs.v1.VDCS.GetProof:input_type -> vdcs.v1.GetProofRequest
+	15, // 28: vdcs.v1.VDCS.GetValue:input_type -> vdcs.v1.GetValueRequest
+	17, // 29: vdcs.v1.VDCS.GetInclusionProof:input_type -> vdcs.v1.GetInclusionProofRequest
+	19, // 30: vdcs.v1.VDCS.GetConsistencyProof:input_type -> vdcs.v1.GetConsistencyProofRequest
+	21, // 31: vdcs.v1.VDCS.Watch:input_type -> vdcs.v1.WatchRequest
+	23, // 32: vdcs.v1.VDCS.GetEntries:input_type -> vdcs.v1.GetEntriesRequest
+	5,  // 33: vdcs.v1.VDCS.SubmitProposal:input_type -> vdcs.v1.Intent
+	28, // 34: vdcs.v1.VDCS.ListProposals:input_type -> vdcs.v1.ListProposalsRequest
+	30, // 35: vdcs.v1.VDCS.GetProposal:input_type -> vdcs.v1.GetProposalRequest
+	31, // 36: vdcs.v1.VDCS.ApproveProposal:input_type -> vdcs.v1.ApproveProposalRequest
+	32, // 37: vdcs.v1.VDCS.RejectProposal:input_type -> vdcs.v1.RejectProposalRequest
+	33, // 38: vdcs.v1.VDCS.WithdrawProposal:input_type -> vdcs.v1.WithdrawProposalRequest
+	11, // 39: vdcs.v1.VDCS.ProposeEntry:output_type -> vdcs.v1.ProposeResponse
+	11, // 40: vdcs.v1.VDCS.ProposeIntent:output_type -> vdcs.v1.ProposeResponse
+	8,  // 41: vdcs.v1.VDCS.GetLatestRoot:output_type -> vdcs.v1.ConfigState
+	14, // 42: vdcs.v1.VDCS.GetProof:output_type -> vdcs.v1.GetProofResponse
+	16, // 43: vdcs.v1.VDCS.GetValue:output_type -> vdcs.v1.GetValueResponse
+	18, // 44: vdcs.v1.VDCS.GetInclusionProof:output_type -> vdcs.v1.GetInclusionProofResponse
+	20, // 45: vdcs.v1.VDCS.GetConsistencyProof:output_type -> vdcs.v1.GetConsistencyProofResponse
+	22, // 46: vdcs.v1.VDCS.Watch:output_type -> vdcs.v1.WatchEvent
+	24, // 47: vdcs.v1.VDCS.GetEntries:output_type -> vdcs.v1.GetEntriesResponse
+	27, // 48: vdcs.v1.VDCS.SubmitProposal:output_type -> vdcs.v1.ProposalResponse
+	29, // 49: vdcs.v1.VDCS.ListProposals:output_type -> vdcs.v1.ListProposalsResponse
+	25, // 50: vdcs.v1.VDCS.GetProposal:output_type -> vdcs.v1.Proposal
+	27, // 51: vdcs.v1.VDCS.ApproveProposal:output_type -> vdcs.v1.ProposalResponse
+	27, // 52: vdcs.v1.VDCS.RejectProposal:output_type -> vdcs.v1.ProposalResponse
+	27, // 53: vdcs.v1.VDCS.WithdrawProposal:output_type -> vdcs.v1.ProposalResponse
+	39, // [39:54] is the sub-list for method output_type
+	24, // [24:39] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
 }
 
 func init() { file_proto_vdcs_proto_init() }
@@ -542,13 +2711,18 @@ func file_proto_vdcs_proto_init() {
 	if File_proto_vdcs_proto != nil {
 		return
 	}
+	file_proto_vdcs_proto_msgTypes[1].OneofWrappers = []any{
+		(*Precondition_ExpectedValueHash)(nil),
+		(*Precondition_MustNotExist)(nil),
+		(*Precondition_ExpectedModifiedIndex)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_vdcs_proto_rawDesc), len(file_proto_vdcs_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   6,
+			NumEnums:      2,
+			NumMessages:   32,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,9 +19,21 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	VDCS_ProposeEntry_FullMethodName  = "/vdcs.v1.VDCS/ProposeEntry"
-	VDCS_GetLatestRoot_FullMethodName = "/vdcs.v1.VDCS/GetLatestRoot"
-	VDCS_GetProof_FullMethodName      = "/vdcs.v1.VDCS/GetProof"
+	VDCS_ProposeEntry_FullMethodName        = "/vdcs.v1.VDCS/ProposeEntry"
+	VDCS_ProposeIntent_FullMethodName       = "/vdcs.v1.VDCS/ProposeIntent"
+	VDCS_GetLatestRoot_FullMethodName       = "/vdcs.v1.VDCS/GetLatestRoot"
+	VDCS_GetProof_FullMethodName            = "/vdcs.v1.VDCS/GetProof"
+	VDCS_GetValue_FullMethodName            = "/vdcs.v1.VDCS/GetValue"
+	VDCS_GetInclusionProof_FullMethodName   = "/vdcs.v1.VDCS/GetInclusionProof"
+	VDCS_GetConsistencyProof_FullMethodName = "/vdcs.v1.VDCS/GetConsistencyProof"
+	VDCS_Watch_FullMethodName               = "/vdcs.v1.VDCS/Watch"
+	VDCS_GetEntries_FullMethodName          = "/vdcs.v1.VDCS/GetEntries"
+	VDCS_SubmitProposal_FullMethodName      = "/vdcs.v1.VDCS/SubmitProposal"
+	VDCS_ListProposals_FullMethodName       = "/vdcs.v1.VDCS/ListProposals"
+	VDCS_GetProposal_FullMethodName         = "/vdcs.v1.VDCS/GetProposal"
+	VDCS_ApproveProposal_FullMethodName     = "/vdcs.v1.VDCS/ApproveProposal"
+	VDCS_RejectProposal_FullMethodName      = "/vdcs.v1.VDCS/RejectProposal"
+	VDCS_WithdrawProposal_FullMethodName    = "/vdcs.v1.VDCS/WithdrawProposal"
 )
 
 // VDCSClient is the client API for VDCS service.
@@ -30,10 +42,43 @@ const (
 type VDCSClient interface {
 	// ProposeEntry submits a new configuration entry.
 	ProposeEntry(ctx context.Context, in *ConfigEntry, opts ...grpc.CallOption) (*ProposeResponse, error)
+	// ProposeIntent submits a signed intent. The node assigns the index and
+	// previous hash and appends it as a sequenced entry.
+	ProposeIntent(ctx context.Context, in *Intent, opts ...grpc.CallOption) (*ProposeResponse, error)
 	// GetLatestRoot returns the current Merkle root and version.
 	GetLatestRoot(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ConfigState, error)
-	// GetProof returns a value and inclusion proof for a key.
+	// GetProof returns an inclusion proof for a key, or a non-inclusion proof
+	// if the key is not set.
 	GetProof(ctx context.Context, in *GetProofRequest, opts ...grpc.CallOption) (*GetProofResponse, error)
+	// GetValue returns the value of a key together with a proof and the state
+	// the proof was generated against.
+	GetValue(ctx context.Context, in *GetValueRequest, opts ...grpc.CallOption) (*GetValueResponse, error)
+	// GetInclusionProof returns the audit path proving that the entry at an
+	// index is part of the log tree of a given size.
+	GetInclusionProof(ctx context.Context, in *GetInclusionProofRequest, opts ...grpc.CallOption) (*GetInclusionProofResponse, error)
+	// GetConsistencyProof returns a proof that the log tree of one size is an
+	// append-only extension of the log tree of a smaller size.
+	GetConsistencyProof(ctx context.Context, in *GetConsistencyProofRequest, opts ...grpc.CallOption) (*GetConsistencyProofResponse, error)
+	// Watch streams every committed entry from start_index on that changes a
+	// key with the given prefix, followed by new entries as they are committed.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error)
+	// GetEntries returns one page of signed entries from the log.
+	GetEntries(ctx context.Context, in *GetEntriesRequest, opts ...grpc.CallOption) (*GetEntriesResponse, error)
+	// SubmitProposal queues a signed intent for review. The node commits it as
+	// a sequenced entry as soon as it has the approvals the policy requires.
+	SubmitProposal(ctx context.Context, in *Intent, opts ...grpc.CallOption) (*ProposalResponse, error)
+	// ListProposals returns pending proposals, with a diff against the
+	// current state.
+	ListProposals(ctx context.Context, in *ListProposalsRequest, opts ...grpc.CallOption) (*ListProposalsResponse, error)
+	// GetProposal returns a single proposal, pending or closed.
+	GetProposal(ctx context.Context, in *GetProposalRequest, opts ...grpc.CallOption) (*Proposal, error)
+	// ApproveProposal adds an approval to a pending proposal and commits it if
+	// that satisfies the policy.
+	ApproveProposal(ctx context.Context, in *ApproveProposalRequest, opts ...grpc.CallOption) (*ProposalResponse, error)
+	// RejectProposal closes a pending proposal on behalf of a reviewer.
+	RejectProposal(ctx context.Context, in *RejectProposalRequest, opts ...grpc.CallOption) (*ProposalResponse, error)
+	// WithdrawProposal closes a pending proposal on behalf of its author.
+	WithdrawProposal(ctx context.Context, in *WithdrawProposalRequest, opts ...grpc.CallOption) (*ProposalResponse, error)
 }
 
 type vDCSClient struct {
@@ -54,6 +99,16 @@ func (c *vDCSClient) ProposeEntry(ctx context.Context, in *ConfigEntry, opts ...
 	return out, nil
 }
 
+func (c *vDCSClient) ProposeIntent(ctx context.Context, in *Intent, opts ...grpc.CallOption) (*ProposeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProposeResponse)
+	err := c.cc.Invoke(ctx, VDCS_ProposeIntent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *vDCSClient) GetLatestRoot(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ConfigState, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ConfigState)
@@ -74,16 +129,168 @@ func (c *vDCSClient) GetProof(ctx context.Context, in *GetProofRequest, opts ...
 	return out, nil
 }
 
+func (c *vDCSClient) GetValue(ctx context.Context, in *GetValueRequest, opts ...grpc.CallOption) (*GetValueResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetValueResponse)
+	err := c.cc.Invoke(ctx, VDCS_GetValue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vDCSClient) GetInclusionProof(ctx context.Context, in *GetInclusionProofRequest, opts ...grpc.CallOption) (*GetInclusionProofResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetInclusionProofResponse)
+	err := c.cc.Invoke(ctx, VDCS_GetInclusionProof_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vDCSClient) GetConsistencyProof(ctx context.Context, in *GetConsistencyProofRequest, opts ...grpc.CallOption) (*GetConsistencyProofResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetConsistencyProofResponse)
+	err := c.cc.Invoke(ctx, VDCS_GetConsistencyProof_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vDCSClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &VDCS_ServiceDesc.Streams[0], VDCS_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, WatchEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VDCS_WatchClient = grpc.ServerStreamingClient[WatchEvent]
+
+func (c *vDCSClient) GetEntries(ctx context.Context, in *GetEntriesRequest, opts ...grpc.CallOption) (*GetEntriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetEntriesResponse)
+	err := c.cc.Invoke(ctx, VDCS_GetEntries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vDCSClient) SubmitProposal(ctx context.Context, in *Intent, opts ...grpc.CallOption) (*ProposalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProposalResponse)
+	err := c.cc.Invoke(ctx, VDCS_SubmitProposal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vDCSClient) ListProposals(ctx context.Context, in *ListProposalsRequest, opts ...grpc.CallOption) (*ListProposalsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProposalsResponse)
+	err := c.cc.Invoke(ctx, VDCS_ListProposals_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vDCSClient) GetProposal(ctx context.Context, in *GetProposalRequest, opts ...grpc.CallOption) (*Proposal, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Proposal)
+	err := c.cc.Invoke(ctx, VDCS_GetProposal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vDCSClient) ApproveProposal(ctx context.Context, in *ApproveProposalRequest, opts ...grpc.CallOption) (*ProposalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProposalResponse)
+	err := c.cc.Invoke(ctx, VDCS_ApproveProposal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vDCSClient) RejectProposal(ctx context.Context, in *RejectProposalRequest, opts ...grpc.CallOption) (*ProposalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProposalResponse)
+	err := c.cc.Invoke(ctx, VDCS_RejectProposal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vDCSClient) WithdrawProposal(ctx context.Context, in *WithdrawProposalRequest, opts ...grpc.CallOption) (*ProposalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProposalResponse)
+	err := c.cc.Invoke(ctx, VDCS_WithdrawProposal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // VDCSServer is the server API for VDCS service.
 // All implementations must embed UnimplementedVDCSServer
 // for forward compatibility.
 type VDCSServer interface {
 	// ProposeEntry submits a new configuration entry.
 	ProposeEntry(context.Context, *ConfigEntry) (*ProposeResponse, error)
+	// ProposeIntent submits a signed intent. The node assigns the index and
+	// previous hash and appends it as a sequenced entry.
+	ProposeIntent(context.Context, *Intent) (*ProposeResponse, error)
 	// GetLatestRoot returns the current Merkle root and version.
 	GetLatestRoot(context.Context, *Empty) (*ConfigState, error)
-	// GetProof returns a value and inclusion proof for a key.
+	// GetProof returns an inclusion proof for a key, or a non-inclusion proof
+	// if the key is not set.
 	GetProof(context.Context, *GetProofRequest) (*GetProofResponse, error)
+	// GetValue returns the value of a key together with a proof and the state
+	// the proof was generated against.
+	GetValue(context.Context, *GetValueRequest) (*GetValueResponse, error)
+	// GetInclusionProof returns the audit path proving that the entry at an
+	// index is part of the log tree of a given size.
+	GetInclusionProof(context.Context, *GetInclusionProofRequest) (*GetInclusionProofResponse, error)
+	// GetConsistencyProof returns a proof that the log tree of one size is an
+	// append-only extension of the log tree of a smaller size.
+	GetConsistencyProof(context.Context, *GetConsistencyProofRequest) (*GetConsistencyProofResponse, error)
+	// Watch streams every committed entry from start_index on that changes a
+	// key with the given prefix, followed by new entries as they are committed.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[WatchEvent]) error
+	// GetEntries returns one page of signed entries from the log.
+	GetEntries(context.Context, *GetEntriesRequest) (*GetEntriesResponse, error)
+	// SubmitProposal queues a signed intent for review. The node commits it as
+	// a sequenced entry as soon as it has the approvals the policy requires.
+	SubmitProposal(context.Context, *Intent) (*ProposalResponse, error)
+	// ListProposals returns pending proposals, with a diff against the
+	// current state.
+	ListProposals(context.Context, *ListProposalsRequest) (*ListProposalsResponse, error)
+	// GetProposal returns a single proposal, pending or closed.
+	GetProposal(context.Context, *GetProposalRequest) (*Proposal, error)
+	// ApproveProposal adds an approval to a pending proposal and commits it if
+	// that satisfies the policy.
+	ApproveProposal(context.Context, *ApproveProposalRequest) (*ProposalResponse, error)
+	// RejectProposal closes a pending proposal on behalf of a reviewer.
+	RejectProposal(context.Context, *RejectProposalRequest) (*ProposalResponse, error)
+	// WithdrawProposal closes a pending proposal on behalf of its author.
+	WithdrawProposal(context.Context, *WithdrawProposalRequest) (*ProposalResponse, error)
 	mustEmbedUnimplementedVDCSServer()
 }
 
@@ -97,12 +304,48 @@ type UnimplementedVDCSServer struct{}
 func (UnimplementedVDCSServer) ProposeEntry(context.Context, *ConfigEntry) (*ProposeResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ProposeEntry not implemented")
 }
+func (UnimplementedVDCSServer) ProposeIntent(context.Context, *Intent) (*ProposeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ProposeIntent not implemented")
+}
 func (UnimplementedVDCSServer) GetLatestRoot(context.Context, *Empty) (*ConfigState, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetLatestRoot not implemented")
 }
 func (UnimplementedVDCSServer) GetProof(context.Context, *GetProofRequest) (*GetProofResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetProof not implemented")
 }
+func (UnimplementedVDCSServer) GetValue(context.Context, *GetValueRequest) (*GetValueResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetValue not implemented")
+}
+func (UnimplementedVDCSServer) GetInclusionProof(context.Context, *GetInclusionProofRequest) (*GetInclusionProofResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetInclusionProof not implemented")
+}
+func (UnimplementedVDCSServer) GetConsistencyProof(context.Context, *GetConsistencyProofRequest) (*GetConsistencyProofResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetConsistencyProof not implemented")
+}
+func (UnimplementedVDCSServer) Watch(*WatchRequest, grpc.ServerStreamingServer[WatchEvent]) error {
+	return status.Error(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedVDCSServer) GetEntries(context.Context, *GetEntriesRequest) (*GetEntriesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetEntries not implemented")
+}
+func (UnimplementedVDCSServer) SubmitProposal(context.Context, *Intent) (*ProposalResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SubmitProposal not implemented")
+}
+func (UnimplementedVDCSServer) ListProposals(context.Context, *ListProposalsRequest) (*ListProposalsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListProposals not implemented")
+}
+func (UnimplementedVDCSServer) GetProposal(context.Context, *GetProposalRequest) (*Proposal, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetProposal not implemented")
+}
+func (UnimplementedVDCSServer) ApproveProposal(context.Context, *ApproveProposalRequest) (*ProposalResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ApproveProposal not implemented")
+}
+func (UnimplementedVDCSServer) RejectProposal(context.Context, *RejectProposalRequest) (*ProposalResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RejectProposal not implemented")
+}
+func (UnimplementedVDCSServer) WithdrawProposal(context.Context, *WithdrawProposalRequest) (*ProposalResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method WithdrawProposal not implemented")
+}
 func (UnimplementedVDCSServer) mustEmbedUnimplementedVDCSServer() {}
 func (UnimplementedVDCSServer) testEmbeddedByValue()              {}
 
@@ -142,6 +385,24 @@ func _VDCS_ProposeEntry_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _VDCS_ProposeIntent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Intent)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VDCSServer).ProposeIntent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VDCS_ProposeIntent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VDCSServer).ProposeIntent(ctx, req.(*Intent))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _VDCS_GetLatestRoot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -178,6 +439,197 @@ func _VDCS_GetProof_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _VDCS_GetValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VDCSServer).GetValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VDCS_GetValue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VDCSServer).GetValue(ctx, req.(*GetValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VDCS_GetInclusionProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInclusionProofRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VDCSServer).GetInclusionProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VDCS_GetInclusionProof_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VDCSServer).GetInclusionProof(ctx, req.(*GetInclusionProofRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VDCS_GetConsistencyProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConsistencyProofRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VDCSServer).GetConsistencyProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VDCS_GetConsistencyProof_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VDCSServer).GetConsistencyProof(ctx, req.(*GetConsistencyProofRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VDCS_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VDCSServer).Watch(m, &grpc.GenericServerStream[WatchRequest, WatchEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VDCS_WatchServer = grpc.ServerStreamingServer[WatchEvent]
+
+func _VDCS_GetEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VDCSServer).GetEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VDCS_GetEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VDCSServer).GetEntries(ctx, req.(*GetEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VDCS_SubmitProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Intent)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VDCSServer).SubmitProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VDCS_SubmitProposal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VDCSServer).SubmitProposal(ctx, req.(*Intent))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VDCS_ListProposals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProposalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VDCSServer).ListProposals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VDCS_ListProposals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VDCSServer).ListProposals(ctx, req.(*ListProposalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VDCS_GetProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProposalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VDCSServer).GetProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VDCS_GetProposal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VDCSServer).GetProposal(ctx, req.(*GetProposalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VDCS_ApproveProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApproveProposalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VDCSServer).ApproveProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VDCS_ApproveProposal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VDCSServer).ApproveProposal(ctx, req.(*ApproveProposalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VDCS_RejectProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RejectProposalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VDCSServer).RejectProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VDCS_RejectProposal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VDCSServer).RejectProposal(ctx, req.(*RejectProposalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VDCS_WithdrawProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WithdrawProposalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VDCSServer).WithdrawProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VDCS_WithdrawProposal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VDCSServer).WithdrawProposal(ctx, req.(*WithdrawProposalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // VDCS_ServiceDesc is the grpc.ServiceDesc for VDCS service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -189,6 +641,10 @@ var VDCS_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ProposeEntry",
 			Handler:    _VDCS_ProposeEntry_Handler,
 		},
+		{
+			MethodName: "ProposeIntent",
+			Handler:    _VDCS_ProposeIntent_Handler,
+		},
 		{
 			MethodName: "GetLatestRoot",
 			Handler:    _VDCS_GetLatestRoot_Handler,
@@ -197,7 +653,53 @@ var VDCS_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetProof",
 			Handler:    _VDCS_GetProof_Handler,
 		},
+		{
+			MethodName: "GetValue",
+			Handler:    _VDCS_GetValue_Handler,
+		},
+		{
+			MethodName: "GetInclusionProof",
+			Handler:    _VDCS_GetInclusionProof_Handler,
+		},
+		{
+			MethodName: "GetConsistencyProof",
+			Handler:    _VDCS_GetConsistencyProof_Handler,
+		},
+		{
+			MethodName: "GetEntries",
+			Handler:    _VDCS_GetEntries_Handler,
+		},
+		{
+			MethodName: "SubmitProposal",
+			Handler:    _VDCS_SubmitProposal_Handler,
+		},
+		{
+			MethodName: "ListProposals",
+			Handler:    _VDCS_ListProposals_Handler,
+		},
+		{
+			MethodName: "GetProposal",
+			Handler:    _VDCS_GetProposal_Handler,
+		},
+		{
+			MethodName: "ApproveProposal",
+			Handler:    _VDCS_ApproveProposal_Handler,
+		},
+		{
+			MethodName: "RejectProposal",
+			Handler:    _VDCS_RejectProposal_Handler,
+		},
+		{
+			MethodName: "WithdrawProposal",
+			Handler:    _VDCS_WithdrawProposal_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _VDCS_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/vdcs.proto",
 }